@@ -0,0 +1,63 @@
+package middleware // import "github.com/docker/docker/api/server/middleware"
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/docker/docker/api/server/httpstatus"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware is a middleware that records a span for every API
+// request. The span is a child of the span in the traceparent header of the
+// request, if any.
+type TracingMiddleware struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracingMiddleware creates a new TracingMiddleware recording spans with
+// the tracer provider, and extracting the trace context of requests with the
+// propagator.
+func NewTracingMiddleware(tp trace.TracerProvider, propagator propagation.TextMapPropagator) TracingMiddleware {
+	return TracingMiddleware{
+		tracer:     tp.Tracer("github.com/docker/docker/api/server"),
+		propagator: propagator,
+	}
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (t TracingMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		route := r.URL.Path
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if tmpl, err := cr.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+
+		ctx = t.propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("dockerd", route, r)...),
+		)
+		defer span.End()
+
+		err := handler(ctx, w, r.WithContext(ctx), vars)
+		if err != nil {
+			statusCode := httpstatus.FromError(err)
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(statusCode)...)
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(statusCode, trace.SpanKindServer))
+			if statusCode >= http.StatusInternalServerError {
+				span.RecordError(err)
+			}
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		return err
+	}
+}
@@ -0,0 +1,74 @@
+package middleware // import "github.com/docker/docker/api/server/middleware"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	e.spans = append(e.spans, spans...)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	exp := &recordingExporter{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	defer tp.Shutdown(context.Background())
+
+	var handlerSpan trace.SpanContext
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		assert.Check(t, trace.SpanContextFromContext(r.Context()).Equal(handlerSpan))
+		if vars["fail"] != "" {
+			return errdefs.NotFound(errors.New("no such container"))
+		}
+		return nil
+	}
+	h := NewTracingMiddleware(tp, propagation.TraceContext{}).WrapHandler(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/containers/foo/start", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NilError(t, h(context.Background(), httptest.NewRecorder(), req, map[string]string{}))
+
+	assert.Assert(t, is.Len(exp.spans, 1))
+	span := exp.spans[0]
+	assert.Check(t, is.Equal(span.Name(), "POST /containers/foo/start"))
+	assert.Check(t, is.Equal(span.SpanKind(), trace.SpanKindServer))
+	assert.Check(t, is.Equal(span.SpanContext().TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736"))
+	assert.Check(t, is.Equal(span.Parent().SpanID().String(), "00f067aa0ba902b7"))
+	assert.Check(t, span.SpanContext().Equal(handlerSpan))
+	assert.Check(t, is.Equal(span.Status().Code, codes.Ok))
+
+	req = httptest.NewRequest(http.MethodPost, "/containers/foo/start", nil)
+	err := h(context.Background(), httptest.NewRecorder(), req, map[string]string{"fail": "1"})
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	assert.Assert(t, is.Len(exp.spans, 2))
+	span = exp.spans[1]
+	assert.Check(t, !span.Parent().IsValid())
+	// Client errors are not errors of the server.
+	assert.Check(t, is.Equal(span.Status().Code, codes.Unset))
+}
@@ -138,6 +138,16 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 		return errdefs.InvalidParameter(errors.New("Bad parameters: you must choose at least one stream"))
 	}
 
+	// The filters parameter was added in API 1.43.
+	filter := filters.NewArgs()
+	if versions.GreaterThanOrEqualTo(httputils.VersionFromContext(ctx), "1.43") {
+		var err error
+		filter, err = filters.FromJSON(r.Form.Get("filters"))
+		if err != nil {
+			return err
+		}
+	}
+
 	containerName := vars["name"]
 	logsConfig := &types.ContainerLogsOptions{
 		Follow:     httputils.BoolValue(r, "follow"),
@@ -148,6 +158,7 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 		ShowStdout: stdout,
 		ShowStderr: stderr,
 		Details:    httputils.BoolValue(r, "details"),
+		Filters:    filter,
 	}
 
 	msgs, tty, err := s.backend.ContainerLogs(ctx, containerName, logsConfig)
@@ -214,7 +225,7 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 
 	checkpoint := r.Form.Get("checkpoint")
 	checkpointDir := r.Form.Get("checkpoint-dir")
-	if err := s.backend.ContainerStart(vars["name"], hostConfig, checkpoint, checkpointDir); err != nil {
+	if err := s.backend.ContainerStart(ctx, vars["name"], hostConfig, checkpoint, checkpointDir); err != nil {
 		return err
 	}
 
@@ -444,6 +455,13 @@ func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.Respon
 	if versions.LessThan(httputils.VersionFromContext(ctx), "1.40") {
 		updateConfig.PidsLimit = nil
 	}
+	if versions.LessThan(httputils.VersionFromContext(ctx), "1.43") {
+		// Ignore the restart delay options because they were added in API 1.43.
+		updateConfig.RestartPolicy = container.RestartPolicy{
+			Name:              updateConfig.RestartPolicy.Name,
+			MaximumRetryCount: updateConfig.RestartPolicy.MaximumRetryCount,
+		}
+	}
 
 	if versions.GreaterThanOrEqualTo(httputils.VersionFromContext(ctx), "1.42") {
 		// Ignore KernelMemory removed in API 1.42.
@@ -554,6 +572,33 @@ func (s *containerRouter) postContainersCreate(ctx context.Context, w http.Respo
 		}
 	}
 
+	if hostConfig != nil && versions.LessThan(version, "1.43") {
+		for _, m := range hostConfig.Mounts {
+			if m.Type == mount.TypeImage {
+				return errdefs.InvalidParameter(fmt.Errorf("mount type %q requires API version 1.43 or newer", m.Type))
+			}
+		}
+		// Ignore the restart delay options because they were added in API 1.43.
+		hostConfig.RestartPolicy = container.RestartPolicy{
+			Name:              hostConfig.RestartPolicy.Name,
+			MaximumRetryCount: hostConfig.RestartPolicy.MaximumRetryCount,
+		}
+		// Ignore DependsOn because it was added in API 1.43.
+		hostConfig.DependsOn = nil
+	}
+	if config != nil && config.Healthcheck != nil && versions.LessThan(version, "1.43") {
+		if test := config.Healthcheck.Test; len(test) > 0 && (test[0] == "HTTP" || test[0] == "TCP") {
+			return errdefs.InvalidParameter(fmt.Errorf("healthcheck type %q requires API version 1.43 or newer", test[0]))
+		}
+		// Ignore the startup check and the unhealthy actions because they
+		// were added in API 1.43.
+		config.Healthcheck.StartupTest = nil
+		config.Healthcheck.StartupInterval = 0
+		config.Healthcheck.StartupRetries = 0
+		config.Healthcheck.OnUnhealthy = ""
+		config.Healthcheck.OnUnhealthySignal = ""
+	}
+
 	if hostConfig != nil && runtime.GOOS == "linux" && versions.LessThan(version, "1.42") {
 		// ConsoleSize is not respected by Linux daemon before API 1.42
 		hostConfig.ConsoleSize = [2]uint{0, 0}
@@ -578,7 +623,7 @@ func (s *containerRouter) postContainersCreate(ctx context.Context, w http.Respo
 		hostConfig.PidsLimit = nil
 	}
 
-	ccr, err := s.backend.ContainerCreate(types.ContainerCreateConfig{
+	ccr, err := s.backend.ContainerCreate(ctx, types.ContainerCreateConfig{
 		Name:             name,
 		Config:           config,
 		HostConfig:       hostConfig,
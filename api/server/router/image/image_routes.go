@@ -206,9 +206,17 @@ func (ir *imageRouter) getImagesByName(ctx context.Context, w http.ResponseWrite
 }
 
 func (ir *imageRouter) toImageInspect(img *image.Image) (*types.ImageInspect, error) {
-	refs := ir.referenceBackend.References(img.ID().Digest())
+	details := img.Details
+	if details == nil {
+		var err error
+		details, err = ir.imageDetails(img)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var repoTags, repoDigests []string
-	for _, ref := range refs {
+	for _, ref := range details.References {
 		switch ref.(type) {
 		case reference.NamedTagged:
 			repoTags = append(repoTags, reference.FamiliarString(ref))
@@ -217,31 +225,11 @@ func (ir *imageRouter) toImageInspect(img *image.Image) (*types.ImageInspect, er
 		}
 	}
 
-	var size int64
-	var layerMetadata map[string]string
-	if layerID := img.RootFS.ChainID(); layerID != "" {
-		l, err := ir.layerStore.Get(layerID)
-		if err != nil {
-			return nil, err
-		}
-		defer layer.ReleaseAndLog(ir.layerStore, l)
-		size = l.Size()
-		layerMetadata, err = l.Metadata()
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	comment := img.Comment
 	if len(comment) == 0 && len(img.History) > 0 {
 		comment = img.History[len(img.History)-1].Comment
 	}
 
-	lastUpdated, err := ir.imageStore.GetLastUpdated(img.ID())
-	if err != nil {
-		return nil, err
-	}
-
 	return &types.ImageInspect{
 		ID:              img.ID().String(),
 		RepoTags:        repoTags,
@@ -258,19 +246,48 @@ func (ir *imageRouter) toImageInspect(img *image.Image) (*types.ImageInspect, er
 		Variant:         img.Variant,
 		Os:              img.OperatingSystem(),
 		OsVersion:       img.OSVersion,
-		Size:            size,
-		VirtualSize:     size, // TODO: field unused, deprecate
+		Size:            details.Size,
+		VirtualSize:     details.Size, // TODO: field unused, deprecate
 		GraphDriver: types.GraphDriverData{
-			Name: ir.layerStore.DriverName(),
-			Data: layerMetadata,
+			Name: details.Driver,
+			Data: details.Metadata,
 		},
 		RootFS: rootFSToAPIType(img.RootFS),
 		Metadata: types.ImageMetadata{
-			LastTagTime: lastUpdated,
+			LastTagTime: details.LastUpdated,
 		},
 	}, nil
 }
 
+// imageDetails collects the details of img from the reference, image and
+// layer stores, for image services that do not provide the image details.
+func (ir *imageRouter) imageDetails(img *image.Image) (*image.Details, error) {
+	details := &image.Details{
+		References: ir.referenceBackend.References(img.ID().Digest()),
+		Driver:     ir.layerStore.DriverName(),
+	}
+
+	if layerID := img.RootFS.ChainID(); layerID != "" {
+		l, err := ir.layerStore.Get(layerID)
+		if err != nil {
+			return nil, err
+		}
+		defer layer.ReleaseAndLog(ir.layerStore, l)
+		details.Size = l.Size()
+		details.Metadata, err = l.Metadata()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lastUpdated, err := ir.imageStore.GetLastUpdated(img.ID())
+	if err != nil {
+		return nil, err
+	}
+	details.LastUpdated = lastUpdated
+	return details, nil
+}
+
 func rootFSToAPIType(rootfs *image.RootFS) types.RootFS {
 	var layers []string
 	for _, l := range rootfs.DiffIDs {
@@ -18,6 +18,8 @@ type Backend interface {
 	ConnectContainerToNetwork(containerName, networkName string, endpointConfig *network.EndpointSettings) error
 	DisconnectContainerFromNetwork(containerName string, networkName string, force bool) error
 	DeleteNetwork(networkID string) error
+	GetNetworkDNSRecords(networkID string) ([]network.DNSRecord, error)
+	SetNetworkDNSRecords(networkID string, records []network.DNSRecord) error
 	NetworksPrune(ctx context.Context, pruneFilters filters.Args) (*types.NetworksPruneReport, error)
 }
 
@@ -31,12 +31,15 @@ func (r *networkRouter) initRoutes() {
 		// GET
 		router.NewGetRoute("/networks", r.getNetworksList),
 		router.NewGetRoute("/networks/", r.getNetworksList),
+		router.NewGetRoute("/networks/{id:.*}/dns-records", r.getNetworkDNSRecords),
 		router.NewGetRoute("/networks/{id:.+}", r.getNetwork),
 		// POST
 		router.NewPostRoute("/networks/create", r.postNetworkCreate),
 		router.NewPostRoute("/networks/{id:.*}/connect", r.postNetworkConnect),
 		router.NewPostRoute("/networks/{id:.*}/disconnect", r.postNetworkDisconnect),
 		router.NewPostRoute("/networks/prune", r.postNetworksPrune),
+		// PUT
+		router.NewPutRoute("/networks/{id:.*}/dns-records", r.putNetworkDNSRecords),
 		// DELETE
 		router.NewDeleteRoute("/networks/{id:.*}", r.deleteNetwork),
 	}
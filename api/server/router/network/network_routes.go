@@ -212,6 +212,11 @@ func (n *networkRouter) postNetworkCreate(ctx context.Context, w http.ResponseWr
 		return err
 	}
 
+	if versions.LessThan(httputils.VersionFromContext(ctx), "1.43") {
+		// DNS configuration of networks was added in API 1.43.
+		create.DNS = nil
+	}
+
 	if nws, err := n.cluster.GetNetworksByName(create.Name); err == nil && len(nws) > 0 {
 		return nameConflict(create.Name)
 	}
@@ -231,6 +236,9 @@ func (n *networkRouter) postNetworkCreate(ctx context.Context, w http.ResponseWr
 		if _, ok := err.(libnetwork.ManagerRedirectError); !ok {
 			return err
 		}
+		if create.DNS != nil {
+			return errdefs.InvalidParameter(errors.New("DNS configuration is not supported for swarm networks"))
+		}
 		id, err := n.cluster.CreateNetwork(create)
 		if err != nil {
 			return err
@@ -296,6 +304,49 @@ func (n *networkRouter) deleteNetwork(ctx context.Context, w http.ResponseWriter
 	return nil
 }
 
+func (n *networkRouter) getNetworkDNSRecords(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	nw, err := n.findUniqueNetwork(vars["id"])
+	if err != nil {
+		return err
+	}
+	if nw.Scope == "swarm" {
+		return httputils.WriteJSON(w, http.StatusOK, []network.DNSRecord{})
+	}
+	records, err := n.backend.GetNetworkDNSRecords(nw.ID)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, records)
+}
+
+func (n *networkRouter) putNetworkDNSRecords(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	var records []network.DNSRecord
+	if err := httputils.ReadJSON(r, &records); err != nil {
+		return err
+	}
+
+	nw, err := n.findUniqueNetwork(vars["id"])
+	if err != nil {
+		return err
+	}
+	if nw.Scope == "swarm" {
+		return errdefs.InvalidParameter(errors.New("static DNS records are not supported for swarm networks"))
+	}
+	if err := n.backend.SetNetworkDNSRecords(nw.ID, records); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (n *networkRouter) postNetworksPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -28,10 +28,13 @@ func (r *volumeRouter) initRoutes() {
 	r.routes = []router.Route{
 		// GET
 		router.NewGetRoute("/volumes", r.getVolumesList),
+		router.NewGetRoute("/volumes/{name:.*}/export", r.getVolumeExport),
 		router.NewGetRoute("/volumes/{name:.*}", r.getVolumeByName),
 		// POST
 		router.NewPostRoute("/volumes/create", r.postVolumesCreate),
 		router.NewPostRoute("/volumes/prune", r.postVolumesPrune),
+		router.NewPostRoute("/volumes/{name:.*}/clone", r.postVolumesClone),
+		router.NewPostRoute("/volumes/{name:.*}/import", r.postVolumesImport),
 		// PUT
 		router.NewPutRoute("/volumes/{name:.*}", r.putVolumesUpdate),
 		// DELETE
@@ -3,6 +3,7 @@ package volume // import "github.com/docker/docker/api/server/router/volume"
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -87,6 +88,18 @@ func (v *volumeRouter) getVolumeByName(ctx context.Context, w http.ResponseWrite
 	return httputils.WriteJSON(w, http.StatusOK, vol)
 }
 
+func (v *volumeRouter) getVolumeExport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	tarArchive, err := v.backend.Export(ctx, vars["name"])
+	if err != nil {
+		return err
+	}
+	defer tarArchive.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	_, err = io.Copy(w, tarArchive)
+	return err
+}
+
 func (v *volumeRouter) postVolumesCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -129,6 +142,31 @@ func (v *volumeRouter) postVolumesCreate(ctx context.Context, w http.ResponseWri
 	return httputils.WriteJSON(w, http.StatusCreated, vol)
 }
 
+func (v *volumeRouter) postVolumesClone(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	var req volume.CloneOptions
+	if err := httputils.ReadJSON(r, &req); err != nil {
+		return err
+	}
+
+	vol, err := v.backend.Clone(ctx, vars["name"], req.Name, opts.WithCreateLabels(req.Labels))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, vol)
+}
+
+func (v *volumeRouter) postVolumesImport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := v.backend.Import(ctx, vars["name"], r.Body); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (v *volumeRouter) putVolumesUpdate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if !v.cluster.IsManager() {
 		return errdefs.Unavailable(errors.New("volume update only valid for cluster volumes, but swarm is unavailable"))
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -576,8 +577,84 @@ func TestVolumeRemoveSwarmForce(t *testing.T) {
 	assert.Equal(t, len(b.volumes), 0)
 }
 
+func TestVolumeClone(t *testing.T) {
+	b := &fakeVolumeBackend{
+		volumes: map[string]*volume.Volume{
+			"vol1": {
+				Name:   "vol1",
+				Driver: "local",
+				Labels: map[string]string{"foo": "bar"},
+			},
+		},
+	}
+	v := &volumeRouter{
+		backend: b,
+		cluster: &fakeClusterBackend{},
+	}
+
+	buf := bytes.Buffer{}
+	json.NewEncoder(&buf).Encode(volume.CloneOptions{
+		Name:   "vol2",
+		Labels: map[string]string{"baz": "qux"},
+	})
+	ctx := context.WithValue(context.Background(), httputils.APIVersionKey{}, clusterVolumesVersion)
+	req := httptest.NewRequest("POST", "/volumes/vol1/clone", &buf)
+	req.Header.Add("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	err := v.postVolumesClone(ctx, resp, req, map[string]string{"name": "vol1"})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Code, 201)
+
+	respVolume := volume.Volume{}
+	assert.NilError(t, json.NewDecoder(resp.Result().Body).Decode(&respVolume))
+	assert.Equal(t, respVolume.Name, "vol2")
+	assert.DeepEqual(t, respVolume.Labels, map[string]string{"foo": "bar", "baz": "qux"})
+
+	buf.Reset()
+	json.NewEncoder(&buf).Encode(volume.CloneOptions{Name: "vol3"})
+	req = httptest.NewRequest("POST", "/volumes/notReal/clone", &buf)
+	req.Header.Add("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+
+	err = v.postVolumesClone(ctx, resp, req, map[string]string{"name": "notReal"})
+	assert.Assert(t, errdefs.IsNotFound(err))
+}
+
+func TestVolumeExportImport(t *testing.T) {
+	b := &fakeVolumeBackend{
+		volumes: map[string]*volume.Volume{
+			"vol1": {Name: "vol1", Driver: "local"},
+		},
+	}
+	v := &volumeRouter{
+		backend: b,
+		cluster: &fakeClusterBackend{},
+	}
+	ctx := context.WithValue(context.Background(), httputils.APIVersionKey{}, clusterVolumesVersion)
+
+	req := httptest.NewRequest("POST", "/volumes/vol1/import", bytes.NewBufferString("content"))
+	resp := httptest.NewRecorder()
+	err := v.postVolumesImport(ctx, resp, req, map[string]string{"name": "vol1"})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Code, 204)
+
+	req = httptest.NewRequest("GET", "/volumes/vol1/export", nil)
+	resp = httptest.NewRecorder()
+	err = v.getVolumeExport(ctx, resp, req, map[string]string{"name": "vol1"})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Header().Get("Content-Type"), "application/x-tar")
+	assert.Equal(t, resp.Body.String(), "content")
+
+	req = httptest.NewRequest("GET", "/volumes/notReal/export", nil)
+	resp = httptest.NewRecorder()
+	err = v.getVolumeExport(ctx, resp, req, map[string]string{"name": "notReal"})
+	assert.Assert(t, errdefs.IsNotFound(err))
+}
+
 type fakeVolumeBackend struct {
 	volumes map[string]*volume.Volume
+	content map[string][]byte
 }
 
 func (b *fakeVolumeBackend) List(_ context.Context, _ filters.Args) ([]*volume.Volume, []string, error) {
@@ -616,6 +693,36 @@ func (b *fakeVolumeBackend) Create(_ context.Context, name, driverName string, _
 	return v, nil
 }
 
+func (b *fakeVolumeBackend) Clone(_ context.Context, name, target string, createOpts ...opts.CreateOption) (*volume.Volume, error) {
+	src, ok := b.volumes[name]
+	if !ok {
+		return nil, errdefs.NotFound(fmt.Errorf("volume %s not found", name))
+	}
+	if _, ok := b.volumes[target]; ok {
+		return nil, errdefs.Conflict(fmt.Errorf("volume %s already exists", target))
+	}
+
+	var cfg opts.CreateConfig
+	for _, o := range createOpts {
+		o(&cfg)
+	}
+	labels := map[string]string{}
+	for k, v := range src.Labels {
+		labels[k] = v
+	}
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+
+	v := &volume.Volume{
+		Name:   target,
+		Driver: src.Driver,
+		Labels: labels,
+	}
+	b.volumes[target] = v
+	return v, nil
+}
+
 func (b *fakeVolumeBackend) Remove(_ context.Context, name string, _ ...opts.RemoveOption) error {
 	if v, ok := b.volumes[name]; !ok {
 		return errdefs.NotFound(fmt.Errorf("volume %s not found", name))
@@ -628,6 +735,28 @@ func (b *fakeVolumeBackend) Remove(_ context.Context, name string, _ ...opts.Rem
 	return nil
 }
 
+func (b *fakeVolumeBackend) Export(_ context.Context, name string) (io.ReadCloser, error) {
+	if _, ok := b.volumes[name]; !ok {
+		return nil, errdefs.NotFound(fmt.Errorf("volume %s not found", name))
+	}
+	return io.NopCloser(bytes.NewReader(b.content[name])), nil
+}
+
+func (b *fakeVolumeBackend) Import(_ context.Context, name string, content io.Reader) error {
+	if _, ok := b.volumes[name]; !ok {
+		return errdefs.NotFound(fmt.Errorf("volume %s not found", name))
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	if b.content == nil {
+		b.content = map[string][]byte{}
+	}
+	b.content[name] = data
+	return nil
+}
+
 func (b *fakeVolumeBackend) Prune(_ context.Context, _ filters.Args) (*types.VolumesPruneReport, error) {
 	return nil, nil
 }
@@ -79,6 +79,7 @@ type ContainerLogsOptions struct {
 	Follow     bool
 	Tail       string
 	Details    bool
+	Filters    filters.Args
 }
 
 // ContainerRemoveOptions holds parameters to remove containers.
@@ -41,6 +41,8 @@ type HealthConfig struct {
 	// {"NONE"} : disable healthcheck
 	// {"CMD", args...} : exec arguments directly
 	// {"CMD-SHELL", command} : run command with system's default shell
+	// {"HTTP", url, options...} : send a GET request to url from the container's network namespace
+	// {"TCP", address} : open a TCP connection to address from the container's network namespace
 	Test []string `json:",omitempty"`
 
 	// Zero means to inherit. Durations are expressed as integer nanoseconds.
@@ -51,8 +53,42 @@ type HealthConfig struct {
 	// Retries is the number of consecutive failures needed to consider a container as unhealthy.
 	// Zero means inherit.
 	Retries int `json:",omitempty"`
+
+	// StartupTest is the test to perform until it succeeds once, before Test
+	// is performed. The container is starting until then. It accepts the same
+	// options as Test. An empty slice means to inherit the default.
+	StartupTest []string `json:",omitempty"`
+
+	// StartupInterval is the time to wait between startup checks.
+	// Zero means inherit, or to use Interval.
+	StartupInterval time.Duration `json:",omitempty"`
+
+	// StartupRetries is the number of consecutive failures of the startup check
+	// needed to consider a container as unhealthy.
+	// Zero means inherit, or to never consider the container as unhealthy while
+	// it is starting.
+	StartupRetries int `json:",omitempty"`
+
+	// OnUnhealthy is the action taken when the container becomes unhealthy.
+	// Empty means inherit, or no action.
+	OnUnhealthy UnhealthyAction `json:",omitempty"`
+
+	// OnUnhealthySignal is the signal sent to the container by the "kill"
+	// action. Empty means inherit, or SIGKILL.
+	OnUnhealthySignal string `json:",omitempty"`
 }
 
+// UnhealthyAction is the action taken when a container becomes unhealthy.
+type UnhealthyAction string
+
+// Actions taken when a container becomes unhealthy.
+const (
+	UnhealthyActionNone    UnhealthyAction = "none"    // Do nothing
+	UnhealthyActionRestart UnhealthyAction = "restart" // Restart the container, using the restart policy's backoff
+	UnhealthyActionStop    UnhealthyAction = "stop"    // Stop the container, as done by "docker stop"
+	UnhealthyActionKill    UnhealthyAction = "kill"    // Send OnUnhealthySignal to the container, as done by "docker kill"
+)
+
 // ExecStartOptions holds the options to start container's exec.
 type ExecStartOptions struct {
 	Stdin       io.Reader
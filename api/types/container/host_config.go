@@ -2,6 +2,7 @@ package container // import "github.com/docker/docker/api/types/container"
 
 import (
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/blkiodev"
 	"github.com/docker/docker/api/types/mount"
@@ -303,6 +304,17 @@ type DeviceMapping struct {
 type RestartPolicy struct {
 	Name              string
 	MaximumRetryCount int
+
+	// The delay before restarting the container is InitialDelay for the first
+	// restart, and is multiplied by Multiplier for each subsequent restart, up
+	// to MaxDelay. It is reset to InitialDelay once the container ran for
+	// ResetAfter. Zero means to use the default. Durations are expressed as
+	// integer nanoseconds.
+	InitialDelay time.Duration `json:",omitempty"` // InitialDelay is the delay before the first restart. Defaults to 100ms.
+	MaxDelay     time.Duration `json:",omitempty"` // MaxDelay is the maximum delay before a restart. Defaults to 1 minute.
+	Multiplier   float64       `json:",omitempty"` // Multiplier is the factor applied to the delay after each restart. Defaults to 2.
+	Jitter       float64       `json:",omitempty"` // Jitter is the fraction, between 0 and 1, by which the delay is randomly reduced. Defaults to 0.
+	ResetAfter   time.Duration `json:",omitempty"` // ResetAfter is the run time after which the delay is reset. Defaults to 10 seconds.
 }
 
 // IsNone indicates whether the container has the "no" restart policy.
@@ -332,7 +344,27 @@ func (rp *RestartPolicy) IsUnlessStopped() bool {
 
 // IsSame compares two RestartPolicy to see if they are the same
 func (rp *RestartPolicy) IsSame(tp *RestartPolicy) bool {
-	return rp.Name == tp.Name && rp.MaximumRetryCount == tp.MaximumRetryCount
+	return *rp == *tp
+}
+
+// DependencyCondition is the condition a dependency of a container must meet
+// before the container is started.
+type DependencyCondition string
+
+// Conditions a dependency of a container must meet before the container is
+// started.
+const (
+	DependencyConditionStarted DependencyCondition = "started" // The dependency is running
+	DependencyConditionHealthy DependencyCondition = "healthy" // The dependency is running, and its healthcheck passes
+)
+
+// Dependency is a container which must be started before another container.
+type Dependency struct {
+	// Container is the name or ID of the container.
+	Container string
+	// Condition is the condition the container must meet. Defaults to
+	// DependencyConditionStarted.
+	Condition DependencyCondition `json:",omitempty"`
 }
 
 // LogMode is a type to define the available modes for logging
@@ -418,6 +450,7 @@ type HostConfig struct {
 	VolumeDriver    string        // Name of the volume driver used to mount volumes
 	VolumesFrom     []string      // List of volumes to take from other container
 	ConsoleSize     [2]uint       // Initial console size (height,width)
+	DependsOn       []Dependency  `json:",omitempty"` // List of containers to start before the container
 
 	// Applicable to UNIX platforms
 	CapAdd          strslice.StrSlice // List of kernel capabilities to add to the container
@@ -19,13 +19,15 @@ const (
 	TypeNamedPipe Type = "npipe"
 	// TypeCluster is the type for Swarm Cluster Volumes.
 	TypeCluster Type = "cluster"
+	// TypeImage is the type for mounting the filesystem of an image, read-only.
+	TypeImage Type = "image"
 )
 
 // Mount represents a mount (volume).
 type Mount struct {
 	Type Type `json:",omitempty"`
 	// Source specifies the name of the mount. Depending on mount type, this
-	// may be a volume name or a host path, or even ignored.
+	// may be a volume name, a host path or an image reference, or even ignored.
 	// Source is not supported for tmpfs (must be an empty value)
 	Source      string      `json:",omitempty"`
 	Target      string      `json:",omitempty"`
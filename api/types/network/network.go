@@ -110,6 +110,33 @@ type ConfigReference struct {
 	Network string
 }
 
+// DNSConfig represents the DNS configuration of the containers connected to a
+// network, which overrides the DNS configuration of the daemon. It does not
+// override the DNS configuration of containers.
+type DNSConfig struct {
+	Nameservers []string `json:",omitempty"` // Nameservers the queries of containers are forwarded to
+	Search      []string `json:",omitempty"` // Search domains
+	Options     []string `json:",omitempty"` // Options of the resolver of containers, such as "ndots:2"
+}
+
+// DNSRecord represents a static DNS record of a network, which is served by
+// the embedded DNS server to the containers connected to the network.
+type DNSRecord struct {
+	Name  string // Name of the record, such as "db.example.com"
+	Type  string // Type of the record: "A", "AAAA", "CNAME" or "TXT"
+	Value string // IP address, target name or text of the record
+	TTL   uint32 `json:",omitempty"` // TTL of the record in seconds, the default TTL is used if zero
+}
+
+// EndpointBandwidth represents the bandwidth limits of an endpoint, from the
+// point of view of the container.
+type EndpointBandwidth struct {
+	IngressRate  uint64 `json:",omitempty"` // Rate limit of the traffic received by the container, in bytes per second
+	IngressBurst uint64 `json:",omitempty"` // Burst size of the traffic received by the container, in bytes
+	EgressRate   uint64 `json:",omitempty"` // Rate limit of the traffic sent by the container, in bytes per second
+	EgressBurst  uint64 `json:",omitempty"` // Burst size of the traffic sent by the container, in bytes
+}
+
 var acceptedFilters = map[string]bool{
 	"dangling": true,
 	"driver":   true,
@@ -430,6 +430,10 @@ type ContainerState struct {
 	StartedAt  string
 	FinishedAt string
 	Health     *Health `json:",omitempty"`
+
+	// NextRestartAt is the time at which the container is scheduled to be
+	// restarted by its restart policy, if it is restarting.
+	NextRestartAt string `json:",omitempty"`
 }
 
 // ContainerNode stores information about the node that a container
@@ -583,6 +587,7 @@ type NetworkResource struct {
 	Labels     map[string]string              // Labels holds metadata specific to the network being created
 	Peers      []network.PeerInfo             `json:",omitempty"` // List of peer nodes for an overlay network
 	Services   map[string]network.ServiceInfo `json:",omitempty"`
+	DNS        *network.DNSConfig             `json:",omitempty"` // DNS holds the DNS configuration of the containers connected to the network
 }
 
 // EndpointResource contains network resources allocated and used for a container in a network
@@ -592,6 +597,7 @@ type EndpointResource struct {
 	MacAddress  string
 	IPv4Address string
 	IPv6Address string
+	Bandwidth   *network.EndpointBandwidth `json:",omitempty"` // Bandwidth holds the bandwidth limits of the endpoint, if any
 }
 
 // NetworkCreate is the expected body of the "create network" http request message
@@ -615,6 +621,7 @@ type NetworkCreate struct {
 	ConfigFrom     *network.ConfigReference
 	Options        map[string]string
 	Labels         map[string]string
+	DNS            *network.DNSConfig `json:",omitempty"`
 }
 
 // NetworkCreateRequest is the request message sent to the server for network create call.
@@ -6,3 +6,13 @@ import "github.com/docker/docker/api/types/filters"
 type ListOptions struct {
 	Filters filters.Args
 }
+
+// CloneOptions holds parameters to clone a volume.
+type CloneOptions struct {
+	// Name is the name of the new volume. A random name is generated if it
+	// is empty.
+	Name string `json:"Name,omitempty"`
+	// Labels are the labels of the new volume, in addition to the labels of
+	// the volume being cloned.
+	Labels map[string]string `json:"Labels,omitempty"`
+}
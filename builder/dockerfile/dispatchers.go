@@ -201,7 +201,7 @@ func dispatchTriggeredOnBuild(d dispatchRequest, triggers []string) error {
 		if len(ast.AST.Children) != 1 {
 			return errors.New("onbuild trigger should be a single expression")
 		}
-		cmd, err := instructions.ParseCommand(ast.AST.Children[0])
+		cmd, err := parseCommand(ast.AST.Children[0])
 		if err != nil {
 			var uiErr *instructions.UnknownInstructionError
 			if errors.As(err, &uiErr) {
@@ -300,6 +300,49 @@ func TestHealthcheckCmd(t *testing.T) {
 	assert.Check(t, is.DeepEqual(expectedTest, sb.state.runConfig.Healthcheck.Test))
 }
 
+func TestHealthcheckProbe(t *testing.T) {
+	testCases := []struct {
+		dockerfile   string
+		expectedTest []string
+	}{
+		{
+			dockerfile:   `HEALTHCHECK --interval=5s HTTP http://localhost:8080/health status=200`,
+			expectedTest: []string{"HTTP", "http://localhost:8080/health", "status=200"},
+		},
+		{
+			dockerfile:   `HEALTHCHECK tcp ["127.0.0.1:5432"]`,
+			expectedTest: []string{"TCP", "127.0.0.1:5432"},
+		},
+	}
+	for _, tc := range testCases {
+		b := newBuilderWithMockBackend()
+		sb := newDispatchRequest(b, '`', nil, NewBuildArgs(make(map[string]*string)), newStagesBuildResults())
+		ast, err := parser.Parse(strings.NewReader(tc.dockerfile))
+		assert.NilError(t, err)
+		cmd, err := parseCommand(ast.AST.Children[0])
+		assert.NilError(t, err)
+		err = dispatch(sb, cmd)
+		assert.NilError(t, err)
+
+		assert.Assert(t, sb.state.runConfig.Healthcheck != nil)
+		assert.Check(t, is.DeepEqual(tc.expectedTest, sb.state.runConfig.Healthcheck.Test))
+	}
+
+	ast, err := parser.Parse(strings.NewReader("FROM busybox\nHEALTHCHECK --retries=2 TCP localhost:80\n"))
+	assert.NilError(t, err)
+	stages, _, err := parseStages(ast.AST)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(stages[0].Commands, 1))
+	health := stages[0].Commands[0].(*instructions.HealthCheckCommand).Health
+	assert.Check(t, is.DeepEqual([]string{"TCP", "localhost:80"}, health.Test))
+	assert.Check(t, is.Equal(2, health.Retries))
+
+	ast, err = parser.Parse(strings.NewReader("HEALTHCHECK HTTP\n"))
+	assert.NilError(t, err)
+	_, err = parseCommand(ast.AST.Children[0])
+	assert.Check(t, is.ErrorContains(err, "Missing address after HEALTHCHECK HTTP"))
+}
+
 func TestEntrypoint(t *testing.T) {
 	b := newBuilderWithMockBackend()
 	sb := newDispatchRequest(b, '`', nil, NewBuildArgs(make(map[string]*string)), newStagesBuildResults())
@@ -0,0 +1,99 @@
+package dockerfile // import "github.com/docker/docker/builder/dockerfile"
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// healthcheckProbeTypes are the HEALTHCHECK types probing an address of the
+// container, instead of running a command in it.
+var healthcheckProbeTypes = map[string]bool{
+	"HTTP": true,
+	"TCP":  true,
+}
+
+// parseStages parses the stages of a Dockerfile, like instructions.Parse,
+// including the HEALTHCHECK instructions probing an address.
+func parseStages(ast *parser.Node) ([]instructions.Stage, []instructions.ArgCommand, error) {
+	root := *ast
+	root.Children = make([]*parser.Node, 0, len(ast.Children))
+	probes := make(map[string][]string)
+	for _, n := range ast.Children {
+		n, probe, err := rewriteHealthcheckProbe(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		if probe != nil {
+			probes[strings.TrimSpace(n.Original)] = probe
+		}
+		root.Children = append(root.Children, n)
+	}
+
+	stages, metaArgs, err := instructions.Parse(&root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(probes) > 0 {
+		for _, s := range stages {
+			for _, cmd := range s.Commands {
+				if c, ok := cmd.(*instructions.HealthCheckCommand); ok && probes[c.String()] != nil {
+					c.Health.Test = probes[c.String()]
+				}
+			}
+		}
+	}
+	return stages, metaArgs, nil
+}
+
+// parseCommand parses an instruction, like instructions.ParseCommand,
+// including the HEALTHCHECK instructions probing an address.
+func parseCommand(n *parser.Node) (instructions.Command, error) {
+	n, probe, err := rewriteHealthcheckProbe(n)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := instructions.ParseCommand(n)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := cmd.(*instructions.HealthCheckCommand); ok && probe != nil {
+		c.Health.Test = probe
+	}
+	return cmd, nil
+}
+
+// rewriteHealthcheckProbe returns the health check test of a HEALTHCHECK HTTP
+// or TCP instruction, and a copy of the instruction with the CMD type. The
+// Dockerfile parser only knows the CMD and NONE types, so the copy is parsed
+// instead of the instruction, and the test of the parsed command is replaced.
+// Other instructions are returned as is, with a nil test.
+func rewriteHealthcheckProbe(n *parser.Node) (*parser.Node, []string, error) {
+	if !strings.EqualFold(n.Value, command.Healthcheck) || n.Next == nil {
+		return n, nil, nil
+	}
+	typ := strings.ToUpper(n.Next.Value)
+	if !healthcheckProbeTypes[typ] {
+		return n, nil, nil
+	}
+
+	var args []string
+	for arg := n.Next.Next; arg != nil; arg = arg.Next {
+		args = append(args, arg.Value)
+	}
+	if !n.Attributes["json"] {
+		args = strings.Fields(strings.Join(args, " "))
+	}
+	if len(args) == 0 {
+		return nil, nil, parser.WithLocation(errors.Errorf("Missing address after HEALTHCHECK %s", typ), n.Location())
+	}
+
+	typNode := *n.Next
+	typNode.Value = "CMD"
+	rewritten := *n
+	rewritten.Next = &typNode
+	return &rewritten, append([]string{typ}, args...), nil
+}
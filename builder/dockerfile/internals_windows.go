@@ -81,7 +81,7 @@ func lookupNTAccount(builder *Builder, accountName string, state *dispatchState)
 	},
 	}
 
-	container, err := builder.containerManager.Create(runConfig, hostConfig)
+	container, err := builder.containerManager.Create(builder.clientCtx, runConfig, hostConfig)
 	if err != nil {
 		return idtools.Identity{}, err
 	}
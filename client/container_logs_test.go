@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/errdefs"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -103,6 +104,15 @@ func TestContainerLogs(t *testing.T) {
 				"until": "1136073600.000000001",
 			},
 		},
+		{
+			options: types.ContainerLogsOptions{
+				Filters: filters.NewArgs(filters.Arg("contains", "request-id")),
+			},
+			expectedQueryParams: map[string]string{
+				"tail":    "",
+				"filters": `{"contains":{"request-id":true}}`,
+			},
+		},
 		{
 			options: types.ContainerLogsOptions{
 				// An complete invalid date will not be passed
@@ -0,0 +1,30 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// NetworkDNSRecords returns the static DNS records of a network.
+func (cli *Client) NetworkDNSRecords(ctx context.Context, networkID string) ([]network.DNSRecord, error) {
+	var records []network.DNSRecord
+	resp, err := cli.get(ctx, "/networks/"+networkID+"/dns-records", nil, nil)
+	defer ensureReaderClosed(resp)
+	if err != nil {
+		return records, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&records)
+	return records, err
+}
+
+// NetworkSetDNSRecords replaces the static DNS records of a network.
+func (cli *Client) NetworkSetDNSRecords(ctx context.Context, networkID string, records []network.DNSRecord) error {
+	if records == nil {
+		records = []network.DNSRecord{}
+	}
+	resp, err := cli.put(ctx, "/networks/"+networkID+"/dns-records", nil, records, nil)
+	ensureReaderClosed(resp)
+	return err
+}
@@ -0,0 +1,96 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestNetworkDNSRecordsError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	_, err := client.NetworkDNSRecords(context.Background(), "network_id")
+	if !errdefs.IsSystem(err) {
+		t.Fatalf("expected a Server Error, got %[1]T: %[1]v", err)
+	}
+
+	err = client.NetworkSetDNSRecords(context.Background(), "network_id", nil)
+	if !errdefs.IsSystem(err) {
+		t.Fatalf("expected a Server Error, got %[1]T: %[1]v", err)
+	}
+}
+
+func TestNetworkDNSRecords(t *testing.T) {
+	expectedURL := "/networks/network_id/dns-records"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodGet {
+				return nil, fmt.Errorf("expected GET method, got %s", req.Method)
+			}
+			content, err := json.Marshal([]network.DNSRecord{
+				{Name: "db.example.com", Type: "A", Value: "10.0.0.10"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	records, err := client.NetworkDNSRecords(context.Background(), "network_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Name != "db.example.com" || records[0].Value != "10.0.0.10" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
+
+func TestNetworkSetDNSRecords(t *testing.T) {
+	expectedURL := "/networks/network_id/dns-records"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodPut {
+				return nil, fmt.Errorf("expected PUT method, got %s", req.Method)
+			}
+			var records []network.DNSRecord
+			if err := json.NewDecoder(req.Body).Decode(&records); err != nil {
+				return nil, err
+			}
+			if len(records) != 1 || records[0].Type != "CNAME" || records[0].Value != "db.example.com" {
+				return nil, fmt.Errorf("unexpected records: %v", records)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}),
+	}
+
+	err := client.NetworkSetDNSRecords(context.Background(), "network_id", []network.DNSRecord{
+		{Name: "database.example.com", Type: "CNAME", Value: "db.example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
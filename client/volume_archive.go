@@ -0,0 +1,26 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"io"
+)
+
+// VolumeExport returns the contents of a volume as a tar archive. It's up to
+// the caller to close the reader.
+func (cli *Client) VolumeExport(ctx context.Context, volumeID string) (io.ReadCloser, error) {
+	resp, err := cli.get(ctx, "/volumes/"+volumeID+"/export", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+// VolumeImport extracts a tar archive into a volume. Files of the volume
+// which are also in the archive are overwritten.
+func (cli *Client) VolumeImport(ctx context.Context, volumeID string, content io.Reader) error {
+	resp, err := cli.postRaw(ctx, "/volumes/"+volumeID+"/import", nil, content, map[string][]string{
+		"Content-Type": {"application/x-tar"},
+	})
+	ensureReaderClosed(resp)
+	return err
+}
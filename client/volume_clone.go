@@ -0,0 +1,20 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types/volume"
+)
+
+// VolumeClone creates a copy of a volume in the docker host.
+func (cli *Client) VolumeClone(ctx context.Context, volumeID string, options volume.CloneOptions) (volume.Volume, error) {
+	var vol volume.Volume
+	resp, err := cli.post(ctx, "/volumes/"+volumeID+"/clone", nil, options, nil)
+	defer ensureReaderClosed(resp)
+	if err != nil {
+		return vol, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&vol)
+	return vol, err
+}
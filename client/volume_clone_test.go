@@ -0,0 +1,75 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestVolumeCloneError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	_, err := client.VolumeClone(context.Background(), "volume_id", volume.CloneOptions{})
+	if !errdefs.IsSystem(err) {
+		t.Fatalf("expected a Server Error, got %[1]T: %[1]v", err)
+	}
+}
+
+func TestVolumeClone(t *testing.T) {
+	expectedURL := "/volumes/volume_id/clone"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodPost {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+
+			var opts volume.CloneOptions
+			if err := json.NewDecoder(req.Body).Decode(&opts); err != nil {
+				return nil, err
+			}
+			if opts.Name != "clone" {
+				return nil, fmt.Errorf("expected name 'clone', got %s", opts.Name)
+			}
+
+			content, err := json.Marshal(volume.Volume{
+				Name:   opts.Name,
+				Driver: "local",
+				Labels: opts.Labels,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       io.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	vol, err := client.VolumeClone(context.Background(), "volume_id", volume.CloneOptions{
+		Name:   "clone",
+		Labels: map[string]string{"foo": "bar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.Name != "clone" {
+		t.Fatalf("expected volume.Name to be 'clone', got %s", vol.Name)
+	}
+	if vol.Labels["foo"] != "bar" {
+		t.Fatalf("expected volume label foo=bar, got %v", vol.Labels)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/docker/docker/libnetwork/proxy"
 	"github.com/ishidawataru/sctp"
 )
 
@@ -16,7 +17,7 @@ func main() {
 	f := os.NewFile(3, "signal-parent")
 	host, container := parseHostContainerAddrs()
 
-	p, err := NewProxy(host, container)
+	p, err := proxy.NewProxy(host, container)
 	if err != nil {
 		fmt.Fprintf(f, "1\n%s", err)
 		f.Close()
@@ -60,7 +61,7 @@ func parseHostContainerAddrs() (host net.Addr, container net.Addr) {
 	return host, container
 }
 
-func handleStopSignals(p Proxy) {
+func handleStopSignals(p proxy.Proxy) {
 	s := make(chan os.Signal, 10)
 	signal.Notify(s, os.Interrupt, syscall.SIGTERM)
 
@@ -43,6 +43,8 @@ func installConfigFlags(conf *config.Config, flags *pflag.FlagSet) error {
 	flags.IPVar(&conf.BridgeConfig.DefaultIP, "ip", net.IPv4zero, "Default IP when binding container ports")
 	flags.BoolVar(&conf.BridgeConfig.EnableUserlandProxy, "userland-proxy", true, "Use userland proxy for loopback traffic")
 	flags.StringVar(&conf.BridgeConfig.UserlandProxyPath, "userland-proxy-path", conf.BridgeConfig.UserlandProxyPath, "Path to the userland proxy binary")
+	flags.StringVar(&conf.BridgeConfig.UserlandProxyMode, "userland-proxy-mode", "", `Run the userland proxy of each published port in a process, or in the daemon ("process" | "internal")`)
+	flags.StringVar(&conf.BridgeConfig.FirewallBackend, "firewall-backend", "", `Firewall backend programming the rules of the bridge driver and the embedded DNS server ("iptables" | "nftables")`)
 	flags.StringVar(&conf.CgroupParent, "cgroup-parent", "", "Set parent cgroup for all containers")
 	flags.StringVar(&conf.RemappedRoot, "userns-remap", "", "User/Group setting for user namespaces")
 	flags.BoolVar(&conf.LiveRestoreEnabled, "live-restore", false, "Enable live restore of docker when containers are still running")
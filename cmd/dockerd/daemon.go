@@ -57,6 +57,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
 )
 
 // DaemonCli represents the daemon CLI.
@@ -190,6 +191,20 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 
 	pluginStore := plugin.NewStore()
 
+	shutdownTracing, err := initTracing(ctx, cli.Config.Tracing)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize tracing")
+	}
+	if shutdownTracing != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				logrus.WithError(err).Warn("failed to flush traces")
+			}
+		}()
+	}
+
 	if err := cli.initMiddlewares(cli.api, serverConfig, pluginStore); err != nil {
 		logrus.Fatalf("Error creating middlewares: %v", err)
 	}
@@ -573,6 +588,12 @@ func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config
 	cli.authzMiddleware = authorization.NewMiddleware(cli.Config.AuthorizationPlugins, pluginStore)
 	cli.Config.AuthzMiddleware = cli.authzMiddleware
 	s.UseMiddleware(cli.authzMiddleware)
+
+	// The tracing middleware is the outermost middleware, so that the spans
+	// of requests include the time spent in the other middlewares.
+	if cli.Config.Tracing.Endpoint != "" {
+		s.UseMiddleware(middleware.NewTracingMiddleware(otel.GetTracerProvider(), otel.GetTextMapPropagator()))
+	}
 	return nil
 }
 
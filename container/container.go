@@ -438,7 +438,10 @@ func (container *Container) StartLogger() (logger.Logger, error) {
 			}
 		}
 	}
-	return l, nil
+
+	// Multiline messages are aggregated before they are written to the
+	// local cache, so that they are read back the same way.
+	return logger.NewMultilineLogger(l, cfg.Config)
 }
 
 // GetProcessLabel returns the process label for the container.
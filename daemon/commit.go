@@ -93,6 +93,21 @@ func merge(userConf, imageConf *containertypes.Config) error {
 			if userConf.Healthcheck.Retries == 0 {
 				userConf.Healthcheck.Retries = imageConf.Healthcheck.Retries
 			}
+			if len(userConf.Healthcheck.StartupTest) == 0 {
+				userConf.Healthcheck.StartupTest = imageConf.Healthcheck.StartupTest
+			}
+			if userConf.Healthcheck.StartupInterval == 0 {
+				userConf.Healthcheck.StartupInterval = imageConf.Healthcheck.StartupInterval
+			}
+			if userConf.Healthcheck.StartupRetries == 0 {
+				userConf.Healthcheck.StartupRetries = imageConf.Healthcheck.StartupRetries
+			}
+			if userConf.Healthcheck.OnUnhealthy == "" {
+				userConf.Healthcheck.OnUnhealthy = imageConf.Healthcheck.OnUnhealthy
+			}
+			if userConf.Healthcheck.OnUnhealthySignal == "" {
+				userConf.Healthcheck.OnUnhealthySignal = imageConf.Healthcheck.OnUnhealthySignal
+			}
 		}
 	}
 
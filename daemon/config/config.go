@@ -79,6 +79,7 @@ var flatOptions = map[string]bool{
 	"default-ulimits":    true,
 	"features":           true,
 	"builder":            true,
+	"events":             true,
 }
 
 // skipValidateOptions contains configuration keys
@@ -87,6 +88,7 @@ var flatOptions = map[string]bool{
 var skipValidateOptions = map[string]bool{
 	"features": true,
 	"builder":  true,
+	"events":   true,
 	// Corresponding flag has been removed because it was already unusable
 	"deprecated-key-path": true,
 }
@@ -222,6 +224,10 @@ type CommonConfig struct {
 
 	MetricsAddress string `json:"metrics-addr"`
 
+	// ContainerMetrics enables the per-container resource metrics on the
+	// metrics endpoint.
+	ContainerMetrics bool `json:"metrics-containers,omitempty"`
+
 	DNSConfig
 	LogConfig
 	BridgeConfig // bridgeConfig holds bridge network specific configuration.
@@ -254,6 +260,10 @@ type CommonConfig struct {
 
 	Builder BuilderConfig `json:"builder,omitempty"`
 
+	Events EventsConfig `json:"events,omitempty"`
+
+	Tracing TracingConfig `json:"tracing,omitempty"`
+
 	ContainerdNamespace       string `json:"containerd-namespace,omitempty"`
 	ContainerdPluginNamespace string `json:"containerd-plugin-namespace,omitempty"`
 
@@ -622,6 +632,14 @@ func Validate(config *Config) error {
 		return err
 	}
 
+	if err := config.Events.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.Tracing.Validate(); err != nil {
+		return err
+	}
+
 	if defaultRuntime := config.GetDefaultRuntimeName(); defaultRuntime != "" {
 		if !builtinRuntimes[defaultRuntime] {
 			runtimes := config.GetAllRuntimes()
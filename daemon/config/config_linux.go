@@ -50,6 +50,8 @@ type BridgeConfig struct {
 	EnableIPMasq        bool   `json:"ip-masq,omitempty"`
 	EnableUserlandProxy bool   `json:"userland-proxy,omitempty"`
 	UserlandProxyPath   string `json:"userland-proxy-path,omitempty"`
+	UserlandProxyMode   string `json:"userland-proxy-mode,omitempty"`
+	FirewallBackend     string `json:"firewall-backend,omitempty"`
 	FixedCIDRv6         string `json:"fixed-cidr-v6,omitempty"`
 }
 
@@ -154,12 +156,45 @@ func verifyDefaultCgroupNsMode(mode string) error {
 	return nil
 }
 
+func verifyFirewallBackend(backend string) error {
+	switch backend {
+	case "", "iptables", "nftables":
+		return nil
+	default:
+		return fmt.Errorf(`invalid firewall backend (%s); use "iptables" or "nftables"`, backend)
+	}
+}
+
+func verifyUserlandProxyMode(conf *Config) error {
+	switch conf.BridgeConfig.UserlandProxyMode {
+	case "", "process":
+		return nil
+	case "internal":
+		if conf.Rootless {
+			// Ports are exposed to the initial namespace by the
+			// docker-proxy of RootlessKit.
+			return errors.New("userland proxy mode (internal) is not supported in rootless mode")
+		}
+		return nil
+	default:
+		return fmt.Errorf(`invalid userland proxy mode (%s); use "process" or "internal"`, conf.BridgeConfig.UserlandProxyMode)
+	}
+}
+
 // ValidatePlatformConfig checks if any platform-specific configuration settings are invalid.
 func (conf *Config) ValidatePlatformConfig() error {
 	if err := verifyDefaultIpcMode(conf.IpcMode); err != nil {
 		return err
 	}
 
+	if err := verifyFirewallBackend(conf.BridgeConfig.FirewallBackend); err != nil {
+		return err
+	}
+
+	if err := verifyUserlandProxyMode(conf); err != nil {
+		return err
+	}
+
 	return verifyDefaultCgroupNsMode(conf.CgroupNamespaceMode)
 }
 
@@ -142,6 +142,34 @@ func TestUnixValidateConfigurationErrors(t *testing.T) {
 			},
 			expectedErr: `runtime name 'runc' is reserved`,
 		},
+		{
+			doc: `invalid firewall backend`,
+			config: &Config{
+				BridgeConfig: BridgeConfig{
+					FirewallBackend: "ebtables",
+				},
+			},
+			expectedErr: `invalid firewall backend (ebtables); use "iptables" or "nftables"`,
+		},
+		{
+			doc: `invalid userland proxy mode`,
+			config: &Config{
+				BridgeConfig: BridgeConfig{
+					UserlandProxyMode: "thread",
+				},
+			},
+			expectedErr: `invalid userland proxy mode (thread); use "process" or "internal"`,
+		},
+		{
+			doc: `internal userland proxy in rootless mode`,
+			config: &Config{
+				Rootless: true,
+				BridgeConfig: BridgeConfig{
+					UserlandProxyMode: "internal",
+				},
+			},
+			expectedErr: `userland proxy mode (internal) is not supported in rootless mode`,
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
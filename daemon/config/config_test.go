@@ -286,6 +286,24 @@ func TestValidateConfigurationErrors(t *testing.T) {
 			},
 			expectedErr: "invalid max download attempts: -10",
 		},
+		{
+			name: "negative events journal max-age",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					Events: EventsConfig{MaxAge: "-1h"},
+				},
+			},
+			expectedErr: "invalid events journal max-age: -1h: must be positive",
+		},
+		{
+			name: "invalid tracing endpoint",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					Tracing: TracingConfig{Endpoint: "localhost:4317"},
+				},
+			},
+			expectedErr: "invalid tracing endpoint: localhost:4317: must be an http or https URL",
+		},
 		// TODO(thaJeztah) temporarily excluding this test as it assumes defaults are set before validating and applying updated configs
 		/*
 			{
@@ -0,0 +1,62 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+// EventsConfig contains the configuration of the events journal, which
+// persists the events emitted by the daemon so that they can be replayed
+// after the daemon restarts.
+type EventsConfig struct {
+	// Journal enables the events journal.
+	Journal bool `json:"journal,omitempty"`
+	// MaxSize is the maximum size of the journal (for example, "64MB").
+	MaxSize string `json:"max-size,omitempty"`
+	// MaxAge is the maximum age of the events kept in the journal (for
+	// example, "720h").
+	MaxAge string `json:"max-age,omitempty"`
+}
+
+// Validate validates the events journal configuration.
+func (c *EventsConfig) Validate() error {
+	if _, err := c.ParseMaxSize(); err != nil {
+		return err
+	}
+	_, err := c.ParseMaxAge()
+	return err
+}
+
+// ParseMaxSize returns the maximum size of the journal in bytes, or 0 if it
+// is not set.
+func (c *EventsConfig) ParseMaxSize() (int64, error) {
+	if c.MaxSize == "" {
+		return 0, nil
+	}
+	size, err := units.RAMInBytes(c.MaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid events journal max-size: %v", err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("invalid events journal max-size: %s: must be positive", c.MaxSize)
+	}
+	return size, nil
+}
+
+// ParseMaxAge returns the maximum age of the events in the journal, or 0 if
+// it is not set.
+func (c *EventsConfig) ParseMaxAge() (time.Duration, error) {
+	if c.MaxAge == "" {
+		return 0, nil
+	}
+	age, err := time.ParseDuration(c.MaxAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid events journal max-age: %v", err)
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("invalid events journal max-age: %s: must be positive", c.MaxAge)
+	}
+	return age, nil
+}
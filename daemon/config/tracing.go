@@ -0,0 +1,44 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// TracingConfig contains the configuration of the export of traces of API
+// requests and daemon operations, using the OpenTelemetry protocol (OTLP).
+type TracingConfig struct {
+	// Endpoint is the URL of the OTLP/gRPC collector the traces are exported
+	// to (for example, "http://localhost:4317"). The traces are exported
+	// using TLS if the scheme is "https". Tracing is disabled if it is not set.
+	Endpoint string `json:"endpoint,omitempty"`
+	// SampleRatio is the ratio of traces which are sampled, between 0 and 1.
+	// All traces are sampled if it is not set. The traces of API requests
+	// with a sampled parent in their traceparent header are always sampled.
+	SampleRatio *float64 `json:"sample-ratio,omitempty"`
+}
+
+// Validate validates the tracing configuration.
+func (c *TracingConfig) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid tracing endpoint: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid tracing endpoint: %s: must be an http or https URL", c.Endpoint)
+		}
+	}
+	if c.SampleRatio != nil && (*c.SampleRatio < 0 || *c.SampleRatio > 1) {
+		return fmt.Errorf("invalid tracing sample-ratio: %v: must be between 0 and 1", *c.SampleRatio)
+	}
+	return nil
+}
+
+// GetSampleRatio returns the ratio of traces which are sampled.
+func (c *TracingConfig) GetSampleRatio() float64 {
+	if c.SampleRatio == nil {
+		return 1
+	}
+	return *c.SampleRatio
+}
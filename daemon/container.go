@@ -16,6 +16,7 @@ import (
 	"github.com/docker/docker/oci/caps"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/restartmanager"
 	"github.com/docker/docker/runconfig"
 	volumemounts "github.com/docker/docker/volume/mounts"
 	"github.com/docker/go-connections/nat"
@@ -289,6 +290,9 @@ func validateHostConfig(hostConfig *containertypes.HostConfig) error {
 	if err := validatePortBindings(hostConfig.PortBindings); err != nil {
 		return err
 	}
+	if err := validateDependencies(hostConfig.DependsOn); err != nil {
+		return err
+	}
 	if err := validateRestartPolicy(hostConfig.RestartPolicy); err != nil {
 		return err
 	}
@@ -329,9 +333,47 @@ func validateHealthCheck(healthConfig *containertypes.HealthConfig) error {
 	if healthConfig.StartPeriod != 0 && healthConfig.StartPeriod < containertypes.MinimumDuration {
 		return errors.Errorf("StartPeriod in Healthcheck cannot be less than %s", containertypes.MinimumDuration)
 	}
+	if err := validateProbeTest(healthConfig.Test); err != nil {
+		return err
+	}
+	if healthConfig.StartupInterval != 0 && healthConfig.StartupInterval < containertypes.MinimumDuration {
+		return errors.Errorf("StartupInterval in Healthcheck cannot be less than %s", containertypes.MinimumDuration)
+	}
+	if healthConfig.StartupRetries < 0 {
+		return errors.Errorf("StartupRetries in Healthcheck cannot be negative")
+	}
+	if err := validateProbeTest(healthConfig.StartupTest); err != nil {
+		return errors.Wrap(err, "invalid StartupTest in Healthcheck")
+	}
+	switch healthConfig.OnUnhealthy {
+	case "", containertypes.UnhealthyActionNone, containertypes.UnhealthyActionRestart, containertypes.UnhealthyActionStop:
+	case containertypes.UnhealthyActionKill:
+		if healthConfig.OnUnhealthySignal != "" {
+			if _, err := signal.ParseSignal(healthConfig.OnUnhealthySignal); err != nil {
+				return errors.Wrap(err, "invalid OnUnhealthySignal in Healthcheck")
+			}
+		}
+	default:
+		return errors.Errorf("invalid OnUnhealthy action in Healthcheck: %s", healthConfig.OnUnhealthy)
+	}
 	return nil
 }
 
+// validateProbeTest validates the options of the HTTP and TCP probes.
+func validateProbeTest(test []string) error {
+	if len(test) == 0 {
+		return nil
+	}
+	var err error
+	switch test[0] {
+	case "HTTP":
+		_, err = parseHTTPProbe(test)
+	case "TCP":
+		_, err = parseTCPProbe(test)
+	}
+	return err
+}
+
 func validatePortBindings(ports nat.PortMap) error {
 	for port := range ports {
 		_, portStr := nat.SplitProtoPort(string(port))
@@ -364,6 +406,28 @@ func validateRestartPolicy(policy containertypes.RestartPolicy) error {
 	default:
 		return errors.Errorf("invalid restart policy '%s'", policy.Name)
 	}
+	if policy.InitialDelay < 0 {
+		return errors.Errorf("initial restart delay cannot be negative")
+	}
+	if policy.MaxDelay < 0 {
+		return errors.Errorf("maximum restart delay cannot be negative")
+	}
+	maxDelay := policy.MaxDelay
+	if maxDelay == 0 {
+		maxDelay = restartmanager.DefaultMaxDelay
+	}
+	if policy.InitialDelay > maxDelay {
+		return errors.Errorf("initial restart delay cannot be greater than the maximum restart delay (%s)", maxDelay)
+	}
+	if policy.Multiplier != 0 && policy.Multiplier < 1 {
+		return errors.Errorf("restart delay multiplier cannot be less than 1")
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return errors.Errorf("restart delay jitter must be between 0 and 1")
+	}
+	if policy.ResetAfter < 0 {
+		return errors.Errorf("restart delay reset period cannot be negative")
+	}
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -24,6 +25,8 @@ import (
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/go-connections/nat"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -33,11 +36,29 @@ var (
 	getPortMapInfo    = getSandboxPortMapInfo
 )
 
+// getNetworkDNSConfig returns the DNS configuration of the network of the
+// network mode of the container, which overrides the DNS configuration of the
+// daemon, or nil if the network has none.
+func (daemon *Daemon) getNetworkDNSConfig(container *container.Container) *libnetwork.DNSConfig {
+	if daemon.netController == nil || !container.HostConfig.NetworkMode.IsUserDefined() {
+		return nil
+	}
+	nw, err := daemon.FindNetwork(container.HostConfig.NetworkMode.NetworkName())
+	if err != nil {
+		return nil
+	}
+	return nw.Info().DNSConfig()
+}
+
 func (daemon *Daemon) getDNSSearchSettings(container *container.Container) []string {
 	if len(container.HostConfig.DNSSearch) > 0 {
 		return container.HostConfig.DNSSearch
 	}
 
+	if nwDNS := daemon.getNetworkDNSConfig(container); nwDNS != nil && len(nwDNS.Search) > 0 {
+		return nwDNS.Search
+	}
+
 	if len(daemon.configStore.DNSSearch) > 0 {
 		return daemon.configStore.DNSSearch
 	}
@@ -72,8 +93,11 @@ func (daemon *Daemon) buildSandboxOptions(container *container.Container) ([]lib
 		return nil, err
 	}
 
+	nwDNS := daemon.getNetworkDNSConfig(container)
 	if len(container.HostConfig.DNS) > 0 {
 		dns = container.HostConfig.DNS
+	} else if nwDNS != nil && len(nwDNS.Servers) > 0 {
+		dns = nwDNS.Servers
 	} else if len(daemon.configStore.DNS) > 0 {
 		dns = daemon.configStore.DNS
 	}
@@ -90,6 +114,8 @@ func (daemon *Daemon) buildSandboxOptions(container *container.Container) ([]lib
 
 	if len(container.HostConfig.DNSOptions) > 0 {
 		dnsOptions = container.HostConfig.DNSOptions
+	} else if nwDNS != nil && len(nwDNS.Options) > 0 {
+		dnsOptions = nwDNS.Options
 	} else if len(daemon.configStore.DNSOptions) > 0 {
 		dnsOptions = daemon.configStore.DNSOptions
 	}
@@ -526,11 +552,14 @@ func (daemon *Daemon) updateContainerNetworkSettings(container *container.Contai
 	}
 }
 
-func (daemon *Daemon) allocateNetwork(container *container.Container) (retErr error) {
+func (daemon *Daemon) allocateNetwork(ctx context.Context, container *container.Container) (retErr error) {
 	if daemon.netController == nil {
 		return nil
 	}
 
+	ctx, span := tracer.Start(ctx, "daemon.allocateNetwork")
+	defer func() { endSpan(span, retErr) }()
+
 	var (
 		start      = time.Now()
 		controller = daemon.netController
@@ -559,7 +588,7 @@ func (daemon *Daemon) allocateNetwork(container *container.Container) (retErr er
 	defaultNetName := runconfig.DefaultDaemonNetworkMode().NetworkName()
 	if nConf, ok := container.NetworkSettings.Networks[defaultNetName]; ok {
 		cleanOperationalData(nConf)
-		if err := daemon.connectToNetwork(container, defaultNetName, nConf.EndpointSettings, updateSettings); err != nil {
+		if err := daemon.tracedConnectToNetwork(ctx, container, defaultNetName, nConf.EndpointSettings, updateSettings); err != nil {
 			return err
 		}
 
@@ -577,7 +606,7 @@ func (daemon *Daemon) allocateNetwork(container *container.Container) (retErr er
 
 	for netName, epConf := range networks {
 		cleanOperationalData(epConf)
-		if err := daemon.connectToNetwork(container, netName, epConf.EndpointSettings, updateSettings); err != nil {
+		if err := daemon.tracedConnectToNetwork(ctx, container, netName, epConf.EndpointSettings, updateSettings); err != nil {
 			return err
 		}
 	}
@@ -590,7 +619,9 @@ func (daemon *Daemon) allocateNetwork(container *container.Container) (retErr er
 			if err != nil {
 				return err
 			}
+			_, sbSpan := tracer.Start(ctx, "libnetwork.NewSandbox")
 			sb, err := daemon.netController.NewSandbox(container.ID, sbOptions...)
+			endSpan(sbSpan, err)
 			if err != nil {
 				return err
 			}
@@ -611,6 +642,17 @@ func (daemon *Daemon) allocateNetwork(container *container.Container) (retErr er
 	return nil
 }
 
+// tracedConnectToNetwork connects the container to the network, recording the
+// operation, including the setup of the network sandbox of the container, in
+// a span.
+func (daemon *Daemon) tracedConnectToNetwork(ctx context.Context, container *container.Container, idOrName string, endpointConfig *networktypes.EndpointSettings, updateSettings bool) (err error) {
+	_, span := tracer.Start(ctx, "daemon.connectToNetwork", trace.WithAttributes(
+		attribute.String("network.name", idOrName),
+	))
+	defer func() { endSpan(span, err) }()
+	return daemon.connectToNetwork(container, idOrName, endpointConfig, updateSettings)
+}
+
 func (daemon *Daemon) getNetworkSandbox(container *container.Container) libnetwork.Sandbox {
 	var sb libnetwork.Sandbox
 	daemon.netController.WalkSandboxes(func(s libnetwork.Sandbox) bool {
@@ -955,7 +997,7 @@ func (daemon *Daemon) tryDetachContainerFromClusterNetwork(network libnetwork.Ne
 	daemon.LogNetworkEventWithAttributes(network, "disconnect", attributes)
 }
 
-func (daemon *Daemon) initializeNetworking(container *container.Container) error {
+func (daemon *Daemon) initializeNetworking(ctx context.Context, container *container.Container) error {
 	var err error
 
 	if container.HostConfig.NetworkMode.IsContainer() {
@@ -984,7 +1026,7 @@ func (daemon *Daemon) initializeNetworking(container *container.Container) error
 		}
 	}
 
-	if err := daemon.allocateNetwork(container); err != nil {
+	if err := daemon.allocateNetwork(ctx, container); err != nil {
 		return err
 	}
 
@@ -1,10 +1,208 @@
 package containerd
 
 import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/builder"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/image/cache"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // MakeImageCache creates a stateful image cache.
-func (i *ImageService) MakeImageCache(cacheFrom []string) builder.ImageCache {
-	panic("not implemented")
+func (i *ImageService) MakeImageCache(sourceRefs []string) builder.ImageCache {
+	local := &localCache{imageService: i}
+	if len(sourceRefs) == 0 {
+		return local
+	}
+
+	cache := &imageCache{imageService: i, localCache: local}
+	for _, ref := range sourceRefs {
+		img, err := i.GetImage(ref, nil)
+		if err != nil {
+			logrus.Warnf("Could not look up %s for cache resolution, skipping: %+v", ref, err)
+			continue
+		}
+		cache.sources = append(cache.sources, img)
+	}
+
+	return cache
+}
+
+// localCache is a cache based on the parent chain of the images built or
+// committed locally.
+type localCache struct {
+	imageService *ImageService
+
+	// children indexes the images of the store by parent. It is built on
+	// the first lookup, so that the images are only listed once per build.
+	children map[image.ID][]*image.Image
+}
+
+// GetCache returns the ID of the most recently created child of parentID
+// which had the same config when it was created.
+func (lc *localCache) GetCache(parentID string, cfg *container.Config) (string, error) {
+	if lc.children == nil {
+		children, err := lc.imageService.childrenIndex(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		lc.children = children
+	}
+
+	var match *image.Image
+	for _, child := range lc.children[image.ID(parentID)] {
+		if cache.CompareConfig(&child.ContainerConfig, cfg) {
+			// check for the most up to date match
+			if match == nil || match.Created.Before(child.Created) {
+				match = child
+			}
+		}
+	}
+	if match == nil {
+		return "", nil
+	}
+	return match.ID().String(), nil
+}
+
+// imageCache is a cache based on the history of a set of source images, such
+// as the images passed with --cache-from.
+type imageCache struct {
+	sources      []*image.Image
+	imageService *ImageService
+	localCache   *localCache
+}
+
+// GetCache returns the image id found in the cache
+func (ic *imageCache) GetCache(parentID string, cfg *container.Config) (string, error) {
+	imgID, err := ic.localCache.GetCache(parentID, cfg)
+	if err != nil {
+		return "", err
+	}
+	if imgID != "" {
+		for _, s := range ic.sources {
+			if ic.isParent(s.ID(), image.ID(imgID)) {
+				return imgID, nil
+			}
+		}
+	}
+
+	var parent *image.Image
+	lenHistory := 0
+	if parentID != "" {
+		parent, err = ic.imageService.GetImage(parentID, nil)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to find image %v", parentID)
+		}
+		lenHistory = len(parent.History)
+	}
+
+	for _, target := range ic.sources {
+		if !cache.IsValidParent(target, parent) || !cache.IsValidConfig(cfg, target.History[lenHistory]) {
+			continue
+		}
+
+		if len(target.History)-1 == lenHistory { // last
+			return target.ID().String(), nil
+		}
+
+		imgID, err := ic.restoreCachedImage(parent, target, cfg)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to restore cached image from %q to %v", parentID, target.ID())
+		}
+
+		ic.sources = []*image.Image{target} // avoid jumping to different target, tuned for safety atm
+		return imgID, nil
+	}
+
+	return "", nil
+}
+
+// restoreCachedImage creates an image for the step of the history of target
+// following parent, reusing the layer of target for that step.
+func (ic *imageCache) restoreCachedImage(parent, target *image.Image, cfg *container.Config) (string, error) {
+	var (
+		history    []image.History
+		rootFS     = image.NewRootFS()
+		lenHistory = 0
+		parentID   string
+	)
+	if parent != nil {
+		history = parent.History
+		rootFS = parent.RootFS.Clone()
+		lenHistory = len(parent.History)
+		parentID = parent.ID().String()
+	}
+	history = append(history, target.History[lenHistory])
+	if layer := cache.GetLayerForHistoryIndex(target, lenHistory); layer != "" {
+		rootFS.Append(layer)
+	}
+
+	config, err := json.Marshal(&image.Image{
+		V1Image: image.V1Image{
+			DockerVersion: dockerversion.Version,
+			Config:        cfg,
+			Architecture:  target.Architecture,
+			OS:            target.OS,
+			Author:        target.Author,
+			Created:       history[len(history)-1].Created,
+		},
+		RootFS:     rootFS,
+		History:    history,
+		OSFeatures: target.OSFeatures,
+		OSVersion:  target.OSVersion,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal image config")
+	}
+
+	img, err := ic.imageService.CreateImage(config, parentID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create cache image")
+	}
+	return img.ImageID(), nil
+}
+
+// isParent returns whether parentID is an ancestor of imgID.
+func (ic *imageCache) isParent(imgID, parentID image.ID) bool {
+	img, err := ic.imageService.GetImage(imgID.String(), nil)
+	if err != nil || img.Parent == "" {
+		return false
+	}
+	if img.Parent == parentID {
+		return true
+	}
+	return ic.isParent(img.Parent, parentID)
+}
+
+// childrenIndex returns the images of the store indexed by the ID of their
+// parent. The images without a parent are indexed by the empty ID.
+func (i *ImageService) childrenIndex(ctx context.Context) (map[image.ID][]*image.Image, error) {
+	imgs, err := i.client.ImageService().List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	seen := make(map[image.ID]struct{})
+	children := make(map[image.ID][]*image.Image)
+	for _, img := range imgs {
+		imgID := image.ID(img.Target.Digest)
+		if _, ok := seen[imgID]; ok {
+			continue
+		}
+		seen[imgID] = struct{}{}
+
+		child, err := i.GetImage(imgID.String(), nil)
+		if err != nil {
+			// Images for other platforms, or whose content was removed,
+			// can't be children of the image.
+			continue
+		}
+		children[child.Parent] = append(children[child.Parent], child)
+	}
+	return children, nil
 }
@@ -0,0 +1,45 @@
+package containerd
+
+import (
+	"fmt"
+
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	"github.com/docker/docker/errdefs"
+)
+
+// translateError converts errors returned by containerd into their errdefs
+// equivalent, so that the API can return the appropriate status code.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case cerrdefs.IsNotFound(err):
+		return errdefs.NotFound(err)
+	case cerrdefs.IsAlreadyExists(err):
+		return errdefs.Conflict(err)
+	case cerrdefs.IsInvalidArgument(err):
+		return errdefs.InvalidParameter(err)
+	case cerrdefs.IsFailedPrecondition(err):
+		return errdefs.Conflict(err)
+	case cerrdefs.IsUnavailable(err):
+		return errdefs.Unavailable(err)
+	case cerrdefs.IsNotImplemented(err):
+		return errdefs.NotImplemented(err)
+	}
+	return err
+}
+
+type invalidFilter struct {
+	filter string
+	value  interface{}
+}
+
+func (e invalidFilter) Error() string {
+	msg := "invalid filter '" + e.filter
+	if e.value != nil {
+		msg += fmt.Sprintf("=%s", e.value)
+	}
+	return msg + "'"
+}
+
+func (e invalidFilter) InvalidParameter() {}
@@ -1,11 +1,230 @@
 package containerd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/content"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/daemon/images"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
 )
 
+// imageNameDanglingPrefix is the prefix of the names of dangling images.
+const imageNameDanglingPrefix = "moby-dangling@"
+
+var truncatedID = regexp.MustCompile(`^(sha256:)?([a-f0-9]{4,64})$`)
+
 // GetImage returns an image corresponding to the image referred to by refOrID.
 func (i *ImageService) GetImage(refOrID string, platform *specs.Platform) (retImg *image.Image, retErr error) {
-	panic("not implemented")
+	ctx := context.TODO()
+	desc, err := i.resolveImage(ctx, refOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := i.readImageConfigBlob(ctx, desc, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewImage(image.ID(desc.Target.Digest))
+	if err := json.Unmarshal(blob, img); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal image config of %s", desc.Name)
+	}
+	if img.RootFS == nil {
+		img.RootFS = image.NewRootFS()
+	}
+
+	refs, err := i.imageReferences(ctx, desc.Target.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	diffIDs := make([]digest.Digest, 0, len(img.RootFS.DiffIDs))
+	for _, diffID := range img.RootFS.DiffIDs {
+		diffIDs = append(diffIDs, digest.Digest(diffID))
+	}
+	size, err := computeVirtualSize(identity.ChainIDs(diffIDs), i.snapshotSizeFn(ctx))
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return nil, err
+	}
+
+	img.Details = &image.Details{
+		References:  refs,
+		Size:        size,
+		Metadata:    nil,
+		Driver:      i.snapshotter,
+		LastUpdated: desc.UpdatedAt,
+	}
+	return img, nil
+}
+
+// imageReferences returns the tags and digest references of all images in
+// the image store that have the given target digest.
+func (i *ImageService) imageReferences(ctx context.Context, target digest.Digest) ([]reference.Named, error) {
+	imgs, err := i.client.ImageService().List(ctx, "target.digest=="+target.String())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var (
+		refs    []reference.Named
+		digests = map[string]struct{}{}
+	)
+	for _, img := range imgs {
+		if isDanglingImage(img) {
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(img.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := named.(reference.Tagged); ok {
+			refs = append(refs, named)
+		}
+		if _, ok := digests[named.Name()]; ok {
+			continue
+		}
+		if canonical, err := reference.WithDigest(reference.TrimNamed(named), target); err == nil {
+			digests[named.Name()] = struct{}{}
+			refs = append(refs, canonical)
+		}
+	}
+	return refs, nil
+}
+
+// snapshotSizeFn returns a function that returns the size of the snapshot
+// with the given chainID.
+func (i *ImageService) snapshotSizeFn(ctx context.Context) func(d digest.Digest) (int64, error) {
+	snapshotter := i.client.SnapshotService(i.snapshotter)
+	sizeCache := make(map[digest.Digest]int64)
+	return func(d digest.Digest) (int64, error) {
+		if s, ok := sizeCache[d]; ok {
+			return s, nil
+		}
+		usage, err := snapshotter.Usage(ctx, d.String())
+		if err != nil {
+			return 0, err
+		}
+		sizeCache[d] = usage.Size
+		return usage.Size, nil
+	}
+}
+
+// resolveImage looks up an image in the containerd image store by reference,
+// image ID, or (truncated) image ID.
+func (i *ImageService) resolveImage(ctx context.Context, refOrID string) (containerdimages.Image, error) {
+	parsed, err := reference.ParseAnyReference(refOrID)
+	if err != nil {
+		return containerdimages.Image{}, errdefs.InvalidParameter(err)
+	}
+
+	is := i.client.ImageService()
+
+	if digested, ok := parsed.(reference.Digested); ok {
+		if _, isNamed := parsed.(reference.Named); !isNamed {
+			imgs, err := is.List(ctx, "target.digest=="+digested.Digest().String())
+			if err != nil {
+				return containerdimages.Image{}, errors.Wrap(err, "failed to lookup digest")
+			}
+			if len(imgs) == 0 {
+				return containerdimages.Image{}, images.ErrImageDoesNotExist{Ref: parsed}
+			}
+			return imgs[0], nil
+		}
+	}
+
+	img, err := is.Get(ctx, reference.TagNameOnly(parsed.(reference.Named)).String())
+	if err == nil {
+		return img, nil
+	}
+	if !cerrdefs.IsNotFound(err) {
+		return containerdimages.Image{}, err
+	}
+
+	// If the identifier could be a truncated ID, attempt to match it against
+	// the image digests.
+	if m := truncatedID.FindStringSubmatch(refOrID); m != nil {
+		id := m[2]
+		imgs, err := is.List(ctx, "target.digest~="+strconv.Quote(fmt.Sprintf("^sha256:%s[0-9a-f]{%d}$", regexp.QuoteMeta(id), 64-len(id))))
+		if err != nil {
+			return containerdimages.Image{}, err
+		}
+		if len(imgs) > 0 {
+			for _, img := range imgs[1:] {
+				if img.Target.Digest != imgs[0].Target.Digest {
+					return containerdimages.Image{}, errdefs.NotFound(errors.Errorf("ambiguous reference: %s", strings.TrimPrefix(refOrID, "sha256:")))
+				}
+			}
+			return imgs[0], nil
+		}
+	}
+
+	return containerdimages.Image{}, images.ErrImageDoesNotExist{Ref: parsed}
+}
+
+// readImageConfig reads the image config of img for the given platform from
+// the content store. The default platform is used if platform is nil.
+func (i *ImageService) readImageConfig(ctx context.Context, img containerdimages.Image, platform *specs.Platform) (specs.Image, error) {
+	blob, err := i.readImageConfigBlob(ctx, img, platform)
+	if err != nil {
+		return specs.Image{}, err
+	}
+
+	var config specs.Image
+	if err := json.Unmarshal(blob, &config); err != nil {
+		return specs.Image{}, errors.Wrapf(err, "failed to unmarshal image config of %s", img.Name)
+	}
+	return config, nil
+}
+
+// readImageConfigBlob reads the raw image config of img for the given
+// platform from the content store. The default platform is used if platform
+// is nil.
+func (i *ImageService) readImageConfigBlob(ctx context.Context, img containerdimages.Image, platform *specs.Platform) ([]byte, error) {
+	cs := i.client.ContentStore()
+	configDesc, err := img.Config(ctx, cs, platformMatcher(platform))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	blob, err := content.ReadBlob(ctx, cs, configDesc)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return blob, nil
+}
+
+// platformMatcher returns a matcher for platform, or for the default
+// platform if platform is nil.
+func platformMatcher(platform *specs.Platform) platforms.MatchComparer {
+	if platform == nil {
+		return platforms.Default()
+	}
+	return platforms.Only(*platform)
+}
+
+// danglingImageName returns the name used for images that have no tag, such
+// as images created by commit or build without a tag. The containerd image
+// store requires each image to have a name.
+func danglingImageName(dgst digest.Digest) string {
+	return imageNameDanglingPrefix + dgst.String()
+}
+
+// isDanglingImage returns whether img is a dangling image, as created by
+// danglingImageName.
+func isDanglingImage(img containerdimages.Image) bool {
+	return img.Name == danglingImageName(img.Target.Digest)
 }
@@ -2,21 +2,405 @@ package containerd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
 
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/labels"
+	"github.com/containerd/containerd/leases"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/builder"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/containerfs"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/system"
+	registrypkg "github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// leaseLabelClassicBuilder is set on the leases taken by the classic builder,
+// so that they can be told apart from other leases.
+const leaseLabelClassicBuilder = "org.mobyproject.lease.classicbuilder"
+
 // GetImageAndReleasableLayer returns an image and releaseable layer for a
 // reference or ID. Every call to GetImageAndReleasableLayer MUST call
 // releasableLayer.Release() to prevent leaking of layers.
 func (i *ImageService) GetImageAndReleasableLayer(ctx context.Context, refOrID string, opts backend.GetImageAndLayerOptions) (builder.Image, builder.ROLayer, error) {
-	panic("not implemented")
+	if refOrID == "" { // ie FROM scratch
+		os := runtime.GOOS
+		if opts.Platform != nil {
+			os = opts.Platform.OS
+		}
+		if !system.IsOSSupported(os) {
+			return nil, nil, system.ErrNotSupportedOperatingSystem
+		}
+		lyr, err := i.newROLayerForImage(ctx, nil)
+		return nil, lyr, err
+	}
+
+	if opts.PullOption != backend.PullOptionForcePull {
+		img, err := i.GetImage(refOrID, opts.Platform)
+		if err != nil && opts.PullOption == backend.PullOptionNoPull {
+			return nil, nil, err
+		}
+		if err != nil && !errdefs.IsNotFound(err) {
+			return nil, nil, err
+		}
+		if img != nil {
+			if !system.IsOSSupported(img.OperatingSystem()) {
+				return nil, nil, system.ErrNotSupportedOperatingSystem
+			}
+			lyr, err := i.newROLayerForImage(ctx, img)
+			return img, lyr, err
+		}
+	}
+
+	img, err := i.pullForBuilder(ctx, refOrID, opts.AuthConfig, opts.Output, opts.Platform)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !system.IsOSSupported(img.OperatingSystem()) {
+		return nil, nil, system.ErrNotSupportedOperatingSystem
+	}
+	lyr, err := i.newROLayerForImage(ctx, img)
+	return img, lyr, err
+}
+
+func (i *ImageService) pullForBuilder(ctx context.Context, name string, authConfigs map[string]registry.AuthConfig, output io.Writer, platform *ocispec.Platform) (*image.Image, error) {
+	ref, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.TagNameOnly(ref)
+
+	pullRegistryAuth := &registry.AuthConfig{}
+	if len(authConfigs) > 0 {
+		// The request came with a full auth config, use it
+		repoInfo, err := i.registryService.ResolveRepository(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedConfig := registrypkg.ResolveAuthConfig(authConfigs, repoInfo.Index)
+		pullRegistryAuth = &resolvedConfig
+	}
+
+	if err := i.PullImage(ctx, reference.TrimNamed(ref).String(), tagOrDigestString(ref), platform, nil, pullRegistryAuth, output); err != nil {
+		return nil, err
+	}
+	return i.GetImage(ref.String(), platform)
+}
+
+// newROLayerForImage returns a read-only layer for the rootfs of img, or an
+// empty layer if img is nil. The snapshot of the image is held by a lease, so
+// that it can't be removed before the layer is released.
+func (i *ImageService) newROLayerForImage(ctx context.Context, img *image.Image) (builder.ROLayer, error) {
+	if img == nil || img.RootFS.ChainID() == "" {
+		return &rolayer{c: i.client, snapshotter: i.snapshotter}, nil
+	}
+
+	key := img.RootFS.ChainID().String()
+	if _, err := i.client.SnapshotService(i.snapshotter).Stat(ctx, key); err != nil {
+		return nil, errors.Wrapf(translateError(err), "failed to get layer for image %s", img.ImageID())
+	}
+
+	lm := i.client.LeasesService()
+	lease, err := createBuilderLease(ctx, lm)
+	if err != nil {
+		return nil, err
+	}
+	if err := lm.AddResource(ctx, lease, leases.Resource{ID: key, Type: "snapshots/" + i.snapshotter}); err != nil {
+		if err := lm.Delete(context.Background(), lease); err != nil {
+			logrus.WithError(err).WithField("lease", lease.ID).Warn("failed to delete lease")
+		}
+		return nil, errors.Wrapf(err, "failed to lease layer for image %s", img.ImageID())
+	}
+
+	diffIDs := img.RootFS.DiffIDs
+	return &rolayer{
+		key:         key,
+		c:           i.client,
+		snapshotter: i.snapshotter,
+		diffID:      digest.Digest(diffIDs[len(diffIDs)-1]),
+		lease:       &lease,
+	}, nil
+}
+
+func createBuilderLease(ctx context.Context, lm leases.Manager) (leases.Lease, error) {
+	return lm.Create(ctx,
+		leases.WithRandomID(),
+		leases.WithExpiration(24*time.Hour),
+		leases.WithLabels(map[string]string{leaseLabelClassicBuilder: "true"}),
+	)
+}
+
+// rolayer is a read-only layer backed by a committed snapshot.
+type rolayer struct {
+	key         string
+	c           *containerd.Client
+	snapshotter string
+	diffID      digest.Digest
+	lease       *leases.Lease
+}
+
+func (rl *rolayer) DiffID() layer.DiffID {
+	if rl.diffID == "" {
+		return layer.DigestSHA256EmptyTar
+	}
+	return layer.DiffID(rl.diffID)
+}
+
+func (rl *rolayer) Release() error {
+	if rl.lease == nil {
+		return nil
+	}
+	if err := rl.c.LeasesService().Delete(context.TODO(), *rl.lease); err != nil && !cerrdefs.IsNotFound(err) {
+		return errors.Wrap(err, "failed to release ROLayer")
+	}
+	rl.lease = nil
+	return nil
+}
+
+func (rl *rolayer) NewRWLayer() (_ builder.RWLayer, retErr error) {
+	ctx := context.TODO()
+
+	lm := rl.c.LeasesService()
+	lease, err := createBuilderLease(ctx, lm)
+	if err != nil {
+		return nil, err
+	}
+	rw := &rwlayer{
+		key:         stringid.GenerateRandomID(),
+		c:           rl.c,
+		snapshotter: rl.snapshotter,
+		lease:       &lease,
+	}
+	defer func() {
+		if retErr != nil {
+			if err := rw.Release(); err != nil {
+				logrus.WithError(err).Warn("failed to release RWLayer")
+			}
+		}
+	}()
+
+	mounts, err := rl.c.SnapshotService(rl.snapshotter).Prepare(leases.WithLease(ctx, lease.ID), rw.key, rl.key)
+	if err != nil {
+		return nil, errors.Wrap(translateError(err), "failed to create rwlayer")
+	}
+
+	root, err := os.MkdirTemp("", "rootfs-mount")
+	if err != nil {
+		return nil, err
+	}
+	if err := mount.All(mounts, root); err != nil {
+		_ = os.Remove(root)
+		return nil, errors.Wrap(err, "failed to mount rwlayer")
+	}
+	rw.root = root
+	return rw, nil
+}
+
+// rwlayer is a read-write layer backed by an active snapshot, which is
+// mounted for as long as the layer is not committed or released.
+type rwlayer struct {
+	key         string
+	c           *containerd.Client
+	snapshotter string
+	root        string
+	lease       *leases.Lease
+}
+
+func (rw *rwlayer) Root() containerfs.ContainerFS {
+	return containerfs.NewLocalContainerFS(rw.root)
+}
+
+func (rw *rwlayer) Commit() (_ builder.ROLayer, retErr error) {
+	ctx := context.TODO()
+
+	if err := rw.unmount(); err != nil {
+		return nil, err
+	}
+
+	// The committed snapshot and the layer blob are held by a new lease,
+	// which is owned by the returned ROLayer.
+	lm := rw.c.LeasesService()
+	lease, err := createBuilderLease(ctx, lm)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := lm.Delete(context.Background(), lease); err != nil {
+				logrus.WithError(err).WithField("lease", lease.ID).Warn("failed to delete lease")
+			}
+		}
+	}()
+	ctx = leases.WithLease(ctx, lease.ID)
+
+	sn := rw.c.SnapshotService(rw.snapshotter)
+	key := stringid.GenerateRandomID()
+	if err := sn.Commit(ctx, key, rw.key); err != nil {
+		return nil, errors.Wrap(translateError(err), "failed to commit rwlayer")
+	}
+
+	_, diffID, err := createDiff(ctx, key, sn, rw.c.ContentStore(), rw.c.DiffService())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export layer")
+	}
+
+	return &rolayer{
+		key:         key,
+		c:           rw.c,
+		snapshotter: rw.snapshotter,
+		diffID:      diffID,
+		lease:       &lease,
+	}, nil
+}
+
+func (rw *rwlayer) Release() error {
+	if rw.lease == nil {
+		return nil
+	}
+	if err := rw.unmount(); err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	if err := rw.c.SnapshotService(rw.snapshotter).Remove(ctx, rw.key); err != nil && !cerrdefs.IsNotFound(err) {
+		// The snapshot is held by the lease, so it is garbage-collected
+		// when the lease is removed.
+		logrus.WithError(err).WithField("key", rw.key).Warn("failed to remove rwlayer snapshot")
+	}
+	if err := rw.c.LeasesService().Delete(ctx, *rw.lease); err != nil && !cerrdefs.IsNotFound(err) {
+		return errors.Wrap(err, "failed to release RWLayer")
+	}
+	rw.lease = nil
+	return nil
+}
+
+func (rw *rwlayer) unmount() error {
+	if rw.root == "" {
+		return nil
+	}
+	if err := mount.UnmountAll(rw.root, 0); err != nil {
+		return errors.Wrap(err, "failed to unmount RWLayer")
+	}
+	if err := os.Remove(rw.root); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	rw.root = ""
+	return nil
 }
 
 // CreateImage creates a new image by adding a config and ID to the image store.
 // This is similar to LoadImage() except that it receives JSON encoded bytes of
 // an image instead of a tar archive.
+//
+// The layers of the image which are not part of parent must have been created
+// by committing an RWLayer, or be present in the content store already.
 func (i *ImageService) CreateImage(config []byte, parent string) (builder.Image, error) {
-	panic("not implemented")
+	ctx := context.TODO()
+
+	ctx, release, err := i.withLease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := image.NewFromJSON(config)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(errors.Wrap(err, "failed to create image"))
+	}
+	platform := platforms.Only(ocispec.Platform{
+		OS:           img.OperatingSystem(),
+		Architecture: img.BaseImgArch(),
+		Variant:      img.BaseImgVariant(),
+	})
+
+	cs := i.client.ContentStore()
+
+	var layers []ocispec.Descriptor
+	if parent != "" {
+		parentImg, err := i.resolveImage(ctx, parent)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find parent %s", parent)
+		}
+		parentManifest, err := containerdimages.Manifest(ctx, cs, parentImg.Target, platform)
+		if err != nil {
+			return nil, errors.Wrapf(translateError(err), "failed to find parent %s", parent)
+		}
+		layers = parentManifest.Layers
+		img.Parent = image.ID(parentImg.Target.Digest)
+	}
+
+	diffIDs := img.RootFS.DiffIDs
+	if len(layers) > len(diffIDs) {
+		return nil, errdefs.InvalidParameter(errors.New("image has less layers than its parent"))
+	}
+	for _, diffID := range diffIDs[len(layers):] {
+		desc, err := findLayerBlob(ctx, cs, digest.Digest(diffID))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, desc)
+	}
+
+	manifestDesc, err := writeContentsForImage(ctx, i.snapshotter, cs, img, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrdImg := containerdimages.Image{
+		Name:      danglingImageName(manifestDesc.Digest),
+		Target:    manifestDesc,
+		CreatedAt: time.Now(),
+	}
+	if _, err := i.client.ImageService().Create(ctx, ctrdImg); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return nil, errors.Wrap(err, "failed to create new image")
+	}
+
+	// Unpack the image, so that its rootfs can be used without pulling.
+	// Snapshots of the parent image are reused.
+	if err := containerd.NewImageWithPlatform(i.client, ctrdImg, platform).Unpack(ctx, i.snapshotter); err != nil {
+		return nil, errors.Wrap(translateError(err), "failed to unpack image")
+	}
+
+	return i.GetImage(manifestDesc.Digest.String(), nil)
+}
+
+// findLayerBlob returns the descriptor of a layer blob in the content store
+// whose uncompressed digest is diffID.
+func findLayerBlob(ctx context.Context, cs content.Store, diffID digest.Digest) (ocispec.Descriptor, error) {
+	var desc ocispec.Descriptor
+	errFound := errors.New("found")
+	err := cs.Walk(ctx, func(info content.Info) error {
+		desc = ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageLayerGzip,
+			Digest:    info.Digest,
+			Size:      info.Size,
+		}
+		return errFound
+	}, fmt.Sprintf("labels.%q==%s", labels.LabelUncompressed, diffID))
+	if err == errFound {
+		return desc, nil
+	}
+	if err != nil {
+		return ocispec.Descriptor{}, translateError(err)
+	}
+	return ocispec.Descriptor{}, errdefs.NotFound(errors.Errorf("layer %s not found", diffID))
 }
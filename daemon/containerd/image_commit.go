@@ -1,13 +1,237 @@
 package containerd
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/diff"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/rootfs"
+	"github.com/containerd/containerd/snapshots"
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // CommitImage creates a new image from a commit config.
 func (i *ImageService) CommitImage(c backend.CommitConfig) (image.ID, error) {
-	panic("not implemented")
+	ctx := context.TODO()
+
+	ctx, release, err := i.withLease(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	var (
+		parent         *image.Image
+		parentManifest ocispec.Manifest
+	)
+	if c.ParentImageID == "" {
+		parent = new(image.Image)
+		parent.RootFS = image.NewRootFS()
+	} else {
+		parentImg, err := i.resolveImage(ctx, c.ParentImageID)
+		if err != nil {
+			return "", err
+		}
+		parentManifest, err = containerdimages.Manifest(ctx, i.client.ContentStore(), parentImg.Target, platformMatcher(nil))
+		if err != nil {
+			return "", translateError(err)
+		}
+		parent, err = i.GetImage(c.ParentImageID, nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var (
+		cs     = i.client.ContentStore()
+		sn     = i.client.SnapshotService(i.snapshotter)
+		differ = i.client.DiffService()
+	)
+
+	diffLayerDesc, diffID, err := createDiff(ctx, c.ContainerID, sn, cs, differ)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to export layer")
+	}
+
+	cc := image.ChildConfig{
+		ContainerID:     c.ContainerID,
+		Author:          c.Author,
+		Comment:         c.Comment,
+		ContainerConfig: c.ContainerConfig,
+		Config:          c.Config,
+		DiffID:          layer.DiffID(diffID),
+	}
+	child := image.NewChildImage(parent, cc, c.ContainerOS)
+	if c.ParentImageID != "" {
+		child.Parent = image.ID(c.ParentImageID)
+	}
+
+	layers := parentManifest.Layers
+	if !layer.IsEmpty(cc.DiffID) {
+		layers = append(layers, diffLayerDesc)
+		if err := applyDiffLayer(ctx, parent.RootFS, child.RootFS, sn, differ, diffLayerDesc); err != nil {
+			return "", errors.Wrap(err, "failed to apply diff")
+		}
+	}
+
+	manifestDesc, err := writeContentsForImage(ctx, i.snapshotter, cs, child, layers)
+	if err != nil {
+		return "", err
+	}
+
+	img := containerdimages.Image{
+		Name:      danglingImageName(manifestDesc.Digest),
+		Target:    manifestDesc,
+		CreatedAt: time.Now(),
+	}
+	if _, err := i.client.ImageService().Create(ctx, img); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return "", errors.Wrap(err, "failed to create new image")
+	}
+	return image.ID(manifestDesc.Digest), nil
+}
+
+// writeContentsForImage writes the image config and manifest of img into the
+// content store, and returns the descriptor of the manifest.
+func writeContentsForImage(ctx context.Context, snName string, cs content.Store, img *image.Image, layers []ocispec.Descriptor) (ocispec.Descriptor, error) {
+	configJSON, err := json.Marshal(img)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	configDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(configJSON),
+		Size:      int64(len(configJSON)),
+	}
+
+	manifest := struct {
+		MediaType string `json:"mediaType,omitempty"`
+		ocispec.Manifest
+	}{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Manifest: ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			Config:    configDesc,
+			Layers:    layers,
+		},
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "   ")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifestJSON),
+		Size:      int64(len(manifestJSON)),
+	}
+
+	// The manifest references the config and the layers, so that those are
+	// not garbage-collected as long as the manifest exists.
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.config": configDesc.Digest.String(),
+	}
+	for n, l := range layers {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.l.%d", n)] = l.Digest.String()
+	}
+	if err := content.WriteBlob(ctx, cs, manifestDesc.Digest.String(), bytes.NewReader(manifestJSON), manifestDesc, content.WithLabels(labels)); err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to write manifest")
+	}
+
+	// The config references the unpacked snapshot of the image.
+	var configLabels map[string]string
+	if chainID := img.RootFS.ChainID(); chainID != "" {
+		configLabels = map[string]string{
+			fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snName): chainID.String(),
+		}
+	}
+	if err := content.WriteBlob(ctx, cs, configDesc.Digest.String(), bytes.NewReader(configJSON), configDesc, content.WithLabels(configLabels)); err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to write config")
+	}
+	return manifestDesc, nil
+}
+
+// createDiff creates a layer blob in the content store from the changes in
+// the snapshot with the given key, and returns its descriptor and diffID.
+func createDiff(ctx context.Context, key string, sn snapshots.Snapshotter, cs content.Store, comparer diff.Comparer) (ocispec.Descriptor, digest.Digest, error) {
+	desc, err := rootfs.CreateDiff(ctx, key, sn, comparer)
+	if err != nil {
+		return ocispec.Descriptor{}, "", translateError(err)
+	}
+	return diffLayerDescriptor(ctx, cs, desc)
+}
+
+// diffLayerDescriptor returns the descriptor of a layer blob created by a
+// diff.Comparer, and its diffID.
+func diffLayerDescriptor(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (ocispec.Descriptor, digest.Digest, error) {
+	info, err := cs.Info(ctx, desc.Digest)
+	if err != nil {
+		return ocispec.Descriptor{}, "", translateError(err)
+	}
+	diffID, err := digest.Parse(info.Labels["containerd.io/uncompressed"])
+	if err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrapf(err, "invalid uncompressed digest for layer %s", desc.Digest)
+	}
+
+	return ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Digest:    desc.Digest,
+		Size:      info.Size,
+	}, diffID, nil
+}
+
+// applyDiffLayer applies the layer created by createDiff on top of the
+// snapshot of parent, so that the new image does not need to be unpacked
+// before it can be used.
+func applyDiffLayer(ctx context.Context, parent, child *image.RootFS, sn snapshots.Snapshotter, differ diff.Applier, diffDesc ocispec.Descriptor) (retErr error) {
+	var (
+		name = child.ChainID().String()
+		key  = uniquePart() + "-" + name
+	)
+
+	mounts, err := sn.Prepare(ctx, key, parent.ChainID().String())
+	if err != nil {
+		return translateError(err)
+	}
+	defer func() {
+		if retErr != nil {
+			// The snapshot is held by the lease, so it is garbage-collected
+			// if removing it fails.
+			if err := sn.Remove(ctx, key); err != nil {
+				logrus.WithError(err).WithField("key", key).Warn("failed to cleanup aborted commit")
+			}
+		}
+	}()
+
+	if _, err := differ.Apply(ctx, diffDesc, mounts); err != nil {
+		return err
+	}
+
+	if err := sn.Commit(ctx, name, key); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func uniquePart() string {
+	t := time.Now()
+	var b [3]byte
+	// Ignore read failures, just decreases uniqueness
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%d-%s", t.Nanosecond(), base64.URLEncoding.EncodeToString(b[:]))
 }
 
 // CommitBuildStep is used by the builder to create an image for each step in
@@ -20,5 +244,13 @@ func (i *ImageService) CommitImage(c backend.CommitConfig) (image.ID, error) {
 //
 // This is a temporary shim. Should be removed when builder stops using commit.
 func (i *ImageService) CommitBuildStep(c backend.CommitConfig) (image.ID, error) {
-	panic("not implemented")
+	ctr := i.containers.Get(c.ContainerID)
+	if ctr == nil {
+		// TODO: use typed error
+		return "", errors.Errorf("container not found: %s", c.ContainerID)
+	}
+	c.ContainerMountLabel = ctr.MountLabel
+	c.ContainerOS = ctr.OS
+	c.ParentImageID = string(ctr.ImageID)
+	return i.CommitImage(c)
 }
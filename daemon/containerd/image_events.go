@@ -1,13 +1,47 @@
 package containerd
 
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/events"
+)
+
 // LogImageEvent generates an event related to an image with only the
 // default attributes.
 func (i *ImageService) LogImageEvent(imageID, refName, action string) {
-	panic("not implemented")
+	i.LogImageEventWithAttributes(imageID, refName, action, map[string]string{})
 }
 
 // LogImageEventWithAttributes generates an event related to an image with
 // specific given attributes.
 func (i *ImageService) LogImageEventWithAttributes(imageID, refName, action string, attributes map[string]string) {
-	panic("not implemented")
+	ctx := context.TODO()
+	if img, err := i.resolveImage(ctx, imageID); err == nil {
+		if config, err := i.readImageConfig(ctx, img, nil); err == nil {
+			// image has not been removed yet.
+			// it could be missing if the event is `delete`.
+			copyAttributes(attributes, config.Config.Labels)
+		}
+	}
+	if refName != "" {
+		attributes["name"] = refName
+	}
+	actor := events.Actor{
+		ID:         imageID,
+		Attributes: attributes,
+	}
+
+	if i.eventsService != nil {
+		i.eventsService.Log(action, events.ImageEventType, actor)
+	}
+}
+
+// copyAttributes guarantees that labels are not mutated by event triggers.
+func copyAttributes(attributes, labels map[string]string) {
+	if labels == nil {
+		return
+	}
+	for k, v := range labels {
+		attributes[k] = v
+	}
 }
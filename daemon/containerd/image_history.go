@@ -1,9 +1,82 @@
 package containerd
 
-import imagetype "github.com/docker/docker/api/types/image"
+import (
+	"context"
+
+	"github.com/docker/distribution/reference"
+	imagetype "github.com/docker/docker/api/types/image"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/pkg/errors"
+)
 
 // ImageHistory returns a slice of ImageHistory structures for the specified
 // image name by walking the image lineage.
 func (i *ImageService) ImageHistory(name string) ([]*imagetype.HistoryResponseItem, error) {
-	panic("not implemented")
+	ctx := context.TODO()
+	img, err := i.resolveImage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := i.readImageConfig(ctx, img, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sizeFn := i.snapshotSizeFn(ctx)
+	diffIDs := config.RootFS.DiffIDs
+	sizes := make([]int64, 0, len(diffIDs))
+	for n := range diffIDs {
+		size, err := sizeFn(identity.ChainID(diffIDs[:n+1]))
+		if err != nil {
+			return nil, translateError(err)
+		}
+		// The snapshot usage is the size of the layer itself, not including
+		// the size of its parents.
+		sizes = append(sizes, size)
+	}
+
+	var history []*imagetype.HistoryResponseItem
+	for _, h := range config.History {
+		var size int64
+		if !h.EmptyLayer {
+			if len(sizes) == 0 {
+				return nil, errors.New("unable to find the size of the layer")
+			}
+			size = sizes[0]
+			sizes = sizes[1:]
+		}
+
+		var created int64
+		if h.Created != nil {
+			created = h.Created.Unix()
+		}
+
+		history = append([]*imagetype.HistoryResponseItem{{
+			ID:        "<missing>",
+			Comment:   h.Comment,
+			CreatedBy: h.CreatedBy,
+			Created:   created,
+			Size:      size,
+			Tags:      nil,
+		}}, history...)
+	}
+
+	if len(history) != 0 {
+		history[0].ID = img.Target.Digest.String()
+
+		refs, err := i.imageReferences(ctx, img.Target.Digest)
+		if err != nil {
+			return nil, err
+		}
+		var tags []string
+		for _, ref := range refs {
+			if _, ok := ref.(reference.NamedTagged); ok {
+				tags = append(tags, reference.FamiliarString(ref))
+			}
+		}
+		history[0].Tags = tags
+	}
+
+	return history, nil
 }
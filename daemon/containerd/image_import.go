@@ -1,15 +1,208 @@
 package containerd
 
 import (
+	"compress/gzip"
+	"context"
 	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
-	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/labels"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/builder/dockerfile"
+	"github.com/docker/docker/builder/remotecontext"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
 )
 
 // ImportImage imports an image, getting the archived layer data either from
 // inConfig (if src is "-"), or from a URI specified in src. Progress output is
 // written to outStream. Repository and tag names can optionally be given in
 // the repo and tag arguments, respectively.
-func (i *ImageService) ImportImage(src string, repository string, platform *specs.Platform, tag string, msg string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error {
-	panic("not implemented")
+func (i *ImageService) ImportImage(src string, repository string, platform *ocispec.Platform, tag string, msg string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error {
+	var (
+		rc     io.ReadCloser
+		resp   *http.Response
+		newRef reference.Named
+	)
+
+	if repository != "" {
+		var err error
+		newRef, err = reference.ParseNormalizedNamed(repository)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+		if _, isCanonical := newRef.(reference.Canonical); isCanonical {
+			return errdefs.InvalidParameter(errors.New("cannot import digest reference"))
+		}
+
+		if tag != "" {
+			newRef, err = reference.WithTag(newRef, tag)
+			if err != nil {
+				return errdefs.InvalidParameter(err)
+			}
+		}
+	}
+
+	// Normalize platform - default to the operating system and architecture if not supplied.
+	if platform == nil {
+		p := platforms.DefaultSpec()
+		platform = &p
+	}
+	if !system.IsOSSupported(platform.OS) {
+		return errdefs.InvalidParameter(system.ErrNotSupportedOperatingSystem)
+	}
+	config, err := dockerfile.BuildFromConfig(&container.Config{}, changes, platform.OS)
+	if err != nil {
+		return err
+	}
+	if src == "-" {
+		rc = inConfig
+	} else {
+		inConfig.Close()
+		if len(strings.Split(src, "://")) == 1 {
+			src = "http://" + src
+		}
+		u, err := url.Parse(src)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+
+		resp, err = remotecontext.GetWithStatusError(u.String())
+		if err != nil {
+			return err
+		}
+		outStream.Write(streamformatter.FormatStatus("", "Downloading from %s", u))
+		progressOutput := streamformatter.NewJSONProgressOutput(outStream, true)
+		rc = progress.NewProgressReader(resp.Body, progressOutput, resp.ContentLength, "", "Importing")
+	}
+
+	defer rc.Close()
+	if len(msg) == 0 {
+		msg = "Imported from " + src
+	}
+
+	ctx := context.TODO()
+
+	ctx, release, err := i.withLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	inflatedLayerData, err := archive.DecompressStream(rc)
+	if err != nil {
+		return err
+	}
+	defer inflatedLayerData.Close()
+
+	cs := i.client.ContentStore()
+	layerDesc, diffID, err := writeLayerBlob(ctx, cs, inflatedLayerData)
+	if err != nil {
+		return err
+	}
+
+	created := time.Now().UTC()
+	img := &image.Image{
+		V1Image: image.V1Image{
+			DockerVersion: dockerversion.Version,
+			Config:        config,
+			Architecture:  platform.Architecture,
+			Variant:       platform.Variant,
+			OS:            platform.OS,
+			Created:       created,
+			Comment:       msg,
+		},
+		RootFS: &image.RootFS{
+			Type:    "layers",
+			DiffIDs: []layer.DiffID{layer.DiffID(diffID)},
+		},
+		History: []image.History{{
+			Created: created,
+			Comment: msg,
+		}},
+	}
+
+	manifestDesc, err := writeContentsForImage(ctx, i.snapshotter, cs, img, []ocispec.Descriptor{layerDesc})
+	if err != nil {
+		return err
+	}
+
+	ctrdImg := containerdimages.Image{
+		Name:      danglingImageName(manifestDesc.Digest),
+		Target:    manifestDesc,
+		CreatedAt: created,
+	}
+	if _, err := i.client.ImageService().Create(ctx, ctrdImg); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return errors.Wrap(err, "failed to create new image")
+	}
+
+	if err := containerd.NewImageWithPlatform(i.client, ctrdImg, platforms.Only(*platform)).Unpack(ctx, i.snapshotter); err != nil {
+		return errors.Wrap(translateError(err), "failed to unpack image")
+	}
+
+	id := image.ID(manifestDesc.Digest)
+	if newRef != nil {
+		if err := i.TagImageWithReference(id, newRef); err != nil {
+			return err
+		}
+	}
+
+	i.LogImageEvent(id.String(), id.String(), "import")
+	outStream.Write(streamformatter.FormatStatus("", "%s", id.String()))
+	return nil
+}
+
+// writeLayerBlob compresses the uncompressed layer tar read from r, and
+// writes it into the content store. It returns the descriptor of the layer
+// blob, and the digest of the uncompressed layer.
+func writeLayerBlob(ctx context.Context, cs content.Store, r io.Reader) (ocispec.Descriptor, digest.Digest, error) {
+	w, err := content.OpenWriter(ctx, cs, content.WithRef("import-"+uniquePart()))
+	if err != nil {
+		return ocispec.Descriptor{}, "", translateError(err)
+	}
+	defer w.Close()
+
+	diffIDDigester := digest.Canonical.Digester()
+	gz := gzip.NewWriter(w)
+	if _, err := io.Copy(io.MultiWriter(gz, diffIDDigester.Hash()), r); err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to write layer")
+	}
+	if err := gz.Close(); err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to write layer")
+	}
+
+	diffID := diffIDDigester.Digest()
+	dgst := w.Digest()
+	opt := content.WithLabels(map[string]string{labels.LabelUncompressed: diffID.String()})
+	if err := w.Commit(ctx, 0, dgst, opt); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to commit layer")
+	}
+
+	info, err := cs.Info(ctx, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, "", translateError(err)
+	}
+	return ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Digest:    dgst,
+		Size:      info.Size,
+	}, diffID, nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/image"
 	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/identity"
 )
@@ -22,7 +23,6 @@ var acceptedImageFilterTags = map[string]bool{
 // Images returns a filtered list of images.
 //
 // TODO(thaJeztah): sort the results by created (descending); see https://github.com/moby/moby/issues/43848
-// TODO(thaJeztah): implement opts.ContainerCount (used for docker system df); see https://github.com/moby/moby/issues/43853
 // TODO(thaJeztah): add labels to results; see https://github.com/moby/moby/issues/43852
 // TODO(thaJeztah): verify behavior of `RepoDigests` and `RepoTags` for images without (untagged) or multiple tags; see https://github.com/moby/moby/issues/43861
 // TODO(thaJeztah): verify "Size" vs "VirtualSize" in images; see https://github.com/moby/moby/issues/43862
@@ -41,30 +41,29 @@ func (i *ImageService) Images(ctx context.Context, opts types.ImageListOptions)
 		return nil, err
 	}
 
-	snapshotter := i.client.SnapshotService(containerd.DefaultSnapshotter)
-	sizeCache := make(map[digest.Digest]int64)
-	snapshotSizeFn := func(d digest.Digest) (int64, error) {
-		if s, ok := sizeCache[d]; ok {
-			return s, nil
-		}
-		usage, err := snapshotter.Usage(ctx, d.String())
-		if err != nil {
-			return 0, err
-		}
-		sizeCache[d] = usage.Size
-		return usage.Size, nil
-	}
+	snapshotSizeFn := i.snapshotSizeFn(ctx)
 
 	var (
 		summaries = make([]*types.ImageSummary, 0, len(imgs))
-		root      []*[]digest.Digest
+		root      [][]digest.Digest
 		layers    map[digest.Digest]int
+		counted   map[digest.Digest]struct{}
 	)
 	if opts.SharedSize {
-		root = make([]*[]digest.Digest, len(imgs))
+		root = make([][]digest.Digest, 0, len(imgs))
 		layers = make(map[digest.Digest]int)
+		counted = make(map[digest.Digest]struct{})
 	}
-	for n, img := range imgs {
+
+	var containersByImage map[image.ID]int64
+	if opts.ContainerCount {
+		containersByImage = make(map[image.ID]int64)
+		for _, c := range i.containers.List() {
+			containersByImage[c.ImageID]++
+		}
+	}
+
+	for _, img := range imgs {
 		if !filter(img) {
 			continue
 		}
@@ -75,9 +74,14 @@ func (i *ImageService) Images(ctx context.Context, opts types.ImageListOptions)
 		}
 		chainIDs := identity.ChainIDs(diffIDs)
 		if opts.SharedSize {
-			root[n] = &chainIDs
-			for _, id := range chainIDs {
-				layers[id] = layers[id] + 1
+			root = append(root, chainIDs)
+			// Images with multiple names must only be counted once, so
+			// that their layers are not considered as shared.
+			if _, ok := counted[img.Target().Digest]; !ok {
+				counted[img.Target().Digest] = struct{}{}
+				for _, id := range chainIDs {
+					layers[id] = layers[id] + 1
+				}
 			}
 		}
 
@@ -91,12 +95,24 @@ func (i *ImageService) Images(ctx context.Context, opts types.ImageListOptions)
 			return nil, err
 		}
 
+		repoDigests := []string{img.Name() + "@" + img.Target().Digest.String()} // "hello-world@sha256:bfea6278a0a267fad2634554f4f0c6f31981eea41c553fdf5a83e95a41d40c38"
+		repoTags := []string{img.Name()}
+		if isDanglingImage(img.Metadata()) {
+			repoDigests = []string{"<none>@<none>"}
+			repoTags = []string{"<none>:<none>"}
+		}
+
+		containers := int64(-1)
+		if opts.ContainerCount {
+			containers = containersByImage[image.ID(img.Target().Digest)]
+		}
+
 		summaries = append(summaries, &types.ImageSummary{
 			ParentID:    "",
 			ID:          img.Target().Digest.String(),
 			Created:     img.Metadata().CreatedAt.Unix(),
-			RepoDigests: []string{img.Name() + "@" + img.Target().Digest.String()}, // "hello-world@sha256:bfea6278a0a267fad2634554f4f0c6f31981eea41c553fdf5a83e95a41d40c38"},
-			RepoTags:    []string{img.Name()},
+			RepoDigests: repoDigests,
+			RepoTags:    repoTags,
 			Size:        size,
 			VirtualSize: virtualSize,
 			// -1 indicates that the value has not been set (avoids ambiguity
@@ -104,13 +120,13 @@ func (i *ImageService) Images(ctx context.Context, opts types.ImageListOptions)
 			// for this, as the JSON representation uses "omitempty", which would
 			// consider both "0" and "nil" to be "empty".
 			SharedSize: -1,
-			Containers: -1,
+			Containers: containers,
 		})
 	}
 
 	if opts.SharedSize {
 		for n, chainIDs := range root {
-			sharedSize, err := computeSharedSize(*chainIDs, layers, snapshotSizeFn)
+			sharedSize, err := computeSharedSize(chainIDs, layers, snapshotSizeFn)
 			if err != nil {
 				return nil, err
 			}
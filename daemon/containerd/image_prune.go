@@ -2,12 +2,296 @@ package containerd
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
 
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/daemon/internal/prunefilters"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+var imagesAcceptedFilters = map[string]bool{
+	"dangling": true,
+	"label":    true,
+	"label!":   true,
+	"until":    true,
+}
+
+// errPruneRunning is returned when a prune request is received while
+// one is in progress
+var errPruneRunning = errdefs.Conflict(errors.New("a prune operation is already running"))
+
 // ImagesPrune removes unused images
 func (i *ImageService) ImagesPrune(ctx context.Context, pruneFilters filters.Args) (*types.ImagesPruneReport, error) {
-	panic("not implemented")
+	if !atomic.CompareAndSwapInt32(&i.pruneRunning, 0, 1) {
+		return nil, errPruneRunning
+	}
+	defer atomic.StoreInt32(&i.pruneRunning, 0)
+
+	// make sure that only accepted filters have been received
+	err := pruneFilters.Validate(imagesAcceptedFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	danglingOnly := true
+	if pruneFilters.Contains("dangling") {
+		if pruneFilters.ExactMatch("dangling", "false") || pruneFilters.ExactMatch("dangling", "0") {
+			danglingOnly = false
+		} else if !pruneFilters.ExactMatch("dangling", "true") && !pruneFilters.ExactMatch("dangling", "1") {
+			return nil, invalidFilter{"dangling", pruneFilters.Get("dangling")}
+		}
+	}
+
+	until, err := prunefilters.GetUntil(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	is := i.client.ImageService()
+	allImages, err := is.List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// Group the names by image, as an image can have multiple names.
+	names := make(map[digest.Digest][]containerdimages.Image)
+	for _, img := range allImages {
+		names[img.Target.Digest] = append(names[img.Target.Digest], img)
+	}
+
+	usedByContainers := make(map[image.ID]struct{})
+	for _, c := range i.containers.List() {
+		usedByContainers[c.ImageID] = struct{}{}
+	}
+
+	// Read the configs of all images, to find the images which are the
+	// parent of another image.
+	configs := make(map[digest.Digest]*image.Image, len(names))
+	hasChildren := make(map[image.ID]bool)
+	for id, imgs := range names {
+		blob, err := i.readImageConfigBlob(ctx, imgs[0], nil)
+		if err != nil {
+			logrus.WithError(err).WithField("image", id).Debug("failed to read config of image to prune")
+			continue
+		}
+		var img image.Image
+		if err := json.Unmarshal(blob, &img); err != nil {
+			logrus.WithError(err).WithField("image", id).Debug("failed to read config of image to prune")
+			continue
+		}
+		configs[id] = &img
+		if img.Parent != "" {
+			hasChildren[img.Parent] = true
+		}
+	}
+
+	// Filter intermediary images and images in use.
+	topImages := make(map[digest.Digest][]containerdimages.Image)
+	for id, img := range configs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		imgs := names[id]
+		if _, ok := usedByContainers[image.ID(id)]; ok {
+			continue
+		}
+		if danglingOnly && hasTag(imgs) {
+			continue
+		}
+		if isDanglingImage(imgs[0]) && hasChildren[image.ID(id)] {
+			continue
+		}
+		if !until.IsZero() && img.Created.After(until) {
+			continue
+		}
+		if img.Config != nil && !prunefilters.MatchLabels(pruneFilters, img.Config.Labels) {
+			continue
+		}
+		topImages[id] = imgs
+	}
+
+	// Record the resources of the images to delete, to find out which of them
+	// were actually removed by the garbage collector. Resources that are still
+	// referenced by other images, or held by a lease (for example, by a build
+	// or a pull in progress) are not removed.
+	resources, err := i.imageResources(ctx, topImages)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &types.ImagesPruneReport{}
+	canceled := false
+deleteImagesLoop:
+	for id, imgs := range topImages {
+		select {
+		case <-ctx.Done():
+			// we still want to calculate freed size and return the data
+			canceled = true
+			break deleteImagesLoop
+		default:
+		}
+
+		var deletedImages []types.ImageDeleteResponseItem
+		if hasChildren[image.ID(id)] {
+			// The image is the parent of another image, so only its names are
+			// removed, and it's kept as a dangling image.
+			dangling := containerdimages.Image{
+				Name:      danglingImageName(id),
+				Target:    imgs[0].Target,
+				CreatedAt: imgs[0].CreatedAt,
+			}
+			if _, err := is.Create(ctx, dangling); err != nil && !cerrdefs.IsAlreadyExists(err) {
+				logrus.WithError(err).WithField("image", id).Warn("failed to prune image")
+				continue
+			}
+		}
+		for n, img := range imgs {
+			var opts []containerdimages.DeleteOpt
+			if n == len(imgs)-1 {
+				// Run the garbage collector once the last name of the image is
+				// removed, so that the space is reclaimed before the size is
+				// computed.
+				opts = append(opts, containerdimages.SynchronousDelete())
+			}
+			if err := is.Delete(ctx, img.Name, opts...); err != nil {
+				if !cerrdefs.IsNotFound(err) {
+					logrus.WithError(err).WithField("image", img.Name).Warn("failed to prune image")
+				}
+				continue
+			}
+			if !isDanglingImage(img) {
+				deletedImages = append(deletedImages, types.ImageDeleteResponseItem{Untagged: familiarImageName(img.Name)})
+				i.LogImageEvent(id.String(), familiarImageName(img.Name), "untag")
+			}
+		}
+		if !hasChildren[image.ID(id)] {
+			deletedImages = append(deletedImages, types.ImageDeleteResponseItem{Deleted: id.String()})
+			i.LogImageEvent(id.String(), id.String(), "delete")
+		}
+
+		rep.ImagesDeleted = append(rep.ImagesDeleted, deletedImages...)
+	}
+
+	// Compute how much space was freed
+	rep.SpaceReclaimed = i.reclaimedSize(ctx, resources)
+
+	if canceled {
+		logrus.Debugf("ImagesPrune operation cancelled: %#v", *rep)
+	}
+	if i.eventsService != nil {
+		i.eventsService.Log("prune", events.ImageEventType, events.Actor{
+			Attributes: map[string]string{
+				"reclaimed": strconv.FormatUint(rep.SpaceReclaimed, 10),
+			},
+		})
+	}
+	return rep, nil
+}
+
+// imageResources holds the sizes of the content blobs and snapshots used by a
+// set of images.
+type imageResources struct {
+	blobs     map[digest.Digest]int64
+	snapshots map[string]int64
+}
+
+func (i *ImageService) imageResources(ctx context.Context, imgs map[digest.Digest][]containerdimages.Image) (imageResources, error) {
+	var (
+		cs  = i.client.ContentStore()
+		sn  = i.client.SnapshotService(i.snapshotter)
+		res = imageResources{
+			blobs:     make(map[digest.Digest]int64),
+			snapshots: make(map[string]int64),
+		}
+	)
+	for _, names := range imgs {
+		img := names[0]
+		err := containerdimages.Walk(ctx, containerdimages.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			res.blobs[desc.Digest] = desc.Size
+			children, err := containerdimages.Children(ctx, cs, desc)
+			if cerrdefs.IsNotFound(err) {
+				// Blobs for other platforms are usually not fetched.
+				return nil, nil
+			}
+			return children, err
+		}), img.Target)
+		if err != nil {
+			return res, translateError(err)
+		}
+
+		diffIDs, err := img.RootFS(ctx, cs, platforms.Default())
+		if err != nil {
+			continue
+		}
+		for _, chainID := range identity.ChainIDs(diffIDs) {
+			usage, err := sn.Usage(ctx, chainID.String())
+			if err != nil {
+				continue
+			}
+			res.snapshots[chainID.String()] = usage.Size
+		}
+	}
+	return res, nil
+}
+
+// reclaimedSize returns the size of the resources which no longer exist.
+func (i *ImageService) reclaimedSize(ctx context.Context, res imageResources) uint64 {
+	var (
+		cs        = i.client.ContentStore()
+		sn        = i.client.SnapshotService(i.snapshotter)
+		reclaimed uint64
+	)
+	for dgst, size := range res.blobs {
+		if _, err := cs.Info(ctx, dgst); cerrdefs.IsNotFound(err) {
+			reclaimed += uint64(size)
+		}
+	}
+	for key, size := range res.snapshots {
+		if _, err := sn.Stat(ctx, key); cerrdefs.IsNotFound(err) {
+			reclaimed += uint64(size)
+		}
+	}
+	return reclaimed
+}
+
+// hasTag returns whether any of the names of an image is a tag.
+func hasTag(imgs []containerdimages.Image) bool {
+	for _, img := range imgs {
+		if isDanglingImage(img) {
+			continue
+		}
+		ref, err := reference.ParseNormalizedNamed(img.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := ref.(reference.NamedTagged); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// familiarImageName returns the shortened form of the name of an image.
+func familiarImageName(name string) string {
+	ref, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return name
+	}
+	return reference.FamiliarString(ref)
 }
@@ -3,24 +3,32 @@ package containerd
 import (
 	"context"
 	"io"
+	"strings"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/platforms"
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types/registry"
+	dockerdist "github.com/docker/docker/distribution"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // PullImage initiates a pull operation. image is the repository name to pull, and
 // tagOrDigest may be either empty, or indicate a specific tag or digest to pull.
+// If the image is a bare repository name and tagOrDigest is empty, all tags of
+// the repository are pulled.
 func (i *ImageService) PullImage(ctx context.Context, image, tagOrDigest string, platform *specs.Platform, metaHeaders map[string][]string, authConfig *registry.AuthConfig, outStream io.Writer) error {
-	var opts []containerd.RemoteOpt
-	if platform != nil {
-		opts = append(opts, containerd.WithPlatform(platforms.Format(*platform)))
-	}
+	// Special case: "pull -a" may send an image name with a
+	// trailing :. This is ugly, but let's not break API
+	// compatibility.
+	image = strings.TrimSuffix(image, ":")
+
 	ref, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
 		return errdefs.InvalidParameter(err)
@@ -41,14 +49,98 @@ func (i *ImageService) PullImage(ctx context.Context, image, tagOrDigest string,
 		}
 	}
 
-	resolver := newResolverFromAuthConfig(authConfig)
+	out := streamformatter.NewJSONProgressOutput(outStream, false)
+	if !reference.IsNameOnly(ref) {
+		return i.pullTag(ctx, ref, platform, authConfig, out)
+	}
+
+	repo, err := i.GetRepository(ctx, ref, authConfig)
+	if err != nil {
+		return err
+	}
+	tags, err := repo.Tags(ctx).All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		tagRef, err := reference.WithTag(ref, tag)
+		if err != nil {
+			return err
+		}
+		if err := i.pullTag(ctx, tagRef, platform, authConfig, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pullTag pulls the image with the given tag or digest.
+func (i *ImageService) pullTag(ctx context.Context, ref reference.Named, platform *specs.Platform, authConfig *registry.AuthConfig, out progress.Output) error {
+	var opts []containerd.RemoteOpt
+	if platform != nil {
+		opts = append(opts, containerd.WithPlatform(platforms.Format(*platform)))
+	}
+
+	resolver, _ := i.newResolverFromAuthConfig(authConfig)
 	opts = append(opts, containerd.WithResolver(resolver))
 
-	_, err = i.client.Pull(ctx, ref.String(), opts...)
-	return err
+	progress.Messagef(out, "", "%s: Pulling from %s", tagOrDigestString(ref), reference.FamiliarName(ref))
+
+	jobs := newJobs()
+	h := images.HandlerFunc(func(ctx context.Context, desc specs.Descriptor) ([]specs.Descriptor, error) {
+		if desc.MediaType != images.MediaTypeDockerSchema1Manifest {
+			jobs.Add(desc)
+		}
+		return nil, nil
+	})
+	opts = append(opts, containerd.WithImageHandler(h))
+
+	finishProgress := jobs.showProgress(ctx, out, pullProgress{Store: i.client.ContentStore(), ShowExists: true})
+
+	var existing digest.Digest
+	if img, err := i.client.ImageService().Get(ctx, ref.String()); err == nil {
+		existing = img.Target.Digest
+	}
+
+	opts = append(opts, containerd.WithPullUnpack, containerd.WithPullSnapshotter(i.snapshotter))
+	if maxDownloads, _ := i.concurrencyLimits(); maxDownloads > 0 {
+		opts = append(opts, containerd.WithMaxConcurrentDownloads(maxDownloads))
+	}
+
+	img, err := i.client.Pull(ctx, ref.String(), opts...)
+	finishProgress()
+	if err != nil {
+		return translateError(err)
+	}
+
+	progress.Message(out, "", "Digest: "+img.Target().Digest.String())
+	if existing == img.Target().Digest {
+		progress.Message(out, "", "Status: Image is up to date for "+reference.FamiliarString(ref))
+	} else {
+		progress.Message(out, "", "Status: Downloaded newer image for "+reference.FamiliarString(ref))
+	}
+	i.LogImageEvent(reference.FamiliarString(ref), reference.FamiliarName(ref), "pull")
+	return nil
 }
 
 // GetRepository returns a repository from the registry.
 func (i *ImageService) GetRepository(ctx context.Context, ref reference.Named, authConfig *registry.AuthConfig) (distribution.Repository, error) {
-	panic("not implemented")
+	return dockerdist.GetRepository(ctx, ref, &dockerdist.ImagePullConfig{
+		Config: dockerdist.Config{
+			AuthConfig:      authConfig,
+			RegistryService: i.registryService,
+		},
+	})
+}
+
+// tagOrDigestString returns the tag or digest of ref, for use in progress
+// messages.
+func tagOrDigestString(ref reference.Named) string {
+	if tagged, ok := ref.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest().String()
+	}
+	return ""
 }
@@ -2,12 +2,150 @@ package containerd
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/docker/distribution/reference"
+	apitypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/streamformatter"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
 )
 
 // PushImage initiates a push operation on the repository named localName.
 func (i *ImageService) PushImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *registry.AuthConfig, outStream io.Writer) error {
-	panic("not implemented")
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return errdefs.InvalidParameter(err)
+	}
+	if tag != "" {
+		// Push by digest is not supported, so only tags are supported.
+		ref, err = reference.WithTag(ref, tag)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+	}
+
+	imgs, err := i.imagesToPush(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	out := streamformatter.NewJSONProgressOutput(outStream, false)
+	progress.Messagef(out, "", "The push refers to repository [%s]", ref.Name())
+
+	for _, img := range imgs {
+		if err := i.pushImage(ctx, img, authConfig, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imagesToPush returns the images to push for ref. If ref has no tag, all
+// tags of the repository are returned.
+func (i *ImageService) imagesToPush(ctx context.Context, ref reference.Named) ([]containerdimages.Image, error) {
+	is := i.client.ImageService()
+	if _, tagged := ref.(reference.Tagged); tagged {
+		img, err := is.Get(ctx, ref.String())
+		if err != nil {
+			if cerrdefs.IsNotFound(err) {
+				return nil, errdefs.NotFound(errors.Errorf("An image does not exist locally with the tag: %s", reference.FamiliarString(ref)))
+			}
+			return nil, translateError(err)
+		}
+		return []containerdimages.Image{img}, nil
+	}
+
+	imgs, err := is.List(ctx, "name~="+strconv.Quote("^"+regexp.QuoteMeta(ref.Name())+":"))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if len(imgs) == 0 {
+		return nil, errdefs.NotFound(errors.Errorf("An image does not exist locally with the tag: %s", reference.FamiliarName(ref)))
+	}
+	return imgs, nil
+}
+
+func (i *ImageService) pushImage(ctx context.Context, img containerdimages.Image, authConfig *registry.AuthConfig, out progress.Output) error {
+	named, err := reference.ParseNormalizedNamed(img.Name)
+	if err != nil {
+		return err
+	}
+	tagged, ok := named.(reference.Tagged)
+	if !ok {
+		return errdefs.InvalidParameter(errors.Errorf("image %s has no tag", img.Name))
+	}
+
+	// Take a lease, so that content fetched or created during the push is
+	// not garbage-collected while pushing.
+	ctx, release, err := i.client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(context.Background())
+
+	resolver, tracker := i.newResolverFromAuthConfig(authConfig)
+	cs := i.client.ContentStore()
+
+	jobs := newJobs()
+	h := containerdimages.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		children, err := containerdimages.Children(ctx, cs, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range children {
+			if containerdimages.IsLayerType(c.MediaType) {
+				jobs.Add(c)
+			}
+		}
+		return nil, nil
+	})
+
+	opts := []containerd.RemoteOpt{
+		containerd.WithResolver(resolver),
+		containerd.WithImageHandler(h),
+	}
+	if _, maxUploads := i.concurrencyLimits(); maxUploads > 0 {
+		opts = append(opts, containerd.WithMaxConcurrentUploadedLayers(maxUploads))
+	}
+
+	finishProgress := jobs.showProgress(ctx, out, pushProgress{Tracker: tracker})
+	err = i.client.Push(ctx, named.String(), img.Target, opts...)
+	finishProgress()
+	if err != nil {
+		return translateError(err)
+	}
+
+	size, err := manifestSize(ctx, cs, img.Target)
+	if err != nil {
+		return err
+	}
+	progress.Messagef(out, "", "%s: digest: %s size: %d", tagged.Tag(), img.Target.Digest, size)
+	progress.Aux(out, apitypes.PushResult{Tag: tagged.Tag(), Digest: img.Target.Digest.String(), Size: size})
+
+	i.LogImageEvent(reference.FamiliarString(named), reference.FamiliarName(named), "push")
+	return nil
+}
+
+// manifestSize returns the size of the manifest (or index) described by desc.
+func manifestSize(ctx context.Context, cs content.Provider, desc ocispec.Descriptor) (int, error) {
+	if desc.Size > 0 {
+		return int(desc.Size), nil
+	}
+	ra, err := cs.ReaderAt(ctx, desc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read manifest %s: %w", desc.Digest, err)
+	}
+	defer ra.Close()
+	return int(ra.Size()), nil
 }
@@ -1,5 +1,21 @@
 package containerd
 
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/snapshots"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
 // SquashImage creates a new image with the diff of the specified image and
 // the specified parent. This new image contains only the layers from its
 // parent + 1 extra layer which contains the diff of all the layers in between.
@@ -7,5 +23,126 @@ package containerd
 // image with the diff of all the specified image's layers merged into a new
 // layer that has no parents.
 func (i *ImageService) SquashImage(id, parent string) (string, error) {
-	panic("not implemented")
+	ctx := context.TODO()
+
+	ctx, release, err := i.withLease(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	img, err := i.GetImage(id, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		cs     = i.client.ContentStore()
+		sn     = i.client.SnapshotService(i.snapshotter)
+		differ = i.client.DiffService()
+	)
+
+	var (
+		parentImg    *image.Image
+		parentLayers []ocispec.Descriptor
+		lower        []mount.Mount
+	)
+	if len(parent) != 0 {
+		parentImg, err = i.GetImage(parent, nil)
+		if err != nil {
+			return "", errors.Wrap(err, "error getting specified parent layer")
+		}
+		ctrdParent, err := i.resolveImage(ctx, parent)
+		if err != nil {
+			return "", errors.Wrap(err, "error getting specified parent layer")
+		}
+		parentManifest, err := containerdimages.Manifest(ctx, cs, ctrdParent.Target, platformMatcher(nil))
+		if err != nil {
+			return "", errors.Wrap(translateError(err), "error getting specified parent layer")
+		}
+		parentLayers = parentManifest.Layers
+
+		if chainID := parentImg.RootFS.ChainID(); chainID != "" {
+			key := uniquePart() + "-squash-parent"
+			lower, err = sn.View(ctx, key, chainID.String())
+			if err != nil {
+				return "", errors.Wrap(translateError(err), "error getting specified parent layer")
+			}
+			defer removeSnapshot(ctx, sn, key)
+		}
+	} else {
+		rootFS := image.NewRootFS()
+		parentImg = &image.Image{RootFS: rootFS}
+	}
+
+	key := uniquePart() + "-squash"
+	upper, err := sn.View(ctx, key, img.RootFS.ChainID().String())
+	if err != nil {
+		return "", errors.Wrap(translateError(err), "error getting image layer")
+	}
+	defer removeSnapshot(ctx, sn, key)
+
+	desc, err := differ.Compare(ctx, lower, upper)
+	if err != nil {
+		return "", errors.Wrapf(translateError(err), "error getting diff to parent")
+	}
+	layerDesc, diffID, err := diffLayerDescriptor(ctx, cs, desc)
+	if err != nil {
+		return "", errors.Wrap(err, "error registering layer")
+	}
+
+	newImage := *img
+	newImage.Details = nil
+	newImage.RootFS = nil
+
+	rootFS := *parentImg.RootFS
+	rootFS.DiffIDs = append(rootFS.DiffIDs, layer.DiffID(diffID))
+	newImage.RootFS = &rootFS
+
+	newImage.History = append([]image.History(nil), img.History...)
+	for i, hi := range newImage.History {
+		if i >= len(parentImg.History) {
+			hi.EmptyLayer = true
+		}
+		newImage.History[i] = hi
+	}
+
+	now := time.Now()
+	var historyComment string
+	if len(parent) > 0 {
+		historyComment = fmt.Sprintf("merge %s to %s", id, parent)
+	} else {
+		historyComment = fmt.Sprintf("create new from %s", id)
+	}
+
+	newImage.History = append(newImage.History, image.History{
+		Created: now,
+		Comment: historyComment,
+	})
+	newImage.Created = now
+
+	if err := applyDiffLayer(ctx, parentImg.RootFS, newImage.RootFS, sn, differ, layerDesc); err != nil {
+		return "", errors.Wrap(err, "error registering layer")
+	}
+
+	manifestDesc, err := writeContentsForImage(ctx, i.snapshotter, cs, &newImage, append(parentLayers, layerDesc))
+	if err != nil {
+		return "", errors.Wrap(err, "error creating new image after squash")
+	}
+
+	ctrdImg := containerdimages.Image{
+		Name:      danglingImageName(manifestDesc.Digest),
+		Target:    manifestDesc,
+		CreatedAt: now,
+	}
+	if _, err := i.client.ImageService().Create(ctx, ctrdImg); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return "", errors.Wrap(err, "error creating new image after squash")
+	}
+	return manifestDesc.Digest.String(), nil
+}
+
+func removeSnapshot(ctx context.Context, sn snapshots.Snapshotter, key string) {
+	if err := sn.Remove(ctx, key); err != nil && !cerrdefs.IsNotFound(err) {
+		logrus.WithError(err).WithField("key", key).Warn("failed to remove snapshot")
+	}
 }
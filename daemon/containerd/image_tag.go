@@ -1,17 +1,70 @@
 package containerd
 
 import (
+	"context"
+
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	containerdimages "github.com/containerd/containerd/images"
 	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // TagImage creates the tag specified by newTag, pointing to the image named
 // imageName (alternatively, imageName can also be an image ID).
 func (i *ImageService) TagImage(imageName, repository, tag string) (string, error) {
-	panic("not implemented")
+	img, err := i.resolveImage(context.TODO(), imageName)
+	if err != nil {
+		return "", err
+	}
+
+	newTag, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return "", err
+	}
+	if tag != "" {
+		if newTag, err = reference.WithTag(reference.TrimNamed(newTag), tag); err != nil {
+			return "", err
+		}
+	}
+
+	err = i.TagImageWithReference(image.ID(img.Target.Digest), newTag)
+	return reference.FamiliarString(newTag), err
 }
 
 // TagImageWithReference adds the given reference to the image ID provided.
 func (i *ImageService) TagImageWithReference(imageID image.ID, newTag reference.Named) error {
-	panic("not implemented")
+	ctx := context.TODO()
+	target, err := i.resolveImage(ctx, imageID.String())
+	if err != nil {
+		return err
+	}
+
+	newTag = reference.TagNameOnly(newTag)
+	newImg := containerdimages.Image{
+		Name:   newTag.String(),
+		Target: target.Target,
+		Labels: target.Labels,
+	}
+
+	is := i.client.ImageService()
+	if _, err := is.Create(ctx, newImg); err != nil {
+		if !cerrdefs.IsAlreadyExists(err) {
+			return errdefs.System(errors.Wrapf(err, "failed to create image with name %s and target %s", newImg.Name, newImg.Target.Digest))
+		}
+		// A tag with this name already exists; point it to the new target.
+		if _, err := is.Update(ctx, newImg, "target", "labels"); err != nil {
+			return translateError(err)
+		}
+	}
+
+	// The image has a name now, so it's no longer dangling.
+	if err := is.Delete(ctx, danglingImageName(newImg.Target.Digest)); err != nil && !cerrdefs.IsNotFound(err) {
+		logrus.WithError(err).WithField("image", newImg.Target.Digest).Warn("failed to remove dangling image")
+	}
+
+	i.LogImageEvent(imageID.String(), reference.FamiliarString(newTag), "tag")
+	return nil
 }
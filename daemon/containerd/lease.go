@@ -0,0 +1,29 @@
+package containerd
+
+import (
+	"context"
+	"time"
+
+	"github.com/containerd/containerd/leases"
+	"github.com/sirupsen/logrus"
+)
+
+// leaseExpiration is the expiration of the temporary leases held while
+// creating images, after which the content and snapshots they hold are
+// garbage-collected if the daemon crashed before releasing them.
+const leaseExpiration = 1 * time.Hour
+
+// withLease takes a temporary lease, so that the content and snapshots created
+// with the returned context are not garbage-collected before the image they
+// belong to is created. The returned function releases the lease.
+func (i *ImageService) withLease(ctx context.Context) (context.Context, func(), error) {
+	ctx, release, err := i.client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(leaseExpiration))
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, func() {
+		if err := release(context.Background()); err != nil {
+			logrus.WithError(err).Warn("failed to release lease")
+		}
+	}, nil
+}
@@ -8,28 +8,59 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func newResolverFromAuthConfig(authConfig *registrytypes.AuthConfig) remotes.Resolver {
-	opts := []docker.RegistryOpt{}
+// newResolverFromAuthConfig creates a resolver for the registry hosts
+// configured on the daemon (mirrors, insecure registries, certificates),
+// using the given authConfig to authenticate. The returned StatusTracker
+// tracks the uploads done by pushers created from the resolver.
+func (i *ImageService) newResolverFromAuthConfig(authConfig *registrytypes.AuthConfig) (remotes.Resolver, docker.StatusTracker) {
+	tracker := docker.NewInMemoryTracker()
+	return docker.NewResolver(docker.ResolverOptions{
+		Hosts:   i.hostsWithAuthorizer(authConfig),
+		Tracker: tracker,
+	}), tracker
+}
+
+// hostsWithAuthorizer wraps the daemon's registry hosts configuration to add
+// an authorizer using the credentials from authConfig to hosts that do not
+// have an authorizer configured.
+func (i *ImageService) hostsWithAuthorizer(authConfig *registrytypes.AuthConfig) docker.RegistryHosts {
+	hostsFn := docker.ConfigureDefaultRegistries()
+	if i.registryHosts != nil {
+		hostsFn = i.registryHosts.RegistryHosts()
+	}
+
+	var authorizer docker.Authorizer
 	if authConfig != nil {
-		cfgHost := registry.ConvertToHostname(authConfig.ServerAddress)
-		if cfgHost == registry.IndexHostname {
-			cfgHost = registry.DefaultRegistryHost
+		authorizer = newAuthorizerFromAuthConfig(authConfig)
+	}
+
+	return func(host string) ([]docker.RegistryHost, error) {
+		hosts, err := hostsFn(host)
+		if err != nil {
+			return nil, err
 		}
-		authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(func(host string) (string, string, error) {
-			if cfgHost != host {
-				logrus.WithField("host", host).WithField("cfgHost", cfgHost).Warn("Host doesn't match")
-				return "", "", nil
-			}
-			if authConfig.IdentityToken != "" {
-				return "", authConfig.IdentityToken, nil
+		for n := range hosts {
+			if hosts[n].Authorizer == nil {
+				hosts[n].Authorizer = authorizer
 			}
-			return authConfig.Username, authConfig.Password, nil
-		}))
-
-		opts = append(opts, docker.WithAuthorizer(authorizer))
+		}
+		return hosts, nil
 	}
+}
 
-	return docker.NewResolver(docker.ResolverOptions{
-		Hosts: docker.ConfigureDefaultRegistries(opts...),
-	})
+func newAuthorizerFromAuthConfig(authConfig *registrytypes.AuthConfig) docker.Authorizer {
+	cfgHost := registry.ConvertToHostname(authConfig.ServerAddress)
+	if cfgHost == "" || cfgHost == registry.IndexHostname {
+		cfgHost = registry.DefaultRegistryHost
+	}
+	return docker.NewDockerAuthorizer(docker.WithAuthCreds(func(host string) (string, string, error) {
+		if cfgHost != host {
+			logrus.WithField("host", host).WithField("cfgHost", cfgHost).Warn("Host doesn't match")
+			return "", "", nil
+		}
+		if authConfig.IdentityToken != "" {
+			return "", authConfig.IdentityToken, nil
+		}
+		return authConfig.Username, authConfig.Password, nil
+	}))
 }
@@ -2,24 +2,80 @@ package containerd
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/containerd/containerd"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes/docker"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/container"
+	daemonevents "github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/images"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 )
 
+// RegistryHostsProvider provides the registry hosts configuration of the
+// daemon, which may change when the daemon configuration is reloaded.
+type RegistryHostsProvider interface {
+	RegistryHosts() docker.RegistryHosts
+}
+
 // ImageService implements daemon.ImageService
 type ImageService struct {
-	client *containerd.Client
+	client          *containerd.Client
+	containers      container.Store
+	snapshotter     string
+	registryHosts   RegistryHostsProvider
+	registryService registry.Service
+	eventsService   *daemonevents.Events
+
+	// mu protects maxConcurrentDownloads and maxConcurrentUploads, which
+	// are updated when the daemon configuration is reloaded.
+	mu                     sync.Mutex
+	maxConcurrentDownloads int
+	maxConcurrentUploads   int
+
+	pruneRunning int32
+	usage        singleflight.Group
+}
+
+// ImageServiceConfig is the configuration used to create a new ImageService
+type ImageServiceConfig struct {
+	Client                 *containerd.Client
+	Containers             container.Store
+	Snapshotter            string
+	RegistryHosts          RegistryHostsProvider
+	RegistryService        registry.Service
+	EventsService          *daemonevents.Events
+	MaxConcurrentDownloads int
+	MaxConcurrentUploads   int
 }
 
 // NewService creates a new ImageService.
-func NewService(c *containerd.Client) *ImageService {
+func NewService(config ImageServiceConfig) *ImageService {
+	snapshotter := config.Snapshotter
+	if snapshotter == "" {
+		snapshotter = containerd.DefaultSnapshotter
+	}
 	return &ImageService{
-		client: c,
+		client:                 config.Client,
+		containers:             config.Containers,
+		snapshotter:            snapshotter,
+		registryHosts:          config.RegistryHosts,
+		registryService:        config.RegistryService,
+		eventsService:          config.EventsService,
+		maxConcurrentDownloads: config.MaxConcurrentDownloads,
+		maxConcurrentUploads:   config.MaxConcurrentUploads,
 	}
 }
 
@@ -31,19 +87,34 @@ func (i *ImageService) DistributionServices() images.DistributionServices {
 // CountImages returns the number of images stored by ImageService
 // called from info.go
 func (i *ImageService) CountImages() int {
-	imgs, err := i.client.ListImages(context.TODO())
+	imgs, err := i.client.ImageService().List(context.TODO())
 	if err != nil {
 		return 0
 	}
 
-	return len(imgs)
+	// Multiple names can refer to the same image.
+	ids := make(map[digest.Digest]struct{}, len(imgs))
+	for _, img := range imgs {
+		ids[img.Target.Digest] = struct{}{}
+	}
+	return len(ids)
 }
 
 // Children returns the children image.IDs for a parent image.
 // called from list.go to filter containers
 // TODO: refactor to expose an ancestry for image.ID?
 func (i *ImageService) Children(id image.ID) []image.ID {
-	panic("not implemented")
+	children, err := i.childrenIndex(context.TODO())
+	if err != nil {
+		logrus.WithError(err).WithField("image", id).Warn("failed to get children of image")
+		return nil
+	}
+
+	ids := make([]image.ID, 0, len(children[id]))
+	for _, child := range children[id] {
+		ids = append(ids, child.ID())
+	}
+	return ids
 }
 
 // CreateLayer creates a filesystem layer for a container.
@@ -53,6 +124,12 @@ func (i *ImageService) CreateLayer(container *container.Container, initFunc laye
 	panic("not implemented")
 }
 
+// CreateImageMountLayer creates a layer with the given name on top of the
+// layers of the image, to mount the filesystem of the image into containers.
+func (i *ImageService) CreateImageMountLayer(imgID image.ID, name, mountLabel string) (layer.RWLayer, error) {
+	return nil, errdefs.NotImplemented(errors.New("image mounts are not supported with the containerd image store"))
+}
+
 // GetLayerByID returns a layer by ID
 // called from daemon.go Daemon.restore(), and Daemon.containerExport().
 func (i *ImageService) GetLayerByID(cid string) (layer.RWLayer, error) {
@@ -81,7 +158,7 @@ func (i *ImageService) Cleanup() error {
 // StorageDriver returns the name of the default storage-driver (snapshotter)
 // used by the ImageService.
 func (i *ImageService) StorageDriver() string {
-	return ""
+	return i.snapshotter
 }
 
 // ReleaseLayer releases a layer allowing it to be removed
@@ -93,19 +170,101 @@ func (i *ImageService) ReleaseLayer(rwlayer layer.RWLayer) error {
 // LayerDiskUsage returns the number of bytes used by layer stores
 // called from disk_usage.go
 func (i *ImageService) LayerDiskUsage(ctx context.Context) (int64, error) {
-	panic("not implemented")
+	ch := i.usage.DoChan("LayerDiskUsage", func() (interface{}, error) {
+		imgs, err := i.client.ListImages(ctx)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		// Count the snapshots shared by multiple images only once.
+		chainIDs := make(map[digest.Digest]struct{})
+		for _, img := range imgs {
+			diffIDs, err := img.RootFS(ctx)
+			if err != nil {
+				// The image may not be available for the current platform.
+				continue
+			}
+			for _, chainID := range identity.ChainIDs(diffIDs) {
+				chainIDs[chainID] = struct{}{}
+			}
+		}
+
+		var allLayersSize int64
+		sizeFn := i.snapshotSizeFn(ctx)
+		for chainID := range chainIDs {
+			select {
+			case <-ctx.Done():
+				return allLayersSize, ctx.Err()
+			default:
+			}
+			size, err := sizeFn(chainID)
+			if err != nil {
+				if cerrdefs.IsNotFound(err) {
+					// The image is not unpacked.
+					continue
+				}
+				return nil, translateError(err)
+			}
+			allLayersSize += size
+		}
+		return allLayersSize, nil
+	})
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return 0, res.Err
+		}
+		return res.Val.(int64), nil
+	}
 }
 
 // ImageDiskUsage returns information about image data disk usage.
 func (i *ImageService) ImageDiskUsage(ctx context.Context) ([]*types.ImageSummary, error) {
-	panic("not implemented")
+	ch := i.usage.DoChan("ImageDiskUsage", func() (interface{}, error) {
+		// Get all top images with extra attributes
+		images, err := i.Images(ctx, types.ImageListOptions{
+			Filters:        filters.NewArgs(),
+			SharedSize:     true,
+			ContainerCount: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve image list: %v", err)
+		}
+		return images, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]*types.ImageSummary), nil
+	}
 }
 
 // UpdateConfig values
 //
 // called from reload.go
 func (i *ImageService) UpdateConfig(maxDownloads, maxUploads int) {
-	panic("not implemented")
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if maxDownloads != 0 {
+		i.maxConcurrentDownloads = maxDownloads
+	}
+	if maxUploads != 0 {
+		i.maxConcurrentUploads = maxUploads
+	}
+}
+
+// concurrencyLimits returns the maximum number of concurrent downloads and
+// uploads per pull and push.
+func (i *ImageService) concurrencyLimits() (maxDownloads, maxUploads int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.maxConcurrentDownloads, i.maxConcurrentUploads
 }
 
 // GetLayerFolders returns the layer folders from an image RootFS.
@@ -115,5 +274,35 @@ func (i *ImageService) GetLayerFolders(img *image.Image, rwLayer layer.RWLayer)
 
 // GetContainerLayerSize returns the real size & virtual size of the container.
 func (i *ImageService) GetContainerLayerSize(containerID string) (int64, int64) {
-	panic("not implemented")
+	ctx := context.TODO()
+	sn := i.client.SnapshotService(i.snapshotter)
+
+	// The snapshot of the container is keyed by the container ID.
+	usage, err := sn.Usage(ctx, containerID)
+	if err != nil {
+		logrus.Errorf("Failed to compute size of container rootfs %v: %v", containerID, err)
+		return 0, 0
+	}
+	sizeRw := usage.Size
+	sizeRootfs := sizeRw
+
+	info, err := sn.Stat(ctx, containerID)
+	if err != nil {
+		logrus.Errorf("Failed to compute size of container rootfs %v: %v", containerID, err)
+		return sizeRw, 0
+	}
+	for parent := info.Parent; parent != ""; parent = info.Parent {
+		usage, err := sn.Usage(ctx, parent)
+		if err != nil {
+			logrus.Errorf("Snapshotter %s couldn't return size of layer %s of container %s: %s", i.snapshotter, parent, containerID, err)
+			return sizeRw, 0
+		}
+		sizeRootfs += usage.Size
+
+		if info, err = sn.Stat(ctx, parent); err != nil {
+			logrus.Errorf("Failed to compute size of container rootfs %v: %v", containerID, err)
+			return sizeRw, 0
+		}
+	}
+	return sizeRw, sizeRootfs
 }
@@ -79,6 +79,7 @@ type Daemon struct {
 	imageService          ImageService
 	configStore           *config.Config
 	statsCollector        *stats.Collector
+	containerMetrics      *stats.Metrics
 	defaultLogConfig      containertypes.LogConfig
 	registryService       registry.Service
 	EventsService         *events.Events
@@ -390,11 +391,16 @@ func (daemon *Daemon) restore() error {
 					c.Unlock()
 				}
 
+				if alive {
+					daemon.restoreVolumeMounts(c)
+				}
+
 				if !alive {
 					logger(c).Debug("setting stopped state")
 					c.Lock()
 					c.SetStopped(&container.ExitStatus{ExitCode: int(ec), ExitedAt: exitedAt})
 					daemon.Cleanup(c)
+					daemon.setStateCounter(c)
 					if err := c.CheckpointTo(daemon.containersReplica); err != nil {
 						log.WithError(err).Error("failed to update stopped container state")
 					}
@@ -496,6 +502,13 @@ func (daemon *Daemon) restore() error {
 	group.Wait()
 
 	for c, notifier := range restartContainers {
+		if len(c.HostConfig.DependsOn) > 0 {
+			// Waiting for the dependencies could take as long as they take
+			// to become healthy, so the container is started in the
+			// background once the daemon is up.
+			go daemon.restartWithDependencies(c, notifier, restartContainers)
+			continue
+		}
 		group.Add(1)
 		go func(c *container.Container, chNotify chan struct{}) {
 			_ = sem.Acquire(context.Background(), 1)
@@ -519,7 +532,7 @@ func (daemon *Daemon) restore() error {
 				}
 			}
 
-			if err := daemon.containerStart(c, "", "", true); err != nil {
+			if err := daemon.containerStart(context.Background(), c, "", "", true); err != nil {
 				log.WithError(err).Error("failed to start container")
 			}
 			close(chNotify)
@@ -610,7 +623,7 @@ func (daemon *Daemon) RestartSwarmContainers() {
 						return
 					}
 
-					if err := daemon.containerStart(c, "", "", true); err != nil {
+					if err := daemon.containerStart(context.Background(), c, "", "", true); err != nil {
 						logrus.WithField("container", c.ID).WithError(err).Error("failed to start swarm container")
 					}
 
@@ -959,7 +972,7 @@ func NewDaemon(ctx context.Context, config *config.Config, pluginStore *plugin.S
 
 	imageRoot := filepath.Join(config.Root, "image", layerStore.DriverName())
 
-	d.volumes, err = volumesservice.NewVolumeService(config.Root, d.PluginStore, rootIDs, d)
+	d.volumes, err = volumesservice.NewVolumeService(config.Root, d.PluginStore, idMapping, d)
 	if err != nil {
 		return nil, err
 	}
@@ -1012,15 +1025,36 @@ func NewDaemon(ctx context.Context, config *config.Config, pluginStore *plugin.S
 	}
 	d.execCommands = exec.NewStore()
 	d.statsCollector = d.newStatsCollector(1 * time.Second)
+	if config.ContainerMetrics {
+		if err := d.registerContainerMetrics(); err != nil {
+			return nil, err
+		}
+	}
 
 	d.EventsService = events.New()
+	if config.Events.Journal {
+		journal, err := newEventsJournal(config)
+		if err != nil {
+			return nil, err
+		}
+		d.EventsService.SetJournal(journal)
+	}
 	d.root = config.Root
 	d.idMapping = idMapping
 
 	d.linkIndex = newLinkIndex()
 
 	if d.UsesSnapshotter() {
-		d.imageService = ctrd.NewService(d.containerdCli)
+		d.imageService = ctrd.NewService(ctrd.ImageServiceConfig{
+			Client:                 d.containerdCli,
+			Containers:             d.containers,
+			Snapshotter:            containerd.DefaultSnapshotter,
+			RegistryHosts:          d,
+			RegistryService:        registryService,
+			EventsService:          d.EventsService,
+			MaxConcurrentDownloads: config.MaxConcurrentDownloads,
+			MaxConcurrentUploads:   config.MaxConcurrentUploads,
+		})
 	} else {
 		ifs, err := image.NewFSStoreBackend(filepath.Join(imageRoot, "imagedb"))
 		if err != nil {
@@ -1249,6 +1283,12 @@ func (daemon *Daemon) Shutdown() error {
 		daemon.mdDB.Close()
 	}
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.WithError(err).Error("Error closing events journal")
+		}
+	}
+
 	return daemon.cleanupMounts()
 }
 
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/container"
@@ -113,7 +114,7 @@ func initDaemonWithVolumeStore(tmp string) (*Daemon, error) {
 		repository: tmp,
 		root:       tmp,
 	}
-	daemon.volumes, err = volumesservice.NewVolumeService(tmp, nil, idtools.Identity{UID: 0, GID: 0}, daemon)
+	daemon.volumes, err = volumesservice.NewVolumeService(tmp, nil, idtools.IdentityMapping{}, daemon)
 	if err != nil {
 		return nil, err
 	}
@@ -304,6 +305,21 @@ func TestValidateContainerIsolation(t *testing.T) {
 	assert.Check(t, is.Error(err, "invalid isolation 'invalid' on "+runtime.GOOS))
 }
 
+func TestValidateRestartPolicyDelays(t *testing.T) {
+	policy := containertypes.RestartPolicy{Name: "always", InitialDelay: 30 * time.Second}
+	assert.Check(t, validateRestartPolicy(policy))
+
+	// The initial delay can't exceed the default maximum delay.
+	policy.InitialDelay = 2 * time.Minute
+	assert.Check(t, is.ErrorContains(validateRestartPolicy(policy), "initial restart delay cannot be greater than the maximum restart delay (1m0s)"))
+
+	policy.MaxDelay = 5 * time.Minute
+	assert.Check(t, validateRestartPolicy(policy))
+
+	policy.MaxDelay = time.Minute
+	assert.Check(t, is.ErrorContains(validateRestartPolicy(policy), "initial restart delay cannot be greater than the maximum restart delay"))
+}
+
 func TestFindNetworkErrorType(t *testing.T) {
 	d := Daemon{}
 	_, err := d.FindNetwork("fakeNet")
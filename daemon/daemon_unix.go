@@ -927,6 +927,8 @@ func driverOptions(config *config.Config) nwconfig.Option {
 			"EnableIP6Tables":     config.BridgeConfig.EnableIP6Tables,
 			"EnableUserlandProxy": config.BridgeConfig.EnableUserlandProxy,
 			"UserlandProxyPath":   config.BridgeConfig.UserlandProxyPath,
+			"UserlandProxyMode":   config.BridgeConfig.UserlandProxyMode,
+			"FirewallBackend":     config.BridgeConfig.FirewallBackend,
 		},
 	})
 }
@@ -0,0 +1,219 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dependencyPollInterval is the interval at which the state of a
+	// dependency is checked while waiting for it.
+	dependencyPollInterval = 250 * time.Millisecond
+
+	// dependencyHealthyTimeout is the maximum time to wait for a dependency
+	// to become healthy.
+	dependencyHealthyTimeout = 10 * time.Minute
+)
+
+// dependency is a container which must meet a condition before the container
+// depending on it is started.
+type dependency struct {
+	container *container.Container
+	condition containertypes.DependencyCondition
+}
+
+// validateDependencies validates the dependencies of a container.
+func validateDependencies(deps []containertypes.Dependency) error {
+	for _, dep := range deps {
+		if dep.Container == "" {
+			return errors.New("dependency must specify a container")
+		}
+		switch dep.Condition {
+		case "", containertypes.DependencyConditionStarted, containertypes.DependencyConditionHealthy:
+		default:
+			return errors.Errorf("invalid condition '%s' for dependency %s", dep.Condition, dep.Container)
+		}
+	}
+	return nil
+}
+
+// dependencies resolves the dependencies of a container.
+func (daemon *Daemon) dependencies(deps []containertypes.Dependency) ([]dependency, error) {
+	resolved := make([]dependency, 0, len(deps))
+	for _, dep := range deps {
+		c, err := daemon.GetContainer(dep.Container)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get dependency %s", dep.Container)
+		}
+		condition := dep.Condition
+		if condition == "" {
+			condition = containertypes.DependencyConditionStarted
+		}
+		resolved = append(resolved, dependency{container: c, condition: condition})
+	}
+	return resolved, nil
+}
+
+// checkDependencyCycle returns an error if the dependencies of the container
+// with the given name, or any of their own dependencies, depend on that
+// container. id is the ID of the container, and is empty when the container is
+// being created.
+func (daemon *Daemon) checkDependencyCycle(name, id string, deps []containertypes.Dependency) error {
+	name = strings.TrimPrefix(name, "/")
+	visited := make(map[string]bool)
+
+	var visit func(path []string, deps []containertypes.Dependency) error
+	visit = func(path []string, deps []containertypes.Dependency) error {
+		resolved, err := daemon.dependencies(deps)
+		if err != nil {
+			return err
+		}
+		for _, dep := range resolved {
+			c := dep.container
+			depName := strings.TrimPrefix(c.Name, "/")
+			if c.ID == id || (name != "" && depName == name) {
+				return errors.Errorf("dependency cycle: %s", strings.Join(append(path, depName), " -> "))
+			}
+			if visited[c.ID] {
+				continue
+			}
+			visited[c.ID] = true
+			if err := visit(append(path, depName), c.HostConfig.DependsOn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit([]string{name}, deps)
+}
+
+// startDependencies starts the dependencies of a container which are not
+// running, and waits for all of them to meet their condition.
+func (daemon *Daemon) startDependencies(ctx context.Context, ctr *container.Container) error {
+	if len(ctr.HostConfig.DependsOn) == 0 {
+		return nil
+	}
+	if err := daemon.checkDependencyCycle(ctr.Name, ctr.ID, ctr.HostConfig.DependsOn); err != nil {
+		return errdefs.InvalidParameter(err)
+	}
+	deps, err := daemon.dependencies(ctr.HostConfig.DependsOn)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deps {
+		if !dep.container.IsRunning() {
+			// The dependencies of the dependency are started as well.
+			if err := daemon.ContainerStart(ctx, dep.container.ID, nil, "", ""); err != nil && !errdefs.IsNotModified(err) {
+				return errors.Wrapf(err, "failed to start dependency %s", strings.TrimPrefix(dep.container.Name, "/"))
+			}
+		}
+		if err := daemon.waitForDependency(ctx, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForDependency waits for a dependency to meet its condition. It returns
+// an error if the dependency stops, or becomes unhealthy.
+func (daemon *Daemon) waitForDependency(ctx context.Context, dep dependency) error {
+	c := dep.container
+	name := strings.TrimPrefix(c.Name, "/")
+
+	if dep.condition == containertypes.DependencyConditionHealthy {
+		if c.Config.Healthcheck == nil || getProbe(c) == nil {
+			return errdefs.InvalidParameter(errors.Errorf("dependency %s has no healthcheck", name))
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dependencyHealthyTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(dependencyPollInterval)
+	defer ticker.Stop()
+	for {
+		c.Lock()
+		running, restarting, health := c.Running, c.Restarting, c.State.Health
+		c.Unlock()
+
+		if !running {
+			return errdefs.Conflict(errors.Errorf("dependency %s is not running", name))
+		}
+		if daemon.IsShuttingDown() {
+			return errors.Errorf("daemon is shutting down while waiting for dependency %s", name)
+		}
+		if !restarting {
+			if dep.condition != containertypes.DependencyConditionHealthy {
+				return nil
+			}
+			if health != nil {
+				switch health.Status() {
+				case types.Healthy:
+					return nil
+				case types.Unhealthy:
+					return errdefs.Conflict(errors.Errorf("dependency %s is unhealthy", name))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "failed to wait for dependency %s", name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// restartWithDependencies starts a container which is restarted when the
+// daemon starts, once the daemon is up and the dependencies of the container
+// met their condition. Failures of the dependencies are logged, and do not
+// prevent the container from being started. chNotify is closed once the
+// container was started, or failed to start.
+func (daemon *Daemon) restartWithDependencies(c *container.Container, chNotify chan struct{}, restartContainers map[*container.Container]chan struct{}) {
+	defer close(chNotify)
+
+	daemon.waitForStartupDone()
+	daemon.waitForRestoredDependencies(c, restartContainers)
+	if daemon.IsShuttingDown() {
+		return
+	}
+	log := logrus.WithField("container", c.ID)
+	log.Debug("starting container")
+	if err := daemon.containerStart(context.Background(), c, "", "", true); err != nil {
+		log.WithError(err).Error("failed to start container")
+	}
+}
+
+// waitForRestoredDependencies waits for the dependencies of a container which
+// is restarted when the daemon starts to meet their condition.
+func (daemon *Daemon) waitForRestoredDependencies(c *container.Container, restartContainers map[*container.Container]chan struct{}) {
+	log := logrus.WithField("container", c.ID)
+	if err := daemon.checkDependencyCycle(c.Name, c.ID, c.HostConfig.DependsOn); err != nil {
+		log.WithError(err).Error("not waiting for dependencies of container")
+		return
+	}
+	deps, err := daemon.dependencies(c.HostConfig.DependsOn)
+	if err != nil {
+		log.WithError(err).Error("not waiting for dependencies of container")
+		return
+	}
+	for _, dep := range deps {
+		if notifier, ok := restartContainers[dep.container]; ok {
+			<-notifier
+		}
+		if daemon.IsShuttingDown() {
+			return
+		}
+		if err := daemon.waitForDependency(context.Background(), dep); err != nil {
+			log.WithError(err).Warn("dependency of container did not meet its condition")
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newDaemonWithContainers(t *testing.T, containers ...*container.Container) *Daemon {
+	t.Helper()
+	store := container.NewMemoryStore()
+	replica, err := container.NewViewDB()
+	assert.NilError(t, err)
+	d := &Daemon{
+		containers:        store,
+		containersReplica: replica,
+	}
+	for _, c := range containers {
+		store.Add(c.ID, c)
+		assert.NilError(t, replica.Save(c))
+		_, err := d.reserveName(c.ID, c.Name)
+		assert.NilError(t, err)
+	}
+	return d
+}
+
+func TestCheckDependencyCycle(t *testing.T) {
+	db := &container.Container{
+		ID:         "db_id",
+		Name:       "/db",
+		HostConfig: &containertypes.HostConfig{},
+	}
+	app := &container.Container{
+		ID:   "app_id",
+		Name: "/app",
+		HostConfig: &containertypes.HostConfig{
+			DependsOn: []containertypes.Dependency{{Container: "db"}},
+		},
+	}
+	d := newDaemonWithContainers(t, db, app)
+
+	assert.NilError(t, d.checkDependencyCycle("proxy", "", []containertypes.Dependency{{Container: "app"}}))
+
+	// The name of the container being created may be used by a dependency.
+	err := d.checkDependencyCycle("db", "", []containertypes.Dependency{{Container: "app"}})
+	assert.Check(t, is.Error(err, "dependency cycle: db -> app -> db"))
+
+	// The dependencies of an existing container are changed when the containers
+	// it depends on are re-created.
+	db.HostConfig.DependsOn = []containertypes.Dependency{{Container: "app"}}
+	err = d.checkDependencyCycle(app.Name, app.ID, app.HostConfig.DependsOn)
+	assert.Check(t, is.Error(err, "dependency cycle: app -> db -> app"))
+
+	err = d.checkDependencyCycle("proxy", "", []containertypes.Dependency{{Container: "missing"}})
+	assert.Check(t, is.ErrorContains(err, "could not get dependency missing"))
+}
+
+func TestWaitForDependency(t *testing.T) {
+	db := &container.Container{
+		ID:   "db_id",
+		Name: "/db",
+		Config: &containertypes.Config{
+			Healthcheck: &containertypes.HealthConfig{Test: []string{"CMD", "true"}},
+		},
+		HostConfig: &containertypes.HostConfig{},
+		State:      container.NewState(),
+	}
+	d := newDaemonWithContainers(t, db)
+
+	err := d.waitForDependency(context.Background(), dependency{container: db, condition: containertypes.DependencyConditionStarted})
+	assert.Check(t, is.Error(err, "dependency db is not running"))
+
+	db.State.SetRunning(1, true)
+	err = d.waitForDependency(context.Background(), dependency{container: db, condition: containertypes.DependencyConditionStarted})
+	assert.Check(t, err)
+
+	db.State.Health = &container.Health{}
+	db.State.Health.SetStatus(types.Unhealthy)
+	err = d.waitForDependency(context.Background(), dependency{container: db, condition: containertypes.DependencyConditionHealthy})
+	assert.Check(t, is.Error(err, "dependency db is unhealthy"))
+
+	db.State.Health.SetStatus(types.Healthy)
+	err = d.waitForDependency(context.Background(), dependency{container: db, condition: containertypes.DependencyConditionHealthy})
+	assert.Check(t, err)
+}
+
+func TestStartDependenciesCanceled(t *testing.T) {
+	db := &container.Container{
+		ID:   "db_id",
+		Name: "/db",
+		Config: &containertypes.Config{
+			Healthcheck: &containertypes.HealthConfig{Test: []string{"CMD", "true"}},
+		},
+		HostConfig: &containertypes.HostConfig{},
+		State:      container.NewState(),
+	}
+	db.State.SetRunning(1, true)
+	db.State.Health = &container.Health{}
+	db.State.Health.SetStatus(types.Starting)
+	app := &container.Container{
+		ID:   "app_id",
+		Name: "/app",
+		HostConfig: &containertypes.HostConfig{
+			DependsOn: []containertypes.Dependency{{Container: "db", Condition: containertypes.DependencyConditionHealthy}},
+		},
+		State: container.NewState(),
+	}
+	d := newDaemonWithContainers(t, db, app)
+
+	// Waiting for the dependencies stops when the start of the container is
+	// canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := d.startDependencies(ctx, app)
+	assert.Check(t, is.ErrorIs(err, context.Canceled))
+}
+
+func TestRestartWithDependencies(t *testing.T) {
+	db := &container.Container{
+		ID:         "db_id",
+		Name:       "/db",
+		HostConfig: &containertypes.HostConfig{},
+		State:      container.NewState(),
+	}
+	app := &container.Container{
+		ID:   "app_id",
+		Name: "/app",
+		HostConfig: &containertypes.HostConfig{
+			DependsOn: []containertypes.Dependency{{Container: "db"}},
+		},
+		State: container.NewState(),
+	}
+	d := newDaemonWithContainers(t, db, app)
+	d.startupDone = make(chan struct{})
+
+	restartContainers := map[*container.Container]chan struct{}{
+		db:  make(chan struct{}),
+		app: make(chan struct{}),
+	}
+	go d.restartWithDependencies(app, restartContainers[app], restartContainers)
+
+	assertPending := func() {
+		t.Helper()
+		select {
+		case <-restartContainers[app]:
+			t.Fatal("container was started before its dependencies")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	assertPending()
+	close(d.startupDone)
+	assertPending()
+
+	// The container isn't started when the daemon is shutting down.
+	d.shutdown = true
+	close(restartContainers[db])
+	select {
+	case <-restartContainers[app]:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the container to be handled")
+	}
+}
@@ -2,6 +2,7 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"context"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
 	daemonevents "github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/libnetwork"
 	gogotypes "github.com/gogo/protobuf/types"
@@ -24,6 +26,23 @@ var (
 	}
 )
 
+// newEventsJournal opens the events journal in the daemon root, with the
+// retention configured in the daemon configuration.
+func newEventsJournal(conf *config.Config) (*daemonevents.Journal, error) {
+	maxSize, err := conf.Events.ParseMaxSize()
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := conf.Events.ParseMaxAge()
+	if err != nil {
+		return nil, err
+	}
+	return daemonevents.NewJournal(filepath.Join(conf.Root, "events"), daemonevents.JournalConfig{
+		MaxSize: maxSize,
+		MaxAge:  maxAge,
+	})
+}
+
 // LogContainerEvent generates an event related to a container with only the default attributes.
 func (daemon *Daemon) LogContainerEvent(container *container.Container, action string) {
 	daemon.LogContainerEventWithAttributes(container, action, map[string]string{})
@@ -6,6 +6,7 @@ import (
 
 	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -15,9 +16,10 @@ const (
 
 // Events is pubsub channel for events generated by the engine.
 type Events struct {
-	mu     sync.Mutex
-	events []eventtypes.Message
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *Journal
 }
 
 // New returns new *Events instance
@@ -49,6 +51,9 @@ func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 // SubscribeTopic adds new listener to events, returns slice of 256 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion).
+//
+// If a journal is set, the events emitted between since and until that are
+// older than the stored last events are replayed from the journal.
 func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtypes.Message, chan interface{}) {
 	eventSubscribers.Inc()
 	e.mu.Lock()
@@ -68,8 +73,63 @@ func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtype
 		ch = e.pub.Subscribe()
 	}
 
+	journal := e.journal
+	var oldest int64
+	if len(e.events) > 0 {
+		oldest = e.events[0].TimeNano
+	}
+	e.mu.Unlock()
+
+	if journal == nil || (since.IsZero() && until.IsZero()) {
+		return buffered, ch
+	}
+	if oldest != 0 {
+		if !since.IsZero() && since.UnixNano() >= oldest {
+			// All the requested events are in the buffer.
+			return buffered, ch
+		}
+		// The events in the buffer are also in the journal; only read
+		// those that are not in the buffer anymore. Events are written to
+		// the journal before they are added to the buffer, so the journal
+		// is up to date with the buffer.
+		if bufferStart := time.Unix(0, oldest-1); until.IsZero() || until.After(bufferStart) {
+			until = bufferStart
+		}
+	}
+
+	journaled, err := journal.Read(since, until, topic)
+	if err != nil {
+		logrus.WithError(err).Warn("failed to read events from the events journal")
+	}
+	return append(journaled, buffered...), ch
+}
+
+// SetJournal sets the journal in which the events are persisted. Passing a
+// nil journal disables the persistence of events. The previous journal, if
+// any, is closed.
+func (e *Events) SetJournal(j *Journal) {
+	e.mu.Lock()
+	prev := e.journal
+	e.journal = j
 	e.mu.Unlock()
-	return buffered, ch
+
+	if prev != nil && prev != j {
+		if err := prev.Close(); err != nil {
+			logrus.WithError(err).Warn("failed to close events journal")
+		}
+	}
+}
+
+// Close closes the journal of the events service, if any.
+func (e *Events) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.journal == nil {
+		return nil
+	}
+	err := e.journal.Close()
+	e.journal = nil
+	return err
 }
 
 // Evict evicts listener from pubsub
@@ -109,6 +169,19 @@ func (e *Events) Log(action string, eventType eventtypes.Type, actor eventtypes.
 func (e *Events) PublishMessage(jm eventtypes.Message) {
 	eventsCounter.Inc()
 
+	// Write the event to the journal before adding it to the buffer, so that
+	// the events which were discarded from the buffer are in the journal.
+	// The journal is written without holding e.mu, so that publishers and
+	// subscribers don't wait for the disk.
+	e.mu.Lock()
+	journal := e.journal
+	e.mu.Unlock()
+	if journal != nil {
+		if err := journal.Write(jm); err != nil {
+			logrus.WithError(err).Warn("failed to write event to the events journal")
+		}
+	}
+
 	e.mu.Lock()
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
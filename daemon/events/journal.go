@@ -0,0 +1,286 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultJournalMaxSize is the default maximum size of the journal.
+	DefaultJournalMaxSize int64 = 64 * 1024 * 1024
+
+	// segmentsPerJournal is the number of segments the journal is split
+	// into, so that the oldest events can be removed without rewriting
+	// the journal.
+	segmentsPerJournal = 8
+
+	segmentPrefix = "events-"
+	segmentSuffix = ".log"
+)
+
+// JournalConfig is the retention configuration of a Journal.
+type JournalConfig struct {
+	// MaxSize is the maximum size in bytes of the journal. The oldest
+	// events are removed when the journal grows beyond this size. If zero,
+	// DefaultJournalMaxSize is used.
+	MaxSize int64
+	// MaxAge is the maximum age of the events kept in the journal. If zero,
+	// events are only removed when the journal exceeds its maximum size.
+	MaxAge time.Duration
+}
+
+// Journal persists events in rotating segment files, so that they can be
+// replayed after the daemon restarts.
+type Journal struct {
+	mu       sync.Mutex
+	dir      string
+	config   JournalConfig
+	segments []*segment // sorted from oldest to newest
+	current  *os.File   // the file of the newest segment, if open for writing
+	closed   bool
+}
+
+// segment is a file containing the events emitted from the time of its first
+// event until the first event of the next segment.
+type segment struct {
+	path  string
+	first int64 // TimeNano of the first event in the segment
+	size  int64
+}
+
+// NewJournal opens the journal stored in dir, creating the directory if it
+// does not exist.
+func NewJournal(dir string, config JournalConfig) (*Journal, error) {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultJournalMaxSize
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create events journal directory")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read events journal directory")
+	}
+	j := &Journal{dir: dir, config: config}
+	for _, entry := range entries {
+		first, ok := parseSegmentName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read events journal directory")
+		}
+		j.segments = append(j.segments, &segment{
+			path:  filepath.Join(dir, entry.Name()),
+			first: first,
+			size:  info.Size(),
+		})
+	}
+	sort.Slice(j.segments, func(a, b int) bool {
+		return j.segments[a].first < j.segments[b].first
+	})
+
+	j.mu.Lock()
+	j.enforceRetention(time.Now(), 0)
+	j.mu.Unlock()
+	return j, nil
+}
+
+func segmentName(first int64) string {
+	return segmentPrefix + strconv.FormatInt(first, 10) + segmentSuffix
+}
+
+func parseSegmentName(name string) (int64, bool) {
+	if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+		return 0, false
+	}
+	first, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return first, true
+}
+
+// segmentSize returns the size after which a new segment is started.
+func (j *Journal) segmentSize() int64 {
+	return j.config.MaxSize / segmentsPerJournal
+}
+
+// Write appends an event to the journal.
+func (j *Journal) Write(m eventtypes.Message) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return errors.New("events journal is closed")
+	}
+	if j.current == nil || j.segments[len(j.segments)-1].size+int64(len(b)) > j.segmentSize() {
+		if err := j.rotate(m.TimeNano, int64(len(b))); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.current.Write(b)
+	j.segments[len(j.segments)-1].size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write event to journal")
+	}
+	return nil
+}
+
+// rotate starts a new segment, of which the first event was emitted at the
+// given time, and is pending bytes long. j.mu must be held.
+func (j *Journal) rotate(first, pending int64) error {
+	if j.current != nil {
+		if err := j.current.Close(); err != nil {
+			logrus.WithError(err).Warn("failed to close events journal segment")
+		}
+		j.current = nil
+	}
+
+	// Segments must be sorted by the time of their first event; make sure
+	// that the clock going backwards does not break this.
+	if n := len(j.segments); n > 0 && j.segments[n-1].first >= first {
+		first = j.segments[n-1].first + 1
+	}
+
+	path := filepath.Join(j.dir, segmentName(first))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create events journal segment")
+	}
+	j.current = f
+	j.segments = append(j.segments, &segment{path: path, first: first})
+	j.enforceRetention(time.Now(), pending)
+	return nil
+}
+
+// enforceRetention removes the oldest segments, until the journal is not
+// larger than its maximum size and does not contain events older than its
+// maximum age. pending is the size of the event about to be written. The
+// newest segment is never removed. j.mu must be held.
+func (j *Journal) enforceRetention(now time.Time, pending int64) {
+	total := pending
+	for _, s := range j.segments {
+		total += s.size
+	}
+
+	for len(j.segments) > 1 {
+		oldest, next := j.segments[0], j.segments[1]
+		// All the events of the oldest segment were emitted before the
+		// first event of the next segment.
+		expired := j.config.MaxAge > 0 && now.Sub(time.Unix(0, next.first)) > j.config.MaxAge
+		if total <= j.config.MaxSize && !expired {
+			return
+		}
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			logrus.WithError(err).WithField("file", oldest.path).Warn("failed to remove events journal segment")
+			return
+		}
+		total -= oldest.size
+		j.segments = j.segments[1:]
+	}
+}
+
+// Read returns the events in the journal that were emitted between since and
+// until, and for which topic returns true. A zero since or until means that
+// the time range is unbounded.
+func (j *Journal) Read(since, until time.Time, topic func(interface{}) bool) ([]eventtypes.Message, error) {
+	var sinceNanoUnix, untilNanoUnix int64
+	if !since.IsZero() {
+		sinceNanoUnix = since.UnixNano()
+	}
+	if !until.IsZero() {
+		untilNanoUnix = until.UnixNano()
+	}
+
+	j.mu.Lock()
+	segments := make([]segment, 0, len(j.segments))
+	for n, s := range j.segments {
+		if untilNanoUnix > 0 && s.first > untilNanoUnix {
+			break
+		}
+		if n+1 < len(j.segments) && j.segments[n+1].first < sinceNanoUnix {
+			continue
+		}
+		segments = append(segments, *s)
+	}
+	j.mu.Unlock()
+
+	var messages []eventtypes.Message
+	for _, s := range segments {
+		err := readSegment(s.path, func(m eventtypes.Message) {
+			if m.TimeNano < sinceNanoUnix || (untilNanoUnix > 0 && m.TimeNano > untilNanoUnix) {
+				return
+			}
+			if topic == nil || topic(m) {
+				messages = append(messages, m)
+			}
+		})
+		if err != nil {
+			return messages, err
+		}
+	}
+	return messages, nil
+}
+
+func readSegment(path string, fn func(eventtypes.Message)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// The segment was removed by the retention policy.
+			return nil
+		}
+		return errors.Wrap(err, "failed to open events journal segment")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var m eventtypes.Message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			// The last event may be truncated if the daemon crashed while
+			// writing it.
+			logrus.WithError(err).WithField("file", path).Debug("skipping invalid event in events journal")
+			continue
+		}
+		fn(m)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read events journal segment %s", path)
+	}
+	return nil
+}
+
+// Close closes the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closed = true
+	if j.current == nil {
+		return nil
+	}
+	err := j.current.Close()
+	j.current = nil
+	return err
+}
@@ -0,0 +1,154 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func journalMessage(id string, t time.Time) events.Message {
+	return events.Message{
+		Type:     events.ContainerEventType,
+		Action:   "start",
+		Actor:    events.Actor{ID: id},
+		Scope:    "local",
+		Time:     t.Unix(),
+		TimeNano: t.UnixNano(),
+	}
+}
+
+func TestJournalReadSinceUntil(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(dir, JournalConfig{})
+	assert.NilError(t, err)
+
+	start := time.Unix(1600000000, 0)
+	for n := 0; n < 10; n++ {
+		assert.NilError(t, j.Write(journalMessage(strconv.Itoa(n), start.Add(time.Duration(n)*time.Second))))
+	}
+	assert.NilError(t, j.Close())
+
+	// Reopen the journal, as done when the daemon restarts.
+	j, err = NewJournal(dir, JournalConfig{})
+	assert.NilError(t, err)
+	defer j.Close()
+
+	out, err := j.Read(start.Add(3*time.Second), start.Add(5*time.Second), nil)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(out, 3))
+	assert.Check(t, is.Equal(out[0].Actor.ID, "3"))
+	assert.Check(t, is.Equal(out[2].Actor.ID, "5"))
+
+	out, err = j.Read(time.Time{}, time.Time{}, func(m interface{}) bool {
+		return m.(events.Message).Actor.ID == "7"
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(out, 1))
+	assert.Check(t, is.Equal(out[0].Actor.ID, "7"))
+}
+
+func TestJournalMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	b, err := json.Marshal(journalMessage("00", time.Unix(1600000000, 0)))
+	assert.NilError(t, err)
+	size := int64(len(b) + 1)
+
+	// Each segment holds a single event.
+	j, err := NewJournal(dir, JournalConfig{MaxSize: segmentsPerJournal * size})
+	assert.NilError(t, err)
+	defer j.Close()
+
+	start := time.Unix(1600000000, 0)
+	for n := 10; n < 30; n++ {
+		assert.NilError(t, j.Write(journalMessage(strconv.Itoa(n), start.Add(time.Duration(n)*time.Second))))
+	}
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(entries, segmentsPerJournal))
+
+	out, err := j.Read(start, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(out, segmentsPerJournal))
+	assert.Check(t, is.Equal(out[0].Actor.ID, "22"))
+	assert.Check(t, is.Equal(out[len(out)-1].Actor.ID, "29"))
+}
+
+func TestJournalMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	// Each segment holds a single event.
+	j, err := NewJournal(dir, JournalConfig{MaxSize: segmentsPerJournal * 100, MaxAge: time.Hour})
+	assert.NilError(t, err)
+	defer j.Close()
+
+	now := time.Now()
+	assert.NilError(t, j.Write(journalMessage("a", now.Add(-3*time.Hour))))
+	assert.NilError(t, j.Write(journalMessage("b", now.Add(-2*time.Hour))))
+	assert.NilError(t, j.Write(journalMessage("c", now.Add(-time.Minute))))
+	assert.NilError(t, j.Write(journalMessage("d", now)))
+
+	out, err := j.Read(now.Add(-24*time.Hour), time.Time{}, nil)
+	assert.NilError(t, err)
+	var ids []string
+	for _, m := range out {
+		ids = append(ids, m.Actor.ID)
+	}
+	// A segment is only removed once the events of the next segment are
+	// expired, as the events it holds can be as recent as those.
+	assert.Check(t, is.DeepEqual(ids, []string{"b", "c", "d"}))
+}
+
+func TestSubscribeTopicReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(dir, JournalConfig{})
+	assert.NilError(t, err)
+
+	e := New()
+	e.SetJournal(j)
+	start := time.Now().Add(-time.Minute)
+	for n := 0; n < eventsLimit+10; n++ {
+		e.PublishMessage(journalMessage(strconv.Itoa(n), start.Add(time.Duration(n)*time.Millisecond)))
+	}
+
+	// The oldest events are not in the buffer anymore, but they are replayed
+	// from the journal.
+	out, ch := e.SubscribeTopic(start, time.Time{}, nil)
+	defer e.Evict(ch)
+	assert.Assert(t, is.Len(out, eventsLimit+10))
+	for n, m := range out {
+		assert.Check(t, is.Equal(m.Actor.ID, strconv.Itoa(n)))
+	}
+	assert.NilError(t, e.Close())
+
+	// After a restart, the events are replayed from the journal only.
+	j, err = NewJournal(dir, JournalConfig{})
+	assert.NilError(t, err)
+	e = New()
+	e.SetJournal(j)
+	defer e.Close()
+
+	out, ch = e.SubscribeTopic(start.Add(5*time.Millisecond), start.Add(9*time.Millisecond), nil)
+	defer e.Evict(ch)
+	assert.Assert(t, is.Len(out, 5))
+	assert.Check(t, is.Equal(out[0].Actor.ID, "5"))
+}
+
+func TestJournalWriteAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(dir, JournalConfig{})
+	assert.NilError(t, err)
+	assert.NilError(t, j.Close())
+
+	// Events may be published concurrently with the journal being closed;
+	// they must not reopen a segment.
+	assert.Check(t, is.ErrorContains(j.Write(journalMessage("0", time.Now())), "closed"))
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(entries, 0))
+}
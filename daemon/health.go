@@ -3,10 +3,17 @@ package daemon // import "github.com/docker/docker/daemon"
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -14,6 +21,10 @@ import (
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/exec"
+	"github.com/docker/docker/dockerversion"
+	libcontainerdtypes "github.com/docker/docker/libcontainerd/types"
+	"github.com/moby/sys/signal"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -44,7 +55,8 @@ const (
 const (
 	// Exit status codes that can be returned by the probe command.
 
-	exitStatusHealthy = 0 // Container is healthy
+	exitStatusHealthy   = 0 // Container is healthy
+	exitStatusUnhealthy = 1 // Container is unhealthy
 )
 
 // probe implementations know how to run a particular type of probe.
@@ -56,6 +68,8 @@ type probe interface {
 
 // cmdProbe implements the "CMD" probe type.
 type cmdProbe struct {
+	// The command to run.
+	args []string
 	// Run the command with the system's default shell instead of execing it directly.
 	shell bool
 }
@@ -64,7 +78,7 @@ type cmdProbe struct {
 // Returns the exit code and probe output (if any)
 func (p *cmdProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
 	startTime := time.Now()
-	cmdSlice := strslice.StrSlice(cntr.Config.Healthcheck.Test)[1:]
+	cmdSlice := strslice.StrSlice(p.args)
 	if p.shell {
 		cmdSlice = append(getShell(cntr), cmdSlice...)
 	}
@@ -163,8 +177,245 @@ func (p *cmdProbe) run(ctx context.Context, d *Daemon, cntr *container.Container
 	}, nil
 }
 
+// httpProbe implements the "HTTP" probe type.
+type httpProbe struct {
+	url *url.URL
+	// Ranges of accepted status codes.
+	status [][2]int
+	// String the body of the response must contain, if not empty.
+	body string
+}
+
+// parseHTTPProbe parses a {"HTTP", url, options...} healthcheck test.
+func parseHTTPProbe(test []string) (*httpProbe, error) {
+	if len(test) < 2 {
+		return nil, errors.New("HTTP healthcheck requires a URL")
+	}
+	u, err := url.Parse(test[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid HTTP healthcheck URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid HTTP healthcheck URL %q: scheme must be http or https", test[1])
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid HTTP healthcheck URL %q: missing host", test[1])
+	}
+	if err := validateProbeHost(u.Hostname()); err != nil {
+		return nil, errors.Wrapf(err, "invalid HTTP healthcheck URL %q", test[1])
+	}
+
+	p := &httpProbe{url: u}
+	for _, opt := range test[2:] {
+		k, v, ok := strings.Cut(opt, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid HTTP healthcheck option %q: must be key=value", opt)
+		}
+		switch k {
+		case "status":
+			for _, r := range strings.Split(v, ",") {
+				lo, hi, isRange := strings.Cut(r, "-")
+				if !isRange {
+					hi = lo
+				}
+				from, err := strconv.Atoi(lo)
+				if err != nil {
+					return nil, errors.Errorf("invalid HTTP healthcheck status %q", r)
+				}
+				to, err := strconv.Atoi(hi)
+				if err != nil || from < 100 || to > 599 || from > to {
+					return nil, errors.Errorf("invalid HTTP healthcheck status %q", r)
+				}
+				p.status = append(p.status, [2]int{from, to})
+			}
+		case "body":
+			p.body = v
+		default:
+			return nil, errors.Errorf("unknown HTTP healthcheck option %q", k)
+		}
+	}
+	if len(p.status) == 0 {
+		p.status = [][2]int{{200, 399}}
+	}
+	return p, nil
+}
+
+// acceptStatus returns whether a response with the given status code is a
+// success.
+func (p *httpProbe) acceptStatus(code int) bool {
+	for _, r := range p.status {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Send the request from the container's network namespace.
+// Returns the exit code and the status and body of the response.
+func (p *httpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	probeTimeout := timeoutWithDefault(cntr.Config.Healthcheck.Timeout, defaultProbeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialContainer(ctx, cntr, network, addr)
+			},
+			// Services commonly use self-signed certificates, which can't
+			// be verified by the daemon.
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint: gosec // G402: TLS InsecureSkipVerify set true.
+			DisableKeepAlives: true,
+		},
+		// Redirects are not followed, so that they can be accepted as a
+		// success without depending on the target of the redirect.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Docker-Health-Check/"+dockerversion.Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return networkProbeFailure(ctx, probeTimeout, err), nil
+	}
+	defer resp.Body.Close()
+
+	output := &limitedBuffer{}
+	fmt.Fprintf(output, "HTTP %s\n", resp.Status)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOutputLen))
+	if err != nil {
+		return networkProbeFailure(ctx, probeTimeout, err), nil
+	}
+	output.Write(body)
+
+	exitCode := exitStatusHealthy
+	if !p.acceptStatus(resp.StatusCode) || !bytes.Contains(body, []byte(p.body)) {
+		exitCode = exitStatusUnhealthy
+	}
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitCode,
+		Output:   output.String(),
+	}, nil
+}
+
+// tcpProbe implements the "TCP" probe type.
+type tcpProbe struct {
+	address string
+}
+
+// parseTCPProbe parses a {"TCP", address} healthcheck test.
+func parseTCPProbe(test []string) (*tcpProbe, error) {
+	if len(test) != 2 {
+		return nil, errors.New("TCP healthcheck requires a single address")
+	}
+	host, port, err := net.SplitHostPort(test[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid TCP healthcheck address")
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return nil, errors.Errorf("invalid TCP healthcheck address %q: invalid port", test[1])
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	if err := validateProbeHost(host); err != nil {
+		return nil, errors.Wrapf(err, "invalid TCP healthcheck address %q", test[1])
+	}
+	return &tcpProbe{address: net.JoinHostPort(host, port)}, nil
+}
+
+// Open a connection from the container's network namespace.
+// Returns the exit code and the outcome of the connection.
+func (p *tcpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	probeTimeout := timeoutWithDefault(cntr.Config.Healthcheck.Timeout, defaultProbeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	conn, err := dialContainer(ctx, cntr, "tcp", p.address)
+	if err != nil {
+		return networkProbeFailure(ctx, probeTimeout, err), nil
+	}
+	conn.Close()
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitStatusHealthy,
+		Output:   "Connected to " + p.address,
+	}, nil
+}
+
+// networkProbeFailure returns the result of an HTTP or TCP probe which failed
+// with err.
+func networkProbeFailure(ctx context.Context, probeTimeout time.Duration, err error) *types.HealthcheckResult {
+	if ctx.Err() == context.DeadlineExceeded {
+		return &types.HealthcheckResult{
+			ExitCode: -1,
+			Output:   fmt.Sprintf("Health check exceeded timeout (%v)", probeTimeout),
+			End:      time.Now(),
+		}
+	}
+	return &types.HealthcheckResult{
+		ExitCode: exitStatusUnhealthy,
+		Output:   err.Error(),
+		End:      time.Now(),
+	}
+}
+
+// validateProbeHost checks that the host of an HTTP or TCP probe can be
+// dialed without name resolution. Names would be resolved by the daemon,
+// with the configuration of the host instead of the one of the container.
+func validateProbeHost(host string) error {
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return nil
+	}
+	return errors.Errorf("host must be an IP address or localhost, got %q", host)
+}
+
+// probeAddrs returns the IP addresses to dial for the host of a probe.
+func probeAddrs(host string) ([]net.IP, error) {
+	if host == "localhost" {
+		return []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	return nil, validateProbeHost(host)
+}
+
+// dialContainer connects to address from the network namespace of the
+// container. The host of the address must be an IP address or localhost.
+func dialContainer(ctx context.Context, cntr *container.Container, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := probeAddrs(host)
+	if err != nil {
+		return nil, err
+	}
+	pid := cntr.State.GetPID()
+	if pid == 0 {
+		return nil, errors.Errorf("container %s is not running", cntr.ID)
+	}
+	for _, addr := range addrs {
+		var conn net.Conn
+		conn, err = dialInNetNS(ctx, pid, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
 // Update the container's Status.Health struct based on the latest probe's result.
-func handleProbeResult(d *Daemon, c *container.Container, result *types.HealthcheckResult, done chan struct{}) {
+// startup indicates whether the result is the one of the startup probe.
+func handleProbeResult(d *Daemon, c *container.Container, result *types.HealthcheckResult, done chan struct{}, startup bool) {
 	c.Lock()
 	defer c.Unlock()
 
@@ -179,6 +430,11 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 	if retries <= 0 {
 		retries = defaultProbeRetries
 	}
+	if startup {
+		// Failures of the startup probe only make the container unhealthy if
+		// a number of retries is set.
+		retries = c.Config.Healthcheck.StartupRetries
+	}
 
 	h := c.State.Health
 	oldStatus := h.Status()
@@ -211,7 +467,7 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 		if shouldIncrementStreak {
 			h.FailingStreak++
 
-			if h.FailingStreak >= retries {
+			if retries > 0 && h.FailingStreak >= retries {
 				h.SetStatus(types.Unhealthy)
 			}
 		}
@@ -228,19 +484,94 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 	current := h.Status()
 	if oldStatus != current {
 		d.LogContainerEvent(c, "health_status: "+current)
+		if current == types.Unhealthy {
+			go d.handleUnhealthy(c)
+		}
 	}
 }
 
+// handleUnhealthy takes the OnUnhealthy action of a container which became
+// unhealthy.
+func (daemon *Daemon) handleUnhealthy(c *container.Container) {
+	config := c.Config.Healthcheck
+	var err error
+	switch config.OnUnhealthy {
+	case containertypes.UnhealthyActionRestart:
+		err = daemon.restartUnhealthy(c)
+	case containertypes.UnhealthyActionStop:
+		err = daemon.containerStop(context.Background(), c, containertypes.StopOptions{})
+	case containertypes.UnhealthyActionKill:
+		sig := syscall.SIGKILL
+		if config.OnUnhealthySignal != "" {
+			sig, err = signal.ParseSignal(config.OnUnhealthySignal)
+			if err != nil {
+				break
+			}
+		}
+		err = daemon.killWithSignal(c, sig)
+	default:
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Warnf("Failed to %s unhealthy container", config.OnUnhealthy)
+	}
+}
+
+// restartUnhealthy stops the container, and makes the restart manager restart
+// it once it exited, regardless of its restart policy. Unlike "docker restart",
+// this goes through the restart manager, so that the restart count and the
+// backoff delay of the restart policy apply.
+func (daemon *Daemon) restartUnhealthy(c *container.Container) error {
+	stopTimeout := c.StopTimeout()
+	ctx, cancel := context.WithCancel(context.Background())
+	if stopTimeout >= 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(stopTimeout)*time.Second)
+	}
+	defer cancel()
+	exited := c.Wait(ctx, container.WaitConditionNextExit)
+
+	// The restart is only forced once the signal was sent, so that a failure
+	// doesn't leave it latched for an unrelated exit of the container. The
+	// container is kept locked in the meantime, so that its exit can't be
+	// handled before the restart is forced.
+	c.Lock()
+	if !c.Running || c.Restarting {
+		c.Unlock()
+		return nil
+	}
+	logrus.WithField("container", c.ID).Info("Restarting unhealthy container")
+	if err := daemon.containerd.SignalProcess(context.Background(), c.ID, libcontainerdtypes.InitProcessName, c.StopSignal()); err != nil {
+		c.Unlock()
+		return err
+	}
+	c.RestartManager().ForceRestart()
+	c.Unlock()
+
+	if status := <-exited; status.Err() == nil {
+		return nil
+	}
+
+	logrus.WithField("container", c.ID).Infof("Unhealthy container failed to exit within %d seconds of signal %d - using the force", stopTimeout, c.StopSignal())
+	return daemon.containerd.SignalProcess(context.Background(), c.ID, libcontainerdtypes.InitProcessName, syscall.SIGKILL)
+}
+
 // Run the container's monitoring thread until notified via "stop".
 // There is never more than one monitor thread running per container at a time.
-func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe probe) {
+// If startupProbe is not nil, it is run instead of probe while the container is
+// starting.
+func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe, startupProbe probe) {
 	probeInterval := timeoutWithDefault(c.Config.Healthcheck.Interval, defaultProbeInterval)
+	startupInterval := timeoutWithDefault(c.Config.Healthcheck.StartupInterval, probeInterval)
 
 	intervalTimer := time.NewTimer(probeInterval)
 	defer intervalTimer.Stop()
 
 	for {
-		intervalTimer.Reset(probeInterval)
+		p, interval, startup := probe, probeInterval, false
+		if startupProbe != nil && c.State.Health.Status() == types.Starting {
+			p, interval, startup = startupProbe, startupInterval, true
+		}
+		intervalTimer.Reset(interval)
 
 		select {
 		case <-stop:
@@ -253,7 +584,7 @@ func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe probe)
 			results := make(chan *types.HealthcheckResult, 1)
 			go func() {
 				healthChecksCounter.Inc()
-				result, err := probe.run(ctx, d, c)
+				result, err := p.run(ctx, d, c)
 				if err != nil {
 					healthChecksFailedCounter.Inc()
 					logrus.Warnf("Health check for container %s error: %v", c.ID, err)
@@ -279,7 +610,7 @@ func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe probe)
 				<-results
 				return
 			case result := <-results:
-				handleProbeResult(d, c, result, stop)
+				handleProbeResult(d, c, result, stop, startup)
 				cancelProbe()
 			}
 		}
@@ -290,18 +621,50 @@ func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe probe)
 // Nil will be returned if no healthcheck was configured or NONE was set.
 func getProbe(c *container.Container) probe {
 	config := c.Config.Healthcheck
-	if config == nil || len(config.Test) == 0 {
+	if config == nil {
+		return nil
+	}
+	return getProbeForTest(c, config.Test)
+}
+
+// Get a suitable probe implementation for the container's startup check
+// configuration. Nil will be returned if no startup check was configured or
+// NONE was set.
+func getStartupProbe(c *container.Container) probe {
+	config := c.Config.Healthcheck
+	if config == nil {
 		return nil
 	}
-	switch config.Test[0] {
+	return getProbeForTest(c, config.StartupTest)
+}
+
+func getProbeForTest(c *container.Container, test []string) probe {
+	if len(test) == 0 {
+		return nil
+	}
+	switch test[0] {
 	case "CMD":
-		return &cmdProbe{shell: false}
+		return &cmdProbe{args: test[1:], shell: false}
 	case "CMD-SHELL":
-		return &cmdProbe{shell: true}
+		return &cmdProbe{args: test[1:], shell: true}
+	case "HTTP":
+		p, err := parseHTTPProbe(test)
+		if err != nil {
+			logrus.Warnf("Invalid healthcheck in container %s: %v", c.ID, err)
+			return nil
+		}
+		return p
+	case "TCP":
+		p, err := parseTCPProbe(test)
+		if err != nil {
+			logrus.Warnf("Invalid healthcheck in container %s: %v", c.ID, err)
+			return nil
+		}
+		return p
 	case "NONE":
 		return nil
 	default:
-		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD') in container %s", config.Test[0], c.ID)
+		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD', 'HTTP' or 'TCP') in container %s", test[0], c.ID)
 		return nil
 	}
 }
@@ -319,7 +682,7 @@ func (daemon *Daemon) updateHealthMonitor(c *container.Container) {
 	wantRunning := c.Running && !c.Paused && probe != nil
 	if wantRunning {
 		if stop := h.OpenMonitorChannel(); stop != nil {
-			go monitor(daemon, c, stop, probe)
+			go monitor(daemon, c, stop, probe, getStartupProbe(c))
 		}
 	} else {
 		h.CloseMonitorChannel()
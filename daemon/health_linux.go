@@ -0,0 +1,46 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netns"
+)
+
+// dialInNetNS connects to address from the network namespace of the process
+// with the given pid. The address must not require name resolution.
+func dialInNetNS(ctx context.Context, pid int, network, address string) (net.Conn, error) {
+	// The socket is created in the network namespace of the thread it is
+	// created from, and stays in that namespace.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, err := netns.Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current network namespace")
+	}
+	defer origin.Close()
+
+	target, err := netns.GetFromPath(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get network namespace of container")
+	}
+	defer target.Close()
+
+	if err := netns.Set(target); err != nil {
+		return nil, errors.Wrap(err, "failed to enter network namespace of container")
+	}
+	defer func() {
+		if err := netns.Set(origin); err != nil {
+			// Keep the thread locked, so that it is terminated instead of
+			// being reused in the network namespace of the container.
+			runtime.LockOSThread()
+		}
+	}()
+
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+)
+
+type signalFailingClient struct {
+	MockContainerdClient
+}
+
+func (c *signalFailingClient) SignalProcess(ctx context.Context, containerID, processID string, signal syscall.Signal) error {
+	return errors.New("signal failed")
+}
+
+func TestRestartUnhealthySignalFailure(t *testing.T) {
+	c := &container.Container{
+		ID:         "container_id",
+		Config:     &containertypes.Config{},
+		HostConfig: &containertypes.HostConfig{},
+		State:      container.NewState(),
+	}
+	c.SetRunning(1234, true)
+
+	daemon := &Daemon{containerd: &signalFailingClient{}}
+	muteLogs()
+	if err := daemon.restartUnhealthy(c); err == nil {
+		t.Fatal("Expecting an error when the signal fails")
+	}
+
+	// The restart must not be forced for the next exit of the container,
+	// which has no restart policy.
+	restart, _, err := c.RestartManager().ShouldRestart(0, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restart {
+		t.Error("Expecting the container not to be restarted after a failed signal")
+	}
+}
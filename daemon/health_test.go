@@ -91,7 +91,14 @@ func TestHealthStates(t *testing.T) {
 			Start:    startTime,
 			End:      startTime,
 			ExitCode: exitCode,
-		}, nil)
+		}, nil, false)
+	}
+	handleStartupResult := func(startTime time.Time, exitCode int) {
+		handleProbeResult(daemon, c, &types.HealthcheckResult{
+			Start:    startTime,
+			End:      startTime,
+			ExitCode: exitCode,
+		}, nil, true)
 	}
 
 	// starting -> failed -> success -> failed
@@ -152,4 +159,96 @@ func TestHealthStates(t *testing.T) {
 	if c.State.Health.FailingStreak != 0 {
 		t.Errorf("Expecting FailingStreak=0, but got %d\n", c.State.Health.FailingStreak)
 	}
+
+	// Test startup check without retries
+
+	reset(c)
+	c.Config.Healthcheck.StartPeriod = 0
+
+	for i := 1; i <= 5; i++ {
+		handleStartupResult(c.State.StartedAt.Add(time.Duration(i)*time.Second), 1)
+	}
+	if status := c.State.Health.Status(); status != types.Starting {
+		t.Errorf("Expecting starting, but got %#v\n", status)
+	}
+	handleStartupResult(c.State.StartedAt.Add(6*time.Second), 0)
+	expect("health_status: healthy")
+
+	// Test startup check with retries
+
+	reset(c)
+	c.Config.Healthcheck.StartupRetries = 2
+
+	handleStartupResult(c.State.StartedAt.Add(1*time.Second), 1)
+	if status := c.State.Health.Status(); status != types.Starting {
+		t.Errorf("Expecting starting, but got %#v\n", status)
+	}
+	handleStartupResult(c.State.StartedAt.Add(2*time.Second), 1)
+	expect("health_status: unhealthy")
+}
+
+func TestParseHTTPProbe(t *testing.T) {
+	p, err := parseHTTPProbe([]string{"HTTP", "http://localhost:8080/health"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.acceptStatus(200) || !p.acceptStatus(302) || p.acceptStatus(404) {
+		t.Errorf("Expecting status codes 200 to 399 to be accepted, got %v", p.status)
+	}
+
+	p, err = parseHTTPProbe([]string{"HTTP", "https://localhost/health", "status=204,400-404", "body=OK"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.acceptStatus(204) || !p.acceptStatus(404) || p.acceptStatus(200) {
+		t.Errorf("Expecting status codes 204 and 400 to 404 to be accepted, got %v", p.status)
+	}
+	if p.body != "OK" {
+		t.Errorf("Expecting body OK, got %q", p.body)
+	}
+
+	for _, test := range [][]string{
+		{"HTTP"},
+		{"HTTP", "localhost:8080"},
+		{"HTTP", "ftp://localhost/"},
+		{"HTTP", "http://localhost/", "status=600"},
+		{"HTTP", "http://localhost/", "status=399-200"},
+		{"HTTP", "http://localhost/", "method=POST"},
+		{"HTTP", "http://localhost/", "body"},
+		{"HTTP", "http://example.com/"},
+	} {
+		if _, err := parseHTTPProbe(test); err == nil {
+			t.Errorf("Expecting an error for %v", test)
+		}
+	}
+}
+
+func TestParseTCPProbe(t *testing.T) {
+	p, err := parseTCPProbe([]string{"TCP", ":5432"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.address != "localhost:5432" {
+		t.Errorf("Expecting address localhost:5432, got %s", p.address)
+	}
+
+	p, err = parseTCPProbe([]string{"TCP", "[::1]:5432"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.address != "[::1]:5432" {
+		t.Errorf("Expecting address [::1]:5432, got %s", p.address)
+	}
+
+	for _, test := range [][]string{
+		{"TCP"},
+		{"TCP", "localhost"},
+		{"TCP", "localhost:http"},
+		{"TCP", "localhost:80", "localhost:443"},
+		{"TCP", "db:5432"},
+	} {
+		if _, err := parseTCPProbe(test); err == nil {
+			t.Errorf("Expecting an error for %v", test)
+		}
+	}
 }
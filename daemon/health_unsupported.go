@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// dialInNetNS is not supported on this platform.
+func dialInNetNS(ctx context.Context, pid int, network, address string) (net.Conn, error) {
+	return nil, errors.New("HTTP and TCP healthchecks are not supported on this platform")
+}
@@ -0,0 +1,143 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// imageMount is the volume of a mount of type "image", providing the
+// filesystem of an image. The filesystem is mounted from a layer on top of the
+// layers of the image, which keeps the layers of the image from being removed
+// until the container is removed.
+type imageMount struct {
+	images     ImageService
+	imgID      image.ID
+	layerName  string
+	mountLabel string
+
+	mu      sync.Mutex
+	rwLayer layer.RWLayer
+	path    string
+}
+
+// imageMountLayerName returns the name of the layer of the image mount of the
+// container at the given destination.
+func imageMountLayerName(containerID, destination string) string {
+	return containerID + "-" + digest.FromString(destination).Encoded()[:12]
+}
+
+// createImageMount creates the layer of an image mount of the container. The
+// Name of the mount point must be the ID of the image.
+func (daemon *Daemon) createImageMount(c *container.Container, m *volumemounts.MountPoint) (*imageMount, error) {
+	im := daemon.newImageMount(c, m)
+	rwLayer, err := daemon.imageService.CreateImageMountLayer(im.imgID, im.layerName, im.mountLabel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating mount of image %s", m.Spec.Source)
+	}
+	im.rwLayer = rwLayer
+	return im, nil
+}
+
+// newImageMount returns the image mount of a mount point, whose layer was
+// created by createImageMount.
+func (daemon *Daemon) newImageMount(c *container.Container, m *volumemounts.MountPoint) *imageMount {
+	return &imageMount{
+		images:     daemon.imageService,
+		imgID:      image.ID(m.Name),
+		layerName:  imageMountLayerName(c.ID, m.Destination),
+		mountLabel: c.MountLabel,
+	}
+}
+
+// getLayer returns the layer of the image mount. It is expected that callers
+// of this function hold the lock.
+func (m *imageMount) getLayer() (layer.RWLayer, error) {
+	if m.rwLayer == nil {
+		rwLayer, err := m.images.GetLayerByID(m.layerName)
+		if err != nil {
+			return nil, err
+		}
+		m.rwLayer = rwLayer
+	}
+	return m.rwLayer, nil
+}
+
+// release releases the layer of the image mount, which allows the layers of
+// the image to be removed.
+func (m *imageMount) release() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rwLayer, err := m.getLayer()
+	if err != nil {
+		if errors.Is(err, layer.ErrMountDoesNotExist) {
+			return nil
+		}
+		return err
+	}
+	m.rwLayer = nil
+	return m.images.ReleaseLayer(rwLayer)
+}
+
+// Name returns the ID of the image.
+func (m *imageMount) Name() string {
+	return m.imgID.String()
+}
+
+// DriverName returns an empty string, as image mounts are not managed by a
+// volume driver.
+func (m *imageMount) DriverName() string {
+	return ""
+}
+
+// Path returns the path the filesystem of the image is mounted at, if it is
+// mounted.
+func (m *imageMount) Path() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.path
+}
+
+// Mount mounts the filesystem of the image, and returns the path it is
+// mounted at.
+func (m *imageMount) Mount(id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rwLayer, err := m.getLayer()
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting mount of image %s", m.imgID)
+	}
+	fs, err := rwLayer.Mount(m.mountLabel)
+	if err != nil {
+		return "", errors.Wrapf(err, "error mounting image %s", m.imgID)
+	}
+	m.path = fs.Path()
+	return m.path, nil
+}
+
+// Unmount unmounts the filesystem of the image.
+func (m *imageMount) Unmount(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rwLayer == nil {
+		return nil
+	}
+	return m.rwLayer.Unmount()
+}
+
+func (m *imageMount) CreatedAt() (time.Time, error) {
+	return time.Time{}, errors.New("not implemented")
+}
+
+func (m *imageMount) Status() map[string]interface{} {
+	return nil
+}
@@ -0,0 +1,102 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"testing"
+
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/containerfs"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type fakeImageMountLayer struct {
+	layer.RWLayer
+	name    string
+	mounted int
+}
+
+func (l *fakeImageMountLayer) Name() string {
+	return l.name
+}
+
+func (l *fakeImageMountLayer) Mount(string) (containerfs.ContainerFS, error) {
+	l.mounted++
+	return containerfs.NewLocalContainerFS("/layers/" + l.name), nil
+}
+
+func (l *fakeImageMountLayer) Unmount() error {
+	l.mounted--
+	return nil
+}
+
+type fakeImageMountService struct {
+	ImageService
+	layers map[string]*fakeImageMountLayer
+}
+
+func (s *fakeImageMountService) CreateImageMountLayer(imgID image.ID, name, mountLabel string) (layer.RWLayer, error) {
+	if _, ok := s.layers[name]; ok {
+		return nil, layer.ErrMountNameConflict
+	}
+	l := &fakeImageMountLayer{name: name}
+	s.layers[name] = l
+	return l, nil
+}
+
+func (s *fakeImageMountService) GetLayerByID(name string) (layer.RWLayer, error) {
+	l, ok := s.layers[name]
+	if !ok {
+		return nil, layer.ErrMountDoesNotExist
+	}
+	return l, nil
+}
+
+func (s *fakeImageMountService) ReleaseLayer(rwLayer layer.RWLayer) error {
+	delete(s.layers, rwLayer.Name())
+	return nil
+}
+
+func TestImageMount(t *testing.T) {
+	images := &fakeImageMountService{layers: make(map[string]*fakeImageMountLayer)}
+	daemon := &Daemon{imageService: images}
+	c := &container.Container{ID: "container"}
+	mp := &volumemounts.MountPoint{
+		Type:        mounttypes.TypeImage,
+		Name:        "sha256:image",
+		Destination: "/assets",
+	}
+
+	im, err := daemon.createImageMount(c, mp)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(im.Name(), "sha256:image"))
+	layerName := imageMountLayerName(c.ID, mp.Destination)
+	assert.Assert(t, images.layers[layerName] != nil)
+
+	_, err = daemon.createImageMount(c, mp)
+	assert.Check(t, is.ErrorIs(err, layer.ErrMountNameConflict))
+
+	// After a restart of the daemon, the layer is looked up when mounting.
+	assert.NilError(t, daemon.lazyInitializeVolume(c, mp))
+	assert.Assert(t, mp.Volume != nil)
+	path, err := mp.Setup("", daemon.idMapping.RootPair(), nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(path, "/layers/"+layerName))
+	assert.Check(t, is.Equal(mp.Path(), path))
+	assert.Check(t, is.Equal(images.layers[layerName].mounted, 1))
+	assert.NilError(t, mp.Cleanup())
+	assert.Check(t, is.Equal(images.layers[layerName].mounted, 0))
+
+	assert.NilError(t, daemon.removeMountPoints(&container.Container{
+		ID:          c.ID,
+		MountPoints: map[string]*volumemounts.MountPoint{mp.Destination: mp},
+	}, false))
+	assert.Check(t, is.Len(images.layers, 0))
+
+	// Releasing an image mount without a layer is a no-op.
+	daemon.releaseMountPoint(context.Background(), c, mp)
+}
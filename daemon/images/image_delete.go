@@ -8,6 +8,7 @@ import (
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	mounttypes "github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
@@ -72,7 +73,7 @@ func (i *ImageService) ImageDelete(ctx context.Context, imageRef string, force,
 	repoRefs := i.referenceStore.References(imgID.Digest())
 
 	using := func(c *container.Container) bool {
-		return c.ImageID == imgID
+		return usesImage(c, imgID)
 	}
 
 	var removedRepositoryRef bool
@@ -346,6 +347,20 @@ func (i *ImageService) imageDeleteHelper(imgID image.ID, records *[]types.ImageD
 	return i.imageDeleteHelper(parent, records, false, true, true)
 }
 
+// usesImage returns whether the container was created from the image, or
+// mounts the image with a mount of type "image".
+func usesImage(c *container.Container, imgID image.ID) bool {
+	if c.ImageID == imgID {
+		return true
+	}
+	for _, m := range c.MountPoints {
+		if m.Type == mounttypes.TypeImage && m.Name == imgID.String() {
+			return true
+		}
+	}
+	return false
+}
+
 // checkImageDeleteConflict determines whether there are any conflicts
 // preventing deletion of the given image from this daemon. A hard conflict is
 // any image which has the given image as a parent or any running container
@@ -365,7 +380,7 @@ func (i *ImageService) checkImageDeleteConflict(imgID image.ID, mask conflictTyp
 	if mask&conflictRunningContainer != 0 {
 		// Check if any running container is using the image.
 		running := func(c *container.Container) bool {
-			return c.ImageID == imgID && c.IsRunning()
+			return usesImage(c, imgID) && c.IsRunning()
 		}
 		if ctr := i.containers.First(running); ctr != nil {
 			return &imageDeleteConflict{
@@ -388,7 +403,7 @@ func (i *ImageService) checkImageDeleteConflict(imgID image.ID, mask conflictTyp
 	if mask&conflictStoppedContainer != 0 {
 		// Check if any stopped containers reference this image.
 		stopped := func(c *container.Container) bool {
-			return !c.IsRunning() && c.ImageID == imgID
+			return !c.IsRunning() && usesImage(c, imgID)
 		}
 		if ctr := i.containers.First(stopped); ctr != nil {
 			return &imageDeleteConflict{
@@ -0,0 +1,30 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"testing"
+
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"gotest.tools/v3/assert"
+)
+
+func TestUsesImage(t *testing.T) {
+	imgID := image.ID("sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	otherID := image.ID("sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210")
+
+	c := &container.Container{ImageID: imgID}
+	assert.Check(t, usesImage(c, imgID))
+	assert.Check(t, !usesImage(c, otherID))
+
+	c.MountPoints = map[string]*volumemounts.MountPoint{
+		"/assets": {Type: mounttypes.TypeImage, Name: otherID.String(), Destination: "/assets"},
+	}
+	assert.Check(t, usesImage(c, otherID))
+
+	c.MountPoints = map[string]*volumemounts.MountPoint{
+		"/data": {Type: mounttypes.TypeVolume, Name: otherID.String(), Destination: "/data"},
+	}
+	assert.Check(t, !usesImage(c, otherID))
+}
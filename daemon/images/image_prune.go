@@ -2,16 +2,14 @@ package images // import "github.com/docker/docker/daemon/images"
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"sync/atomic"
-	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
-	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/daemon/internal/prunefilters"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
@@ -55,7 +53,7 @@ func (i *ImageService) ImagesPrune(ctx context.Context, pruneFilters filters.Arg
 		}
 	}
 
-	until, err := getUntilFromPruneFilters(pruneFilters)
+	until, err := prunefilters.GetUntil(pruneFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +80,7 @@ func (i *ImageService) ImagesPrune(ctx context.Context, pruneFilters filters.Arg
 			if !until.IsZero() && img.Created.After(until) {
 				continue
 			}
-			if img.Config != nil && !matchLabels(pruneFilters, img.Config.Labels) {
+			if img.Config != nil && !prunefilters.MatchLabels(pruneFilters, img.Config.Labels) {
 				continue
 			}
 			topImages[id] = img
@@ -170,38 +168,3 @@ func imageDeleteFailed(ref string, err error) bool {
 		return true
 	}
 }
-
-func matchLabels(pruneFilters filters.Args, labels map[string]string) bool {
-	if !pruneFilters.MatchKVList("label", labels) {
-		return false
-	}
-	// By default MatchKVList will return true if field (like 'label!') does not exist
-	// So we have to add additional Contains("label!") check
-	if pruneFilters.Contains("label!") {
-		if pruneFilters.MatchKVList("label!", labels) {
-			return false
-		}
-	}
-	return true
-}
-
-func getUntilFromPruneFilters(pruneFilters filters.Args) (time.Time, error) {
-	until := time.Time{}
-	if !pruneFilters.Contains("until") {
-		return until, nil
-	}
-	untilFilters := pruneFilters.Get("until")
-	if len(untilFilters) > 1 {
-		return until, fmt.Errorf("more than one until filter specified")
-	}
-	ts, err := timetypes.GetTimestamp(untilFilters[0], time.Now())
-	if err != nil {
-		return until, err
-	}
-	seconds, nanoseconds, err := timetypes.ParseTimestamps(ts, 0)
-	if err != nil {
-		return until, err
-	}
-	until = time.Unix(seconds, nanoseconds)
-	return until, nil
-}
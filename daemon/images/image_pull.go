@@ -20,6 +20,9 @@ import (
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PullImage initiates a pull operation. image is the repository name to pull, and
@@ -50,7 +53,15 @@ func (i *ImageService) PullImage(ctx context.Context, image, tag string, platfor
 		}
 	}
 
+	ctx, span := tracer.Start(ctx, "images.PullImage", trace.WithAttributes(
+		attribute.String("image.name", reference.FamiliarString(ref)),
+	))
 	err = i.pullImageWithReference(ctx, ref, platform, metaHeaders, authConfig, outStream)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 	imageActions.WithValues("pull").UpdateSince(start)
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	metrics "github.com/docker/go-metrics"
+	"go.opentelemetry.io/otel"
 )
 
 type invalidFilter struct {
@@ -23,6 +24,10 @@ func (e invalidFilter) InvalidParameter() {}
 
 var imageActions metrics.LabeledTimer
 
+// tracer records the spans of image operations. It is a no-op tracer if
+// tracing is not enabled.
+var tracer = otel.Tracer("github.com/docker/docker/daemon/images")
+
 func init() {
 	ns := metrics.NewNamespace("engine", "daemon", nil)
 	imageActions = ns.NewLabeledTimer("image_actions", "The number of seconds it takes to process each image action", "action")
@@ -144,6 +144,21 @@ func (i *ImageService) CreateLayer(container *container.Container, initFunc laye
 	return i.layerStore.CreateRWLayer(container.ID, layerID, rwLayerOpts)
 }
 
+// CreateImageMountLayer creates a layer with the given name on top of the
+// layers of the image, to mount the filesystem of the image into containers.
+// The layer keeps the layers of the image from being removed until it is
+// released with ReleaseLayer.
+// called from volumes.go Daemon.registerMountPoints()
+func (i *ImageService) CreateImageMountLayer(imgID image.ID, name, mountLabel string) (layer.RWLayer, error) {
+	img, err := i.imageStore.Get(imgID)
+	if err != nil {
+		return nil, err
+	}
+	return i.layerStore.CreateRWLayer(name, img.RootFS.ChainID(), &layer.CreateRWLayerOpts{
+		MountLabel: mountLabel,
+	})
+}
+
 // GetLayerByID returns a layer by ID
 // called from daemon.go Daemon.restore(), and Daemon.containerExport().
 func (i *ImageService) GetLayerByID(cid string) (layer.RWLayer, error) {
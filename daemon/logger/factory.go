@@ -125,8 +125,11 @@ func GetLogDriver(name string) (Creator, error) {
 }
 
 var builtInLogOpts = map[string]bool{
-	"mode":            true,
-	"max-buffer-size": true,
+	"mode":                   true,
+	"max-buffer-size":        true,
+	multilinePatternKey:      true,
+	multilineMaxLinesKey:     true,
+	multilineFlushTimeoutKey: true,
 }
 
 // ValidateLogOpts checks the options for the given log driver. The
@@ -151,6 +154,10 @@ func ValidateLogOpts(name string, cfg map[string]string) error {
 		}
 	}
 
+	if _, err := parseMultilineOpts(cfg); err != nil {
+		return err
+	}
+
 	if err := validateExternal(cfg); err != nil {
 		return err
 	}
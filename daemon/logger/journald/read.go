@@ -160,7 +160,16 @@ func (r *reader) drainJournal() error {
 
 	for i := 0; ; i++ {
 		if !r.initialized && i == 0 && r.config.Tail > 0 {
-			if n, err := r.j.PreviousSkip(uint(r.config.Tail)); err != nil || n == 0 {
+			var (
+				n   uint
+				err error
+			)
+			if r.config.Filter != nil {
+				n, err = r.previousSkipMatching(uint(r.config.Tail))
+			} else {
+				n, err = r.j.PreviousSkip(uint(r.config.Tail))
+			}
+			if err != nil || n == 0 {
 				return err
 			}
 		} else if ok, err := r.j.Next(); err != nil || !ok {
@@ -227,10 +236,12 @@ func (r *reader) drainJournal() error {
 					}
 				}
 			*/
-			select {
-			case <-r.logWatcher.WatchConsumerGone():
-				return errDrainDone
-			case r.logWatcher.Msg <- msg:
+			if r.config.Filter.Match(msg) {
+				select {
+				case <-r.logWatcher.WatchConsumerGone():
+					return errDrainDone
+				case r.logWatcher.Msg <- msg:
+				}
 			}
 		}
 
@@ -247,6 +258,30 @@ func (r *reader) drainJournal() error {
 	}
 }
 
+// previousSkipMatching moves the read pointer backwards to the n-th entry
+// from the current position which matches the filter, or to the first entry
+// of the journal if there are fewer matching entries. It returns the number of
+// entries the read pointer was moved by.
+func (r *reader) previousSkipMatching(n uint) (skipped uint, err error) {
+	for matched := uint(0); matched < n; {
+		if ok, err := r.j.Previous(); err != nil || !ok {
+			return skipped, err
+		}
+		skipped++
+		data, err := r.j.Data()
+		if err != nil {
+			return skipped, err
+		}
+		if line, ok := getMessage(data); ok {
+			msg := &logger.Message{Line: line, Source: getSource(data), Attrs: getAttrs(data)}
+			if r.config.Filter.Match(msg) {
+				matched++
+			}
+		}
+	}
+	return skipped, nil
+}
+
 func (r *reader) readJournal() error {
 	caughtUp := atomic.LoadUint64(&r.s.ordinal)
 	if err := r.drainJournal(); err != nil {
@@ -69,16 +69,12 @@ func New(info logger.Info) (logger.Logger, error) {
 		}
 	}
 
-	var compress bool
-	if compressString, ok := info.Config["compress"]; ok {
-		var err error
-		compress, err = strconv.ParseBool(compressString)
-		if err != nil {
-			return nil, err
-		}
-		if compress && (maxFiles == 1 || capval == -1) {
-			return nil, fmt.Errorf("compress cannot be true when max-file is less than 2 or max-size is not set")
-		}
+	compression, err := loggerutils.ParseCompression(info.Config["compress"], info.Config["compress-level"])
+	if err != nil {
+		return nil, err
+	}
+	if compression.Format != "" && (maxFiles == 1 || capval == -1) {
+		return nil, fmt.Errorf("compress cannot be true when max-file is less than 2 or max-size is not set")
 	}
 
 	attrs, err := info.ExtraAttributes(nil)
@@ -104,7 +100,7 @@ func New(info logger.Info) (logger.Logger, error) {
 		}
 	}
 
-	writer, err := loggerutils.NewLogFile(info.LogPath, capval, maxFiles, compress, decodeFunc, 0640, getTailReader)
+	writer, err := loggerutils.NewLogFile(info.LogPath, capval, maxFiles, compression, decodeFunc, 0640, getTailReader)
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +154,7 @@ func ValidateLogOpt(cfg map[string]string) error {
 		case "max-file":
 		case "max-size":
 		case "compress":
+		case "compress-level":
 		case "labels":
 		case "labels-regex":
 		case "env":
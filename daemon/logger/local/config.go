@@ -1,6 +1,7 @@
 package local
 
 import (
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/pkg/errors"
 )
 
@@ -9,6 +10,10 @@ type CreateConfig struct {
 	DisableCompression bool
 	MaxFileSize        int64
 	MaxFileCount       int
+	// Compression is the compression of rotated log files when compression
+	// is not disabled. Rotated log files are compressed with gzip if its
+	// format is empty.
+	Compression loggerutils.Compression
 }
 
 func newDefaultConfig() *CreateConfig {
@@ -37,9 +37,10 @@ var buffersPool = sync.Pool{New: func() interface{} {
 
 // LogOptKeys are the keys names used for log opts passed in to initialize the driver.
 var LogOptKeys = map[string]bool{
-	"max-file": true,
-	"max-size": true,
-	"compress": true,
+	"max-file":       true,
+	"max-size":       true,
+	"compress":       true,
+	"compress-level": true,
 }
 
 // ValidateLogOpt looks for log driver specific options.
@@ -89,13 +90,16 @@ func New(info logger.Info) (logger.Logger, error) {
 		}
 	}
 
-	if userCompress, ok := info.Config["compress"]; ok {
-		compressLogs, err := strconv.ParseBool(userCompress)
-		if err != nil {
-			return nil, errdefs.InvalidParameter(errors.Wrap(err, "error reading compress log option"))
-		}
-		cfg.DisableCompression = !compressLogs
+	userCompress, ok := info.Config["compress"]
+	if !ok {
+		userCompress = strconv.FormatBool(defaultCompressLogs)
+	}
+	compression, err := loggerutils.ParseCompression(userCompress, info.Config["compress-level"])
+	if err != nil {
+		return nil, errdefs.InvalidParameter(errors.Wrap(err, "error reading compress log option"))
 	}
+	cfg.DisableCompression = compression.Format == ""
+	cfg.Compression = compression
 	return newDriver(info.LogPath, cfg)
 }
 
@@ -138,7 +142,15 @@ func newDriver(logPath string, cfg *CreateConfig) (logger.Logger, error) {
 		return nil, errdefs.InvalidParameter(err)
 	}
 
-	lf, err := loggerutils.NewLogFile(logPath, cfg.MaxFileSize, cfg.MaxFileCount, !cfg.DisableCompression, decodeFunc, 0640, getTailReader)
+	var compression loggerutils.Compression
+	if !cfg.DisableCompression {
+		compression = cfg.Compression
+		if compression.Format == "" {
+			compression.Format = loggerutils.CompressGzip
+		}
+	}
+
+	lf, err := loggerutils.NewLogFile(logPath, cfg.MaxFileSize, cfg.MaxFileCount, compression, decodeFunc, 0640, getTailReader)
 	if err != nil {
 		return nil, err
 	}
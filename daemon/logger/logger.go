@@ -8,6 +8,7 @@
 package logger // import "github.com/docker/docker/daemon/logger"
 
 import (
+	"regexp"
 	"sync"
 	"time"
 
@@ -83,6 +84,57 @@ type ReadConfig struct {
 	Until  time.Time
 	Tail   int
 	Follow bool
+
+	// Filter selects the messages to read. When set, Tail counts only the
+	// messages matching the filter.
+	Filter *MessageFilter
+}
+
+// MessageFilter selects log messages by their line and attributes.
+type MessageFilter struct {
+	// Include is a list of expressions of which the line of a message must
+	// match at least one. All lines are included if the list is empty.
+	Include []*regexp.Regexp
+	// Exclude is a list of expressions of which the line of a message must
+	// not match any.
+	Exclude []*regexp.Regexp
+	// Attrs are the attributes a message must have. An empty value matches
+	// any value of the attribute.
+	Attrs map[string]string
+}
+
+// Match returns true if the message is selected by the filter. A nil filter
+// matches all messages.
+func (f *MessageFilter) Match(msg *Message) bool {
+	if f == nil {
+		return true
+	}
+	for k, v := range f.Attrs {
+		found := false
+		for _, attr := range msg.Attrs {
+			if attr.Key == k && (v == "" || attr.Value == v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, re := range f.Exclude {
+		if re.Match(msg.Line) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, re := range f.Include {
+		if re.Match(msg.Line) {
+			return true
+		}
+	}
+	return false
 }
 
 // LogReader is the interface for reading log messages for loggers that support reading.
@@ -0,0 +1,218 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/docker/docker/pkg/pools"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Compression formats of rotated log files.
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// compressedExtensions are the file extensions of rotated log files, by
+// compression format.
+var compressedExtensions = map[string]string{
+	CompressGzip: ".gz",
+	CompressZstd: ".zst",
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zstdSkippableFrameMagic is the magic number of the skippable frame which
+// holds the metadata of a zstd compressed log file.
+const zstdSkippableFrameMagic = 0x184d2a50
+
+// maxMetadataSize is the maximum size of the metadata of a zstd compressed log
+// file which is read.
+const maxMetadataSize = 4096
+
+// Compression configures the compression of rotated log files.
+type Compression struct {
+	// Format is the compression format, or empty if rotated log files are
+	// not compressed.
+	Format string
+	// Level is the compression level, or 0 for the default level of the
+	// compression format.
+	Level int
+}
+
+// ParseCompression parses the values of the compress and compress-level log
+// options. compress is either a boolean, where true selects gzip compression,
+// or the name of a compression format. An empty value disables compression.
+func ParseCompression(compress, level string) (Compression, error) {
+	var c Compression
+	switch compress {
+	case CompressGzip, CompressZstd:
+		c.Format = compress
+	case "":
+	default:
+		enabled, err := strconv.ParseBool(compress)
+		if err != nil {
+			return c, errors.Errorf("invalid value for compress: %s", compress)
+		}
+		if enabled {
+			c.Format = CompressGzip
+		}
+	}
+
+	if level == "" {
+		return c, nil
+	}
+	if c.Format == "" {
+		return c, errors.New("compress-level cannot be set when compression is disabled")
+	}
+	var err error
+	if c.Level, err = strconv.Atoi(level); err != nil {
+		return c, errors.Errorf("invalid value for compress-level: %s", level)
+	}
+	maxLevel := gzip.BestCompression
+	if c.Format == CompressZstd {
+		maxLevel = 22
+	}
+	if c.Level < 1 || c.Level > maxLevel {
+		return c, errors.Errorf("compress-level must be between 1 and %d for %s compression", maxLevel, c.Format)
+	}
+	return c, nil
+}
+
+// newCompressWriter returns a writer compressing to w in the configured
+// format, with the metadata stored in the header of the compressed file.
+func (c Compression) newCompressWriter(w io.Writer, metadata []byte) (io.WriteCloser, error) {
+	switch c.Format {
+	case CompressZstd:
+		// The metadata is stored in a skippable frame, which is ignored
+		// when decompressing the file.
+		if metadata != nil {
+			var hdr [8]byte
+			binary.LittleEndian.PutUint32(hdr[:4], zstdSkippableFrameMagic)
+			binary.LittleEndian.PutUint32(hdr[4:], uint32(len(metadata)))
+			if _, err := w.Write(append(hdr[:], metadata...)); err != nil {
+				return nil, err
+			}
+		}
+		level := zstd.SpeedDefault
+		if c.Level != 0 {
+			level = zstd.EncoderLevelFromZstd(c.Level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+	default:
+		level := gzip.DefaultCompression
+		if c.Level != 0 {
+			level = c.Level
+		}
+		gw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+		gw.Header.Extra = metadata
+		return gw, nil
+	}
+}
+
+// detectCompression returns the compression format of the file, based on its
+// magic number. The read position of the file is reset to its start.
+func detectCompression(f io.ReadSeeker) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	var magic [4]byte
+	n, err := io.ReadFull(f, magic[:])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(magic[:n], gzipMagic):
+		return CompressGzip, nil
+	case bytes.HasPrefix(magic[:n], zstdMagic):
+		return CompressZstd, nil
+	case n == 4 && binary.LittleEndian.Uint32(magic[:])&^0xf == zstdSkippableFrameMagic:
+		return CompressZstd, nil
+	}
+	return "", errors.New("unknown compression format")
+}
+
+// readCompressedMetadata returns the metadata stored in the header of a
+// compressed file, or nil if it has none. The file must be read from its
+// start.
+func readCompressedMetadata(f io.ReadSeeker, format string) ([]byte, error) {
+	if format == CompressGzip {
+		rc, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return rc.Header.Extra, nil
+	}
+
+	var hdr [8]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return nil, err
+	}
+	var h zstd.Header
+	if err := h.Decode(hdr[:]); err != nil {
+		return nil, err
+	}
+	if !h.Skippable || h.SkippableSize > maxMetadataSize {
+		return nil, nil
+	}
+	metadata := make([]byte, h.SkippableSize)
+	if _, err := io.ReadFull(f, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// readRotateFileMetadata returns the metadata of a compressed log file. The
+// zero value is returned if the file has no valid metadata.
+func readRotateFileMetadata(f io.ReadSeeker, format string) (rotateFileMetadata, error) {
+	var extra rotateFileMetadata
+	metadata, err := readCompressedMetadata(f, format)
+	if err != nil {
+		return extra, err
+	}
+	if json.Unmarshal(metadata, &extra) != nil {
+		return rotateFileMetadata{}, nil
+	}
+	return extra, nil
+}
+
+func decompress(dst io.WriteSeeker, src io.ReadSeeker) error {
+	format, err := detectCompression(src)
+	if err != nil {
+		return err
+	}
+	if format == CompressZstd {
+		dec, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		_, err = pools.Copy(dst, dec)
+		return err
+	}
+	rc, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	_, err = pools.Copy(dst, rc)
+	if err != nil {
+		return err
+	}
+	return rc.Close()
+}
@@ -0,0 +1,79 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestParseCompression(t *testing.T) {
+	for _, tc := range []struct {
+		compress, level string
+		expected        Compression
+		err             string
+	}{
+		{compress: "", expected: Compression{}},
+		{compress: "false", expected: Compression{}},
+		{compress: "true", expected: Compression{Format: CompressGzip}},
+		{compress: "zstd", level: "19", expected: Compression{Format: CompressZstd, Level: 19}},
+		{compress: "gzip", level: "9", expected: Compression{Format: CompressGzip, Level: 9}},
+		{compress: "lz4", err: "invalid value for compress: lz4"},
+		{compress: "false", level: "1", err: "compress-level cannot be set when compression is disabled"},
+		{compress: "gzip", level: "10", err: "compress-level must be between 1 and 9 for gzip compression"},
+		{compress: "zstd", level: "0", err: "compress-level must be between 1 and 22 for zstd compression"},
+		{compress: "zstd", level: "fast", err: "invalid value for compress-level: fast"},
+	} {
+		c, err := ParseCompression(tc.compress, tc.level)
+		if tc.err != "" {
+			assert.Check(t, is.Error(err, tc.err), "compress=%s, compress-level=%s", tc.compress, tc.level)
+			continue
+		}
+		assert.Check(t, err)
+		assert.Check(t, is.Equal(c, tc.expected))
+	}
+}
+
+func TestCompressFile(t *testing.T) {
+	for _, compression := range []Compression{
+		{Format: CompressGzip},
+		{Format: CompressZstd},
+		{Format: CompressZstd, Level: 19},
+	} {
+		compression := compression
+		t.Run(compression.Format, func(t *testing.T) {
+			dir := t.TempDir()
+			fileName := filepath.Join(dir, "log.1")
+			content := []byte("hello world!\nhello again!\n")
+			assert.NilError(t, os.WriteFile(fileName, content, 0600))
+
+			lastTime := time.Date(2022, time.August, 1, 12, 0, 0, 0, time.UTC)
+			assert.NilError(t, compressFile(fileName, lastTime, compression))
+			_, err := os.Stat(fileName)
+			assert.Check(t, os.IsNotExist(err), "uncompressed file was not removed")
+
+			f, err := os.Open(fileName + compressedExtensions[compression.Format])
+			assert.NilError(t, err)
+			defer f.Close()
+
+			format, err := detectCompression(f)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(format, compression.Format))
+
+			extra, err := readRotateFileMetadata(f, format)
+			assert.NilError(t, err)
+			assert.Check(t, extra.LastTime.Equal(lastTime))
+
+			out, err := os.Create(filepath.Join(dir, "out"))
+			assert.NilError(t, err)
+			defer out.Close()
+			assert.NilError(t, decompress(out, f))
+			actual, err := os.ReadFile(out.Name())
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(actual, content))
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
 
 import (
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -19,7 +18,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// rotateFileMetadata is a metadata of the gzip header of the compressed log file
+// rotateFileMetadata is a metadata of the header of the compressed log file
 type rotateFileMetadata struct {
 	LastTime time.Time `json:"lastTime,omitempty"`
 }
@@ -37,10 +36,10 @@ type LogFile struct {
 
 	// Logger configuration
 
-	capacity int64 // maximum size of each file
-	maxFiles int   // maximum number of files
-	compress bool  // whether old versions of log files are compressed
-	perms    os.FileMode
+	capacity    int64       // maximum size of each file
+	maxFiles    int         // maximum number of files
+	compression Compression // compression of old versions of log files
+	perms       os.FileMode
 
 	// Log file codec
 
@@ -119,7 +118,7 @@ type readAtCloser interface {
 type GetTailReaderFunc func(ctx context.Context, f SizeReaderAt, nLogLines int) (rdr io.Reader, nLines int, err error)
 
 // NewLogFile creates new LogFile
-func NewLogFile(logPath string, capacity int64, maxFiles int, compress bool, decodeFunc MakeDecoderFn, perms os.FileMode, getTailReader GetTailReaderFunc) (*LogFile, error) {
+func NewLogFile(logPath string, capacity int64, maxFiles int, compression Compression, decodeFunc MakeDecoderFn, perms os.FileMode, getTailReader GetTailReaderFunc) (*LogFile, error) {
 	log, err := openFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perms)
 	if err != nil {
 		return nil, err
@@ -146,7 +145,7 @@ func NewLogFile(logPath string, capacity int64, maxFiles int, compress bool, dec
 		closed:        make(chan struct{}),
 		capacity:      capacity,
 		maxFiles:      maxFiles,
-		compress:      compress,
+		compression:   compression,
 		decompress:    newSharedTempFileConverter(decompress),
 		createDecoder: decodeFunc,
 		perms:         perms,
@@ -197,7 +196,7 @@ func (w *LogFile) WriteLogEntry(timestamp time.Time, marshalled []byte) error {
 
 func (w *LogFile) rotate() (retErr error) {
 	w.rotateMu.Lock()
-	noCompress := w.maxFiles <= 1 || !w.compress
+	noCompress := w.maxFiles <= 1 || w.compression.Format == ""
 	defer func() {
 		// If we aren't going to run the goroutine to compress the log file, then we need to unlock in this function.
 		// Otherwise the lock will be released in the goroutine that handles compression.
@@ -218,7 +217,7 @@ func (w *LogFile) rotate() (retErr error) {
 		w.fsopMu.Lock()
 		defer w.fsopMu.Unlock()
 
-		if err := rotate(fname, w.maxFiles, w.compress); err != nil {
+		if err := rotate(fname, w.maxFiles, w.compression.Format != ""); err != nil {
 			logrus.WithError(err).Warn("Error rotating log file, log data may have been lost")
 		} else {
 			// We may have readers working their way through the
@@ -261,7 +260,7 @@ func (w *LogFile) rotate() (retErr error) {
 		// file once the compressed one is fully written out, so at no
 		// point during the compression process will a reader fail to
 		// open a complete copy of the file.
-		if err := compressFile(fname+".1", ts); err != nil {
+		if err := compressFile(fname+".1", ts, w.compression); err != nil {
 			logrus.WithError(err).Error("Error compressing log file after rotation")
 		}
 	}()
@@ -274,31 +273,38 @@ func rotate(name string, maxFiles int, compress bool) error {
 		return nil
 	}
 
-	var extension string
+	// Rotated files are compressed in the format configured at the time of
+	// their rotation, so files in all of the formats are rotated.
+	extensions := []string{""}
 	if compress {
-		extension = ".gz"
-	}
-
-	lastFile := fmt.Sprintf("%s.%d%s", name, maxFiles-1, extension)
-	err := unlink(lastFile)
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
-		return errors.Wrap(err, "error removing oldest log file")
+		extensions = make([]string, 0, len(compressedExtensions))
+		for _, ext := range compressedExtensions {
+			extensions = append(extensions, ext)
+		}
 	}
 
-	for i := maxFiles - 1; i > 1; i-- {
-		toPath := name + "." + strconv.Itoa(i) + extension
-		fromPath := name + "." + strconv.Itoa(i-1) + extension
-		err := os.Rename(fromPath, toPath)
-		logrus.WithError(err).WithField("source", fromPath).WithField("target", toPath).Trace("Rotating log file")
+	for _, extension := range extensions {
+		lastFile := fmt.Sprintf("%s.%d%s", name, maxFiles-1, extension)
+		err := unlink(lastFile)
 		if err != nil && !errors.Is(err, fs.ErrNotExist) {
-			return err
+			return errors.Wrap(err, "error removing oldest log file")
+		}
+
+		for i := maxFiles - 1; i > 1; i-- {
+			toPath := name + "." + strconv.Itoa(i) + extension
+			fromPath := name + "." + strconv.Itoa(i-1) + extension
+			err := os.Rename(fromPath, toPath)
+			logrus.WithError(err).WithField("source", fromPath).WithField("target", toPath).Trace("Rotating log file")
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-func compressFile(fileName string, lastTimestamp time.Time) (retErr error) {
+func compressFile(fileName string, lastTimestamp time.Time, compression Compression) (retErr error) {
 	file, err := open(fileName)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -317,33 +323,40 @@ func compressFile(fileName string, lastTimestamp time.Time) (retErr error) {
 		}
 	}()
 
-	outFile, err := openFile(fileName+".gz", os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0640)
+	outFileName := fileName + compressedExtensions[compression.Format]
+	outFile, err := openFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0640)
 	if err != nil {
-		return errors.Wrap(err, "failed to open or create gzip log file")
+		return errors.Wrapf(err, "failed to open or create %s log file", compression.Format)
 	}
 	defer func() {
 		outFile.Close()
 		if retErr != nil {
-			if err := unlink(fileName + ".gz"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			if err := unlink(outFileName); err != nil && !errors.Is(err, fs.ErrNotExist) {
 				logrus.WithError(err).Error("Error cleaning up after failed log compression")
 			}
 		}
 	}()
 
-	compressWriter := gzip.NewWriter(outFile)
-	defer compressWriter.Close()
-
-	// Add the last log entry timestamp to the gzip header
+	// Add the last log entry timestamp to the header
 	extra := rotateFileMetadata{}
 	extra.LastTime = lastTimestamp
-	compressWriter.Header.Extra, err = json.Marshal(&extra)
+	metadata, err := json.Marshal(&extra)
 	if err != nil {
 		// Here log the error only and don't return since this is just an optimization.
-		logrus.Warningf("Failed to marshal gzip header as JSON: %v", err)
+		logrus.Warningf("Failed to marshal compressed log file header as JSON: %v", err)
+		metadata = nil
 	}
 
+	compressWriter, err := compression.newCompressWriter(outFile, metadata)
+	if err != nil {
+		return errors.Wrapf(err, "error compressing log file %s", fileName)
+	}
 	_, err = pools.Copy(compressWriter, file)
 	if err != nil {
+		compressWriter.Close()
+		return errors.Wrapf(err, "error compressing log file %s", fileName)
+	}
+	if err := compressWriter.Close(); err != nil {
 		return errors.Wrapf(err, "error compressing log file %s", fileName)
 	}
 
@@ -505,7 +518,12 @@ func (w *LogFile) openRotatedFiles(config logger.ReadConfig) (files []readAtClos
 					return nil, errors.Wrap(err, "error opening rotated log file")
 				}
 				f.compressed = true
-				f.f, err = open(fmt.Sprintf("%s.%d.gz", w.f.Name(), i-1))
+				for _, ext := range compressedExtensions {
+					f.f, err = open(fmt.Sprintf("%s.%d%s", w.f.Name(), i-1, ext))
+					if err == nil || !errors.Is(err, fs.ErrNotExist) {
+						break
+					}
+				}
 				if err != nil {
 					if !errors.Is(err, fs.ErrNotExist) {
 						return nil, errors.Wrap(err, "error opening file for decompression")
@@ -542,37 +560,23 @@ func (w *LogFile) openRotatedFiles(config logger.ReadConfig) (files []readAtClos
 }
 
 func (w *LogFile) maybeDecompressFile(cf *os.File, config logger.ReadConfig) (readAtCloser, error) {
-	rc, err := gzip.NewReader(cf)
+	format, err := detectCompression(cf)
 	if err != nil {
-		return nil, errors.Wrap(err, "error making gzip reader for compressed log file")
+		return nil, errors.Wrap(err, "error detecting compression of log file")
 	}
-	defer rc.Close()
 
-	// Extract the last log entry timestramp from the gzip header
-	extra := &rotateFileMetadata{}
-	err = json.Unmarshal(rc.Header.Extra, extra)
-	if err == nil && !extra.LastTime.IsZero() && extra.LastTime.Before(config.Since) {
+	// Extract the last log entry timestramp from the header
+	extra, err := readRotateFileMetadata(cf, format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading header of %s compressed log file", format)
+	}
+	if !extra.LastTime.IsZero() && extra.LastTime.Before(config.Since) {
 		return nil, nil
 	}
 	tmpf, err := w.decompress.Do(cf)
 	return tmpf, errors.Wrap(err, "error decompressing log file")
 }
 
-func decompress(dst io.WriteSeeker, src io.ReadSeeker) error {
-	if _, err := src.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	rc, err := gzip.NewReader(src)
-	if err != nil {
-		return err
-	}
-	_, err = pools.Copy(dst, rc)
-	if err != nil {
-		return err
-	}
-	return rc.Close()
-}
-
 func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, getTailReader GetTailReaderFunc, nLines int, fwd *forwarder) (cont bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -589,7 +593,9 @@ func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, ge
 
 	readers := make([]io.Reader, 0, len(files))
 
-	if nLines > 0 {
+	// The number of lines to read back cannot be known in advance when the
+	// messages are filtered, so the files are read in full.
+	if nLines > 0 && fwd.filter == nil {
 		for i := len(files) - 1; i >= 0 && nLines > 0; i-- {
 			tail, n, err := getTailReader(ctx, files[i], nLines)
 			if err != nil {
@@ -607,15 +613,19 @@ func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, ge
 
 	rdr := io.MultiReader(readers...)
 	dec.Reset(rdr)
+	if nLines > 0 && fwd.filter != nil {
+		return fwd.DoTail(watcher, dec, nLines)
+	}
 	return fwd.Do(watcher, dec)
 }
 
 type forwarder struct {
 	since, until time.Time
+	filter       *logger.MessageFilter
 }
 
 func newForwarder(config logger.ReadConfig) *forwarder {
-	return &forwarder{since: config.Since, until: config.Until}
+	return &forwarder{since: config.Since, until: config.Until, filter: config.Filter}
 }
 
 // Do reads log messages from dec and sends the messages matching the filter
@@ -623,14 +633,61 @@ func newForwarder(config logger.ReadConfig) *forwarder {
 // dec without encountering a message with a timestamp which is after the
 // configured until time.
 func (fwd *forwarder) Do(watcher *logger.LogWatcher, dec Decoder) (cont bool) {
+	for {
+		msg, cont := fwd.next(watcher, dec)
+		if msg == nil {
+			return cont
+		}
+		select {
+		case <-watcher.WatchConsumerGone():
+			return false
+		case watcher.Msg <- msg:
+		}
+	}
+}
+
+// DoTail is like Do, but only sends the last nLines messages matching the
+// filter conditions to watcher.
+func (fwd *forwarder) DoTail(watcher *logger.LogWatcher, dec Decoder, nLines int) (cont bool) {
+	var msgs []*logger.Message
+	for {
+		select {
+		case <-watcher.WatchConsumerGone():
+			return false
+		default:
+		}
+		msg, c := fwd.next(watcher, dec)
+		if msg == nil {
+			cont = c
+			break
+		}
+		if len(msgs) == nLines {
+			msgs = msgs[1:]
+		}
+		msgs = append(msgs, msg)
+	}
+	for _, msg := range msgs {
+		select {
+		case <-watcher.WatchConsumerGone():
+			return false
+		case watcher.Msg <- msg:
+		}
+	}
+	return cont
+}
+
+// next reads log messages from dec until it finds one matching the filter
+// conditions. It returns a nil message when there are no more messages to
+// read, with cont set as documented for Do.
+func (fwd *forwarder) next(watcher *logger.LogWatcher, dec Decoder) (msg *logger.Message, cont bool) {
 	for {
 		msg, err := dec.Decode()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				return true
+				return nil, true
 			}
 			watcher.Err <- err
-			return false
+			return nil, false
 		}
 		if !fwd.since.IsZero() {
 			if msg.Timestamp.Before(fwd.since) {
@@ -643,12 +700,11 @@ func (fwd *forwarder) Do(watcher *logger.LogWatcher, dec Decoder) (cont bool) {
 			fwd.since = time.Time{}
 		}
 		if !fwd.until.IsZero() && msg.Timestamp.After(fwd.until) {
-			return false
+			return nil, false
 		}
-		select {
-		case <-watcher.WatchConsumerGone():
-			return false
-		case watcher.Msg <- msg:
+		if !fwd.filter.Match(msg) {
+			continue
 		}
+		return msg, true
 	}
 }
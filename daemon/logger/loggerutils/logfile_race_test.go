@@ -26,8 +26,8 @@ func TestConcurrentLogging(t *testing.T) {
 
 		capacity = 256
 		maxFiles = 3
-		compress = true
 	)
+	compression := Compression{Format: CompressGzip}
 	getTailReader := func(ctx context.Context, r SizeReaderAt, lines int) (io.Reader, int, error) {
 		return tailfile.NewTailReader(ctx, r, lines)
 	}
@@ -45,7 +45,7 @@ func TestConcurrentLogging(t *testing.T) {
 		ct := ct
 		dir := t.TempDir()
 		g.Go(func() (err error) {
-			logfile, err := NewLogFile(filepath.Join(dir, "log.log"), capacity, maxFiles, compress, createDecoder, 0644, getTailReader)
+			logfile, err := NewLogFile(filepath.Join(dir, "log.log"), capacity, maxFiles, compression, createDecoder, 0644, getTailReader)
 			if err != nil {
 				return err
 			}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"text/tabwriter"
@@ -103,6 +104,51 @@ func TestTailFiles(t *testing.T) {
 	}
 }
 
+func TestTailFilesFiltered(t *testing.T) {
+	s1 := strings.NewReader("GET /a\nPOST /b\nGET /c\n")
+	s2 := strings.NewReader("GET /d\nPOST /e\nGET /f\n")
+
+	files := []SizeReaderAt{s1, s2}
+	watcher := logger.NewLogWatcher()
+	defer watcher.ConsumerGone()
+
+	tailReader := func(ctx context.Context, r SizeReaderAt, lines int) (io.Reader, int, error) {
+		return tailfile.NewTailReader(ctx, r, lines)
+	}
+	dec := &copyingDecoder{}
+
+	config := logger.ReadConfig{
+		Tail:   2,
+		Filter: &logger.MessageFilter{Include: []*regexp.Regexp{regexp.MustCompile(`^POST`)}},
+	}
+	assert.Assert(t, tailFiles(files, watcher, dec, tailReader, config.Tail, newForwarder(config)))
+	close(watcher.Msg)
+
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	assert.DeepEqual(t, lines, []string{"POST /b", "POST /e"})
+}
+
+// copyingDecoder is a testDecoder which does not reuse the lines of the
+// messages it returns, and returns io.EOF at the end of the input.
+type copyingDecoder struct {
+	testDecoder
+}
+
+func (d *copyingDecoder) Decode() (*logger.Message, error) {
+	msg, err := d.testDecoder.Decode()
+	if msg == nil {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	msg.Line = append([]byte(nil), msg.Line...)
+	return msg, nil
+}
+
 type dummyDecoder struct{}
 
 func (dummyDecoder) Decode() (*logger.Message, error) {
@@ -124,9 +170,9 @@ func TestCheckCapacityAndRotate(t *testing.T) {
 	}
 	l, err := NewLogFile(
 		logPath,
-		5,    // capacity
-		3,    // maxFiles
-		true, // compress
+		5, // capacity
+		3, // maxFiles
+		Compression{Format: CompressGzip},
 		createDecoder,
 		0600, // perms
 		getTailReader,
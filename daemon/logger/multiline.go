@@ -0,0 +1,224 @@
+package logger // import "github.com/docker/docker/daemon/logger"
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	multilinePatternKey      = "multiline-pattern"
+	multilineMaxLinesKey     = "multiline-max-lines"
+	multilineFlushTimeoutKey = "multiline-flush-timeout"
+
+	defaultMultilineMaxLines     = 500
+	defaultMultilineFlushTimeout = time.Second
+)
+
+// multilineOpts are the options of a MultilineLogger.
+type multilineOpts struct {
+	pattern      *regexp.Regexp
+	maxLines     int
+	flushTimeout time.Duration
+}
+
+// parseMultilineOpts parses the multiline log options. It returns nil if
+// multiline aggregation is not enabled.
+func parseMultilineOpts(cfg map[string]string) (*multilineOpts, error) {
+	pattern, ok := cfg[multilinePatternKey]
+	if !ok {
+		for _, key := range []string{multilineMaxLinesKey, multilineFlushTimeoutKey} {
+			if _, ok := cfg[key]; ok {
+				return nil, errors.Errorf("logger: %s option is only supported with %s", key, multilinePatternKey)
+			}
+		}
+		return nil, nil
+	}
+
+	opts := &multilineOpts{
+		maxLines:     defaultMultilineMaxLines,
+		flushTimeout: defaultMultilineFlushTimeout,
+	}
+	var err error
+	if pattern == "" {
+		return nil, errors.Errorf("logger: %s option must not be empty", multilinePatternKey)
+	}
+	if opts.pattern, err = regexp.Compile(pattern); err != nil {
+		return nil, errors.Wrapf(err, "error parsing option %s", multilinePatternKey)
+	}
+	if s, ok := cfg[multilineMaxLinesKey]; ok {
+		if opts.maxLines, err = strconv.Atoi(s); err != nil {
+			return nil, errors.Wrapf(err, "error parsing option %s", multilineMaxLinesKey)
+		}
+		if opts.maxLines < 1 {
+			return nil, errors.Errorf("logger: %s option must be at least 1", multilineMaxLinesKey)
+		}
+	}
+	if s, ok := cfg[multilineFlushTimeoutKey]; ok {
+		if opts.flushTimeout, err = time.ParseDuration(s); err != nil {
+			return nil, errors.Wrapf(err, "error parsing option %s", multilineFlushTimeoutKey)
+		}
+		if opts.flushTimeout <= 0 {
+			return nil, errors.Errorf("logger: %s option must be positive", multilineFlushTimeoutKey)
+		}
+	}
+	return opts, nil
+}
+
+// MultilineLogger is a Logger which joins consecutive lines of a source into
+// a single message. A new message is started by each line matching the
+// configured pattern; the lines which follow it are appended to it until the
+// next matching line, the maximum number of lines is reached, or no line is
+// received for the flush timeout.
+//
+// Partial messages are joined with the line they are a part of, and a record
+// is not flushed in the middle of a line unless the flush timeout expires.
+type MultilineLogger struct {
+	l      Logger
+	opts   multilineOpts
+	mu     sync.Mutex
+	buf    map[string]*multilineRecord // the pending record of each source
+	closed bool
+}
+
+var _ SizedLogger = &MultilineLogger{}
+
+// multilineRecord is a message which is being aggregated.
+type multilineRecord struct {
+	source  string
+	msg     *Message
+	lines   int
+	partial bool // the last line of msg is not complete yet
+	timer   *time.Timer
+}
+
+type multilineWithReader struct {
+	*MultilineLogger
+}
+
+func (m *multilineWithReader) ReadLogs(cfg ReadConfig) *LogWatcher {
+	return m.l.(LogReader).ReadLogs(cfg)
+}
+
+// NewMultilineLogger creates a new Logger which aggregates multiline messages
+// before passing them to the driver, as configured by the multiline-pattern,
+// multiline-max-lines and multiline-flush-timeout options. The driver is
+// returned unchanged if the multiline-pattern option is not set.
+func NewMultilineLogger(driver Logger, cfg map[string]string) (Logger, error) {
+	opts, err := parseMultilineOpts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		return driver, nil
+	}
+	l := newMultilineLogger(driver, *opts)
+	if _, ok := driver.(LogReader); ok {
+		return &multilineWithReader{l}, nil
+	}
+	return l, nil
+}
+
+func newMultilineLogger(driver Logger, opts multilineOpts) *MultilineLogger {
+	return &MultilineLogger{
+		l:    driver,
+		opts: opts,
+		buf:  make(map[string]*multilineRecord),
+	}
+}
+
+// BufSize returns the buffer size of the underlying logger.
+// Returns -1 if the logger doesn't match SizedLogger interface.
+func (m *MultilineLogger) BufSize() int {
+	if sl, ok := m.l.(SizedLogger); ok {
+		return sl.BufSize()
+	}
+	return -1
+}
+
+// Name returns the name of the underlying logger
+func (m *MultilineLogger) Name() string {
+	return m.l.Name()
+}
+
+// Log appends the message to the pending record of its source, or starts a
+// new record with it.
+func (m *MultilineLogger) Log(msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return errClosed
+	}
+
+	rec := m.buf[msg.Source]
+	partial := msg.PLogMetaData != nil && !msg.PLogMetaData.Last
+	switch {
+	case rec != nil && rec.partial:
+		// Continuation of a line which was split into partial messages.
+		rec.msg.Line = append(rec.msg.Line, msg.Line...)
+		PutMessage(msg)
+	case rec != nil && !m.opts.pattern.Match(msg.Line):
+		rec.msg.Line = append(append(rec.msg.Line, '\n'), msg.Line...)
+		rec.lines++
+		PutMessage(msg)
+	default:
+		var err error
+		if rec != nil {
+			err = m.flush(msg.Source)
+		}
+		rec = &multilineRecord{source: msg.Source, msg: msg, lines: 1}
+		rec.timer = time.AfterFunc(m.opts.flushTimeout, func() { m.flushRecord(rec) })
+		m.buf[msg.Source] = rec
+		if err != nil {
+			return err
+		}
+	}
+
+	rec.partial = partial
+	if !partial {
+		rec.msg.PLogMetaData = nil
+		if rec.lines >= m.opts.maxLines {
+			return m.flush(rec.source)
+		}
+	}
+	rec.timer.Reset(m.opts.flushTimeout)
+	return nil
+}
+
+// flushRecord passes the record to the wrapped logger when its flush timeout
+// expires, unless it was already flushed.
+func (m *MultilineLogger) flushRecord(rec *multilineRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buf[rec.source] != rec {
+		return
+	}
+	if err := m.flush(rec.source); err != nil {
+		logDriverError(m.l.Name(), "", err)
+	}
+}
+
+// flush passes the pending record of the source to the wrapped logger. It must
+// be called with the lock held.
+func (m *MultilineLogger) flush(source string) error {
+	rec := m.buf[source]
+	delete(m.buf, source)
+	rec.timer.Stop()
+	return m.l.Log(rec.msg)
+}
+
+// Close flushes the pending records, and closes the wrapped logger.
+func (m *MultilineLogger) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	for source := range m.buf {
+		if err := m.flush(source); err != nil {
+			logDriverError(m.l.Name(), "", err)
+		}
+	}
+	m.mu.Unlock()
+	return m.l.Close()
+}
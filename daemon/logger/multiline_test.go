@@ -0,0 +1,140 @@
+package logger // import "github.com/docker/docker/daemon/logger"
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/backend"
+)
+
+func TestMultilineLogger(t *testing.T) {
+	mockLog := &mockLogger{make(chan *Message, 10)}
+	l := newMultilineLogger(mockLog, multilineOpts{
+		pattern:      regexp.MustCompile(`^\S`),
+		maxLines:     3,
+		flushTimeout: time.Hour,
+	})
+
+	for _, line := range []string{
+		"Exception in thread main",
+		"\tat Foo.bar",
+		"\tat Foo.main",
+		"\tat Foo.baz",
+		"next",
+		"\tdetails",
+		"last",
+	} {
+		if err := l.Log(&Message{Source: "stdout", Line: []byte(line)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Log(&Message{Source: "stderr", Line: []byte("\tstderr")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(mockLog.c)
+
+	expected := map[string][]string{
+		"stdout": {"Exception in thread main\n\tat Foo.bar\n\tat Foo.main", "\tat Foo.baz", "next\n\tdetails", "last"},
+		"stderr": {"\tstderr"},
+	}
+	actual := make(map[string][]string)
+	for msg := range mockLog.c {
+		actual[msg.Source] = append(actual[msg.Source], string(msg.Line))
+	}
+	for source, lines := range expected {
+		if len(actual[source]) != len(lines) {
+			t.Fatalf("expected %q for %s, got %q", lines, source, actual[source])
+		}
+		for i := range lines {
+			if actual[source][i] != lines[i] {
+				t.Fatalf("expected %q for %s, got %q", lines, source, actual[source])
+			}
+		}
+	}
+}
+
+func TestMultilineLoggerPartial(t *testing.T) {
+	mockLog := &mockLogger{make(chan *Message, 10)}
+	l := newMultilineLogger(mockLog, multilineOpts{
+		pattern:      regexp.MustCompile(`^ERROR`),
+		maxLines:     2,
+		flushTimeout: time.Hour,
+	})
+	defer l.Close()
+
+	for _, msg := range []*Message{
+		{Line: []byte("ERROR: a very"), PLogMetaData: &backend.PartialLogMetaData{ID: "1", Ordinal: 1}},
+		{Line: []byte(" long line"), PLogMetaData: &backend.PartialLogMetaData{ID: "1", Ordinal: 2, Last: true}},
+		{Line: []byte("ERROR follows")},
+	} {
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case msg := <-mockLog.c:
+		if string(msg.Line) != "ERROR: a very long line" {
+			t.Fatalf("got unexpected msg: %q", string(msg.Line))
+		}
+		if msg.PLogMetaData != nil {
+			t.Fatalf("expected a complete message, got %+v", msg.PLogMetaData)
+		}
+	default:
+		t.Fatal("expected the first record to be flushed")
+	}
+}
+
+func TestMultilineLoggerFlushTimeout(t *testing.T) {
+	mockLog := &mockLogger{make(chan *Message, 10)}
+	l := newMultilineLogger(mockLog, multilineOpts{
+		pattern:      regexp.MustCompile(`^\S`),
+		maxLines:     10,
+		flushTimeout: 10 * time.Millisecond,
+	})
+	defer l.Close()
+
+	if err := l.Log(&Message{Line: []byte("single")}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-mockLog.c:
+		if string(msg.Line) != "single" {
+			t.Fatalf("got unexpected msg: %q", string(msg.Line))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the record to be flushed")
+	}
+}
+
+func TestParseMultilineOpts(t *testing.T) {
+	opts, err := parseMultilineOpts(map[string]string{})
+	if err != nil || opts != nil {
+		t.Fatalf("expected multiline aggregation to be disabled, got %+v, %v", opts, err)
+	}
+
+	opts, err = parseMultilineOpts(map[string]string{multilinePatternKey: `^\d{4}-`, multilineMaxLinesKey: "20", multilineFlushTimeoutKey: "500ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.maxLines != 20 || opts.flushTimeout != 500*time.Millisecond {
+		t.Fatalf("got unexpected options: %+v", opts)
+	}
+
+	for _, cfg := range []map[string]string{
+		{multilineMaxLinesKey: "20"},
+		{multilinePatternKey: ""},
+		{multilinePatternKey: "("},
+		{multilinePatternKey: "^a", multilineMaxLinesKey: "0"},
+		{multilinePatternKey: "^a", multilineFlushTimeoutKey: "1"},
+		{multilinePatternKey: "^a", multilineFlushTimeoutKey: "-1s"},
+	} {
+		if _, err := parseMultilineOpts(cfg); err == nil {
+			t.Errorf("expected an error for %v", cfg)
+		}
+	}
+}
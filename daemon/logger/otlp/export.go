@@ -0,0 +1,181 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/pools"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// exportPath is the path of the OTLP/HTTP logs endpoint.
+	exportPath = "/v1/logs"
+
+	maxResponseSize = 1024
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// newResource returns the resource with the given attributes.
+func newResource(attrs map[string]string) *resourcepb.Resource {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := &resourcepb.Resource{}
+	for _, k := range keys {
+		res.Attributes = append(res.Attributes, stringAttr(k, attrs[k]))
+	}
+	return res
+}
+
+// newLogRecord returns the log record of the message. The source of the
+// message is recorded in the log.iostream attribute.
+func newLogRecord(msg *logger.Message) *logspb.LogRecord {
+	attrs := []*commonpb.KeyValue{stringAttr("log.iostream", msg.Source)}
+	for _, attr := range msg.Attrs {
+		attrs = append(attrs, stringAttr(attr.Key, attr.Value))
+	}
+	return &logspb.LogRecord{
+		TimeUnixNano: uint64(msg.Timestamp.UnixNano()),
+		Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(msg.Line)}},
+		Attributes:   attrs,
+	}
+}
+
+// newExportRequest returns an export request for the log records of a single
+// resource and instrumentation scope.
+func newExportRequest(resource *resourcepb.Resource, scope *commonpb.InstrumentationLibrary, records []*logspb.LogRecord) *collogspb.ExportLogsServiceRequest {
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: resource,
+			InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{{
+				InstrumentationLibrary: scope,
+				LogRecords:             records,
+			}},
+		}},
+	}
+}
+
+// exporter sends export requests to a collector.
+type exporter interface {
+	export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error
+	close() error
+}
+
+// grpcExporter exports log records using OTLP/gRPC.
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	client  collogspb.LogsServiceClient
+	headers metadata.MD
+}
+
+func newGRPCExporter(target string, tlsConfig *tls.Config, headers map[string]string) (*grpcExporter, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	// The connection is established in the background, so that the
+	// container can start while the collector is not reachable.
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcExporter{
+		conn:    conn,
+		client:  collogspb.NewLogsServiceClient(conn),
+		headers: metadata.New(headers),
+	}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	_, err := e.client.Export(ctx, req, grpc.WaitForReady(true))
+	return err
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// httpExporter exports log records using OTLP/HTTP with binary protobuf
+// encoding.
+type httpExporter struct {
+	url       string
+	headers   map[string]string
+	client    *http.Client
+	transport *http.Transport
+}
+
+func newHTTPExporter(url string, tlsConfig *tls.Config, headers map[string]string) *httpExporter {
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           http.ProxyFromEnvironment,
+	}
+	return &httpExporter{
+		url:       url,
+		headers:   headers,
+		client:    &http.Client{Transport: transport},
+		transport: transport,
+	}
+}
+
+func (e *httpExporter) export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		r.Header.Set(k, v)
+	}
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := e.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		pools.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s: failed to export logs - %s - %s", name, resp.Status, string(body))
+	}
+	return nil
+}
+
+func (e *httpExporter) close() error {
+	e.transport.CloseIdleConnections()
+	return nil
+}
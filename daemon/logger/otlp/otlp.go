@@ -0,0 +1,424 @@
+// Package otlp provides the log driver for forwarding container logs to an
+// OpenTelemetry collector using the OpenTelemetry protocol (OTLP).
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/docker/docker/daemon/logger/templates"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const (
+	name = "otlp"
+
+	protocolGRPC = "grpc"
+	protocolHTTP = "http/protobuf"
+
+	defaultGRPCEndpoint   = "http://localhost:4317"
+	defaultHTTPEndpoint   = "http://localhost:4318"
+	defaultTagTemplate    = "{{.Name}}"
+	defaultBatchSize      = 512
+	defaultBatchInterval  = time.Second
+	defaultExportTimeout  = 10 * time.Second
+	maxBufferedBatches    = 10
+	instrumentationScope  = "github.com/docker/docker/daemon/logger/otlp"
+	recordsChannelBatches = 4
+
+	endpointKey           = "otlp-endpoint"
+	protocolKey           = "otlp-protocol"
+	headersKey            = "otlp-headers"
+	tlsCACertKey          = "otlp-tls-ca-cert"
+	tlsCertKey            = "otlp-tls-cert"
+	tlsKeyKey             = "otlp-tls-key"
+	tlsSkipVerifyKey      = "otlp-tls-skip-verify"
+	resourceAttributesKey = "otlp-resource-attributes"
+	batchSizeKey          = "otlp-batch-size"
+	batchIntervalKey      = "otlp-batch-interval"
+	timeoutKey            = "otlp-timeout"
+)
+
+type otlpLogger struct {
+	exporter      exporter
+	resource      *resourcepb.Resource
+	scope         *commonpb.InstrumentationLibrary
+	batchSize     int
+	batchInterval time.Duration
+	timeout       time.Duration
+
+	mu      sync.RWMutex
+	closed  bool
+	records chan *logspb.LogRecord
+	done    chan struct{}
+}
+
+// config is the configuration of the otlp log driver.
+type config struct {
+	protocol      string
+	endpoint      string // the gRPC target, or the URL of the HTTP endpoint
+	tlsConfig     *tls.Config
+	headers       map[string]string
+	batchSize     int
+	batchInterval time.Duration
+	timeout       time.Duration
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// New creates an otlp logger using the configuration passed in on the
+// context. The log records are exported in batches to the collector at the
+// otlp-endpoint address.
+func New(info logger.Info) (logger.Logger, error) {
+	cfg, err := parseConfig(info.Config)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
+
+	attrs, err := resourceAttributes(info)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
+	var exp exporter
+	if cfg.protocol == protocolHTTP {
+		exp = newHTTPExporter(cfg.endpoint, cfg.tlsConfig, cfg.headers)
+	} else {
+		exp, err = newGRPCExporter(cfg.endpoint, cfg.tlsConfig, cfg.headers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.WithField("container", info.ContainerID).WithField("endpoint", cfg.endpoint).WithField("protocol", cfg.protocol).
+		Debug("logging driver otlp configured")
+
+	l := &otlpLogger{
+		exporter:      exp,
+		resource:      newResource(attrs),
+		scope:         &commonpb.InstrumentationLibrary{Name: instrumentationScope, Version: dockerversion.Version},
+		batchSize:     cfg.batchSize,
+		batchInterval: cfg.batchInterval,
+		timeout:       cfg.timeout,
+		records:       make(chan *logspb.LogRecord, recordsChannelBatches*cfg.batchSize),
+		done:          make(chan struct{}),
+	}
+	go l.worker()
+	return l, nil
+}
+
+// resourceAttributes returns the attributes of the resource the log records
+// of the container are attributed to.
+func resourceAttributes(info logger.Info) (map[string]string, error) {
+	hostname, err := info.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	tag, err := loggerutils.ParseLogTag(info, defaultTagTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := map[string]string{
+		"service.name":         tag,
+		"host.name":            hostname,
+		"container.id":         info.FullID(),
+		"container.name":       info.Name(),
+		"container.image.name": info.ImageName(),
+	}
+	extra, err := info.ExtraAttributes(nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		attrs[k] = v
+	}
+
+	// The values of the resource attributes are templates, like the tag.
+	kvs, err := parseKeyValues(info.Config[resourceAttributesKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", resourceAttributesKey)
+	}
+	for k, v := range kvs {
+		tmpl, err := templates.NewParse("otlp-resource-attribute", v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", resourceAttributesKey)
+		}
+		buf := new(bytes.Buffer)
+		if err := tmpl.Execute(buf, &info); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", resourceAttributesKey)
+		}
+		attrs[k] = buf.String()
+	}
+	return attrs, nil
+}
+
+// Log queues the message to be exported with the next batch. It never blocks,
+// so that a slow or unreachable collector doesn't block the container, even
+// with the default "blocking" log mode. When the queue is full, the message
+// is dropped and an error is returned. The "non-blocking" log mode buffers
+// the messages of the container in front of the queue, and can be used to
+// absorb bursts of messages instead of dropping them.
+func (l *otlpLogger) Log(msg *logger.Message) error {
+	record := newLogRecord(msg)
+	logger.PutMessage(msg)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return errors.Errorf("%s: driver is closed", name)
+	}
+	select {
+	case l.records <- record:
+		return nil
+	default:
+		return errors.Errorf("%s: too many log records waiting to be exported, dropping record", name)
+	}
+}
+
+// worker exports the log records in batches, when a batch is full, or when
+// the batch interval expires.
+func (l *otlpLogger) worker() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.batchInterval)
+	defer ticker.Stop()
+
+	var records []*logspb.LogRecord
+	for {
+		select {
+		case r, ok := <-l.records:
+			if !ok {
+				l.export(records, true)
+				return
+			}
+			records = append(records, r)
+			if len(records)%l.batchSize == 0 {
+				records = l.export(records, false)
+			}
+		case <-ticker.C:
+			records = l.export(records, false)
+		}
+	}
+}
+
+// export exports the records in batches. It returns the records which could
+// not be exported, to be retried with the next export. The oldest records are
+// dropped when more than maxBufferedBatches are waiting to be exported, or
+// when this is the last export.
+func (l *otlpLogger) export(records []*logspb.LogRecord, last bool) []*logspb.LogRecord {
+	for len(records) > 0 {
+		n := len(records)
+		if n > l.batchSize {
+			n = l.batchSize
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+		err := l.exporter.export(ctx, newExportRequest(l.resource, l.scope, records[:n]))
+		cancel()
+		if err == nil {
+			records = records[n:]
+			continue
+		}
+
+		logrus.WithError(err).WithField("module", "logger/otlp").Warn("Error while exporting logs")
+		switch {
+		case last:
+			logrus.WithField("module", "logger/otlp").Errorf("Dropped %d log records which could not be exported", len(records))
+			return nil
+		case len(records) > maxBufferedBatches*l.batchSize:
+			logrus.WithField("module", "logger/otlp").Errorf("Dropped %d log records which could not be exported", n)
+			records = records[n:]
+		}
+		return records
+	}
+	return records[:0]
+}
+
+func (l *otlpLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.records)
+	}
+	l.mu.Unlock()
+	<-l.done
+	return l.exporter.close()
+}
+
+func (l *otlpLogger) Name() string {
+	return name
+}
+
+// ValidateLogOpt looks for otlp specific log options.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "env":
+		case "env-regex":
+		case "labels":
+		case "labels-regex":
+		case "tag":
+
+		case endpointKey:
+		case protocolKey:
+		case headersKey:
+		case tlsCACertKey:
+		case tlsCertKey:
+		case tlsKeyKey:
+		case tlsSkipVerifyKey:
+		case resourceAttributesKey:
+		case batchSizeKey:
+		case batchIntervalKey:
+		case timeoutKey:
+			// Accepted
+		default:
+			return errors.Errorf("unknown log opt '%s' for %s log driver", key, name)
+		}
+	}
+
+	if _, err := parseKeyValues(cfg[resourceAttributesKey]); err != nil {
+		return errors.Wrapf(err, "invalid %s", resourceAttributesKey)
+	}
+	_, err := parseConfig(cfg)
+	return err
+}
+
+func parseConfig(cfg map[string]string) (*config, error) {
+	c := &config{
+		protocol:      protocolGRPC,
+		batchSize:     defaultBatchSize,
+		batchInterval: defaultBatchInterval,
+		timeout:       defaultExportTimeout,
+	}
+
+	endpoint := defaultGRPCEndpoint
+	switch p := cfg[protocolKey]; p {
+	case "", protocolGRPC:
+	case protocolHTTP:
+		c.protocol = protocolHTTP
+		endpoint = defaultHTTPEndpoint
+	default:
+		return nil, errors.Errorf("invalid %s: %s (must be %s or %s)", protocolKey, p, protocolGRPC, protocolHTTP)
+	}
+	if cfg[endpointKey] != "" {
+		endpoint = cfg[endpointKey]
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", endpointKey)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid %s: %s (scheme must be http or https)", endpointKey, endpoint)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid %s: %s (host is missing)", endpointKey, endpoint)
+	}
+
+	if c.protocol == protocolHTTP {
+		u.Path = strings.TrimSuffix(u.Path, "/") + exportPath
+		c.endpoint = u.String()
+	} else {
+		if u.Path != "" && u.Path != "/" {
+			return nil, errors.Errorf("invalid %s: %s (should not contain a path element)", endpointKey, endpoint)
+		}
+		c.endpoint = u.Host
+		if u.Port() == "" {
+			c.endpoint = net.JoinHostPort(u.Hostname(), "4317")
+		}
+	}
+
+	if c.tlsConfig, err = parseTLSConfig(cfg, u); err != nil {
+		return nil, err
+	}
+	if c.headers, err = parseKeyValues(cfg[headersKey]); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", headersKey)
+	}
+
+	if s := cfg[batchSizeKey]; s != "" {
+		if c.batchSize, err = strconv.Atoi(s); err != nil || c.batchSize < 1 {
+			return nil, errors.Errorf("invalid %s: %s (must be a positive integer)", batchSizeKey, s)
+		}
+	}
+	if s := cfg[batchIntervalKey]; s != "" {
+		if c.batchInterval, err = time.ParseDuration(s); err != nil || c.batchInterval <= 0 {
+			return nil, errors.Errorf("invalid %s: %s (must be a positive duration)", batchIntervalKey, s)
+		}
+	}
+	if s := cfg[timeoutKey]; s != "" {
+		if c.timeout, err = time.ParseDuration(s); err != nil || c.timeout <= 0 {
+			return nil, errors.Errorf("invalid %s: %s (must be a positive duration)", timeoutKey, s)
+		}
+	}
+	return c, nil
+}
+
+// parseTLSConfig returns the TLS configuration to connect to the endpoint, or
+// nil if the endpoint does not use TLS.
+func parseTLSConfig(cfg map[string]string, endpoint *url.URL) (*tls.Config, error) {
+	if endpoint.Scheme != "https" {
+		for _, key := range []string{tlsCACertKey, tlsCertKey, tlsKeyKey, tlsSkipVerifyKey} {
+			if _, ok := cfg[key]; ok {
+				return nil, errors.Errorf("%s is only supported with an https %s", key, endpointKey)
+			}
+		}
+		return nil, nil
+	}
+
+	opts := tlsconfig.Options{
+		CAFile:   cfg[tlsCACertKey],
+		CertFile: cfg[tlsCertKey],
+		KeyFile:  cfg[tlsKeyKey],
+	}
+	if s, ok := cfg[tlsSkipVerifyKey]; ok {
+		skipVerify, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", tlsSkipVerifyKey)
+		}
+		opts.InsecureSkipVerify = skipVerify
+	}
+	tlsConfig, err := tlsconfig.Client(opts)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = endpoint.Hostname()
+	return tlsConfig, nil
+}
+
+// parseKeyValues parses a comma-separated list of key=value pairs.
+func parseKeyValues(s string) (map[string]string, error) {
+	kvs := make(map[string]string)
+	if s == "" {
+		return kvs, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, errors.Errorf("%q is not in key=value format", kv)
+		}
+		kvs[k] = strings.TrimSpace(v)
+	}
+	return kvs, nil
+}
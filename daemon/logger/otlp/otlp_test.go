@@ -0,0 +1,383 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/daemon/logger"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// logRecord is a decoded log record received by the fake collector.
+type logRecord struct {
+	body  string
+	attrs map[string]string
+}
+
+// exportRequest is a decoded export request received by the fake collector.
+type exportRequest struct {
+	resource map[string]string
+	scope    string
+	records  []logRecord
+	headers  map[string]string
+}
+
+// collector is a fake OTLP collector which records the export requests it
+// receives.
+type collector struct {
+	mu       sync.Mutex
+	requests []exportRequest
+	received chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{received: make(chan struct{}, 100)}
+}
+
+func (c *collector) record(t *testing.T, data *collogspb.ExportLogsServiceRequest, headers map[string]string) {
+	t.Helper()
+	req := decodeExportRequest(t, data)
+	req.headers = headers
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+	c.received <- struct{}{}
+}
+
+func (c *collector) wait(t *testing.T, n int) []exportRequest {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.received:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout waiting for export request %d", i+1)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+// grpcCollector implements the OTLP/gRPC logs service.
+type grpcCollector struct {
+	collogspb.UnimplementedLogsServiceServer
+	t *testing.T
+	c *collector
+}
+
+func (s *grpcCollector) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	headers := make(map[string]string)
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range md {
+		headers[k] = strings.Join(v, ",")
+	}
+	s.c.record(s.t, req, headers)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// serveGRPC serves the OTLP/gRPC logs service, and returns the address of the
+// server.
+func (c *collector) serveGRPC(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, &grpcCollector{t: t, c: c})
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	return l.Addr().String()
+}
+
+// serveHTTP serves the OTLP/HTTP logs endpoint, and returns the URL of the
+// server.
+func (c *collector) serveHTTP(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != exportPath {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("unexpected content type %s", ct)
+		}
+		req, err := readExportRequest(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		headers := make(map[string]string)
+		for k := range r.Header {
+			headers[strings.ToLower(k)] = r.Header.Get(k)
+		}
+		c.record(t, req, headers)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// readExportRequest reads an OTLP/HTTP export request.
+func readExportRequest(r io.Reader) (*collogspb.ExportLogsServiceRequest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	req := &collogspb.ExportLogsServiceRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeAttrs(kvs []*commonpb.KeyValue) map[string]string {
+	attrs := make(map[string]string)
+	for _, kv := range kvs {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	return attrs
+}
+
+func decodeExportRequest(t *testing.T, data *collogspb.ExportLogsServiceRequest) exportRequest {
+	t.Helper()
+	assert.Assert(t, is.Len(data.ResourceLogs, 1))
+	rl := data.ResourceLogs[0]
+	assert.Assert(t, is.Len(rl.InstrumentationLibraryLogs, 1))
+	ill := rl.InstrumentationLibraryLogs[0]
+
+	req := exportRequest{
+		resource: decodeAttrs(rl.Resource.Attributes),
+		scope:    ill.InstrumentationLibrary.GetName(),
+	}
+	for _, r := range ill.LogRecords {
+		req.records = append(req.records, logRecord{
+			body:  r.Body.GetStringValue(),
+			attrs: decodeAttrs(r.Attributes),
+		})
+	}
+	return req
+}
+
+func newTestLogger(t *testing.T, cfg map[string]string) logger.Logger {
+	t.Helper()
+	info := logger.Info{
+		Config:             cfg,
+		ContainerID:        "c1234567890abcdef",
+		ContainerName:      "/web",
+		ContainerImageName: "nginx:latest",
+		ContainerLabels:    map[string]string{"tier": "frontend"},
+	}
+	l, err := New(info)
+	assert.NilError(t, err)
+	return l
+}
+
+func logMessages(t *testing.T, l logger.Logger, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		msg := logger.NewMessage()
+		msg.Line = append(msg.Line, line...)
+		msg.Source = "stdout"
+		msg.Timestamp = time.Now()
+		msg.Attrs = []backend.LogAttr{{Key: "line", Value: line}}
+		assert.NilError(t, l.Log(msg))
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	c := newCollector()
+	addr := c.serveGRPC(t)
+
+	l := newTestLogger(t, map[string]string{
+		endpointKey:           "http://" + addr,
+		headersKey:            "authorization=Bearer token",
+		"labels":              "tier",
+		resourceAttributesKey: "deployment.environment=test-{{.ID}}",
+		batchSizeKey:          "2",
+		batchIntervalKey:      "1h",
+	})
+	logMessages(t, l, "one", "two", "three")
+
+	// The first two messages fill a batch, the third is exported on close.
+	requests := c.wait(t, 1)
+	assert.Assert(t, is.Len(requests, 1))
+	assert.NilError(t, l.Close())
+	requests = c.wait(t, 1)
+	assert.Assert(t, is.Len(requests, 2))
+
+	req := requests[0]
+	assert.Check(t, is.Equal(req.headers["authorization"], "Bearer token"))
+	assert.Check(t, is.Equal(req.scope, instrumentationScope))
+	assert.Check(t, is.Equal(req.resource["service.name"], "web"))
+	assert.Check(t, is.Equal(req.resource["container.id"], "c1234567890abcdef"))
+	assert.Check(t, is.Equal(req.resource["container.name"], "web"))
+	assert.Check(t, is.Equal(req.resource["container.image.name"], "nginx:latest"))
+	assert.Check(t, is.Equal(req.resource["tier"], "frontend"))
+	assert.Check(t, is.Equal(req.resource["deployment.environment"], "test-c1234567890a"))
+	assert.Assert(t, is.Len(req.records, 2))
+	assert.Check(t, is.Equal(req.records[0].body, "one"))
+	assert.Check(t, is.DeepEqual(req.records[0].attrs, map[string]string{"log.iostream": "stdout", "line": "one"}))
+	assert.Check(t, is.Equal(req.records[1].body, "two"))
+
+	assert.Assert(t, is.Len(requests[1].records, 1))
+	assert.Check(t, is.Equal(requests[1].records[0].body, "three"))
+}
+
+func TestHTTP(t *testing.T) {
+	c := newCollector()
+	url := c.serveHTTP(t)
+
+	l := newTestLogger(t, map[string]string{
+		endpointKey:      url,
+		protocolKey:      protocolHTTP,
+		headersKey:       "x-api-key=secret",
+		"tag":            "{{.ImageName}}",
+		batchIntervalKey: "10ms",
+	})
+	logMessages(t, l, "one", "two")
+
+	// The messages are exported when the batch interval expires.
+	requests := c.wait(t, 1)
+	for n := len(requests[0].records); n < 2; n += len(requests[len(requests)-1].records) {
+		requests = c.wait(t, 1)
+	}
+	assert.NilError(t, l.Close())
+
+	req := requests[0]
+	assert.Check(t, is.Equal(req.headers["x-api-key"], "secret"))
+	assert.Check(t, is.Equal(req.resource["service.name"], "nginx:latest"))
+	assert.Check(t, is.Equal(req.records[0].body, "one"))
+}
+
+func TestExportRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		failures = 1
+	)
+	c := newCollector()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		req, err := readExportRequest(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		c.record(t, req, nil)
+	}))
+	defer srv.Close()
+
+	l := newTestLogger(t, map[string]string{
+		endpointKey:      srv.URL,
+		protocolKey:      protocolHTTP,
+		batchSizeKey:     "1",
+		batchIntervalKey: "10ms",
+	})
+	logMessages(t, l, "one")
+
+	// The record is kept when the export fails, and exported again.
+	requests := c.wait(t, 1)
+	assert.NilError(t, l.Close())
+	assert.Assert(t, is.Len(requests[0].records, 1))
+	assert.Check(t, is.Equal(requests[0].records[0].body, "one"))
+}
+
+func TestLogQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	l := newTestLogger(t, map[string]string{
+		endpointKey:      srv.URL,
+		protocolKey:      protocolHTTP,
+		batchSizeKey:     "1",
+		batchIntervalKey: "10ms",
+		timeoutKey:       "100ms",
+	})
+
+	// The export of the first record is blocked, and the following records
+	// are dropped once the queue is full, instead of blocking.
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		msg := logger.NewMessage()
+		msg.Line = append(msg.Line, "line"...)
+		msg.Timestamp = time.Now()
+		err = l.Log(msg)
+	}
+	assert.Check(t, is.ErrorContains(err, "too many log records waiting to be exported"))
+
+	closed := make(chan error)
+	go func() { closed <- l.Close() }()
+	select {
+	case err := <-closed:
+		assert.Check(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the logger to be closed")
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	valid := []map[string]string{
+		{},
+		{endpointKey: "https://collector:4317", tlsSkipVerifyKey: "true"},
+		{endpointKey: "http://collector:4318/otlp", protocolKey: protocolHTTP},
+		{headersKey: "a=b, c=d", resourceAttributesKey: "env=prod", batchSizeKey: "10", batchIntervalKey: "5s", timeoutKey: "1m"},
+		{"tag": "{{.Name}}", "labels": "a", "labels-regex": "^a", "env": "A", "env-regex": "^A"},
+	}
+	for _, cfg := range valid {
+		assert.Check(t, ValidateLogOpt(cfg), "%v", cfg)
+	}
+
+	invalid := []map[string]string{
+		{"max-size": "1m"},
+		{protocolKey: "http/json"},
+		{endpointKey: "collector:4317"},
+		{endpointKey: "http://collector:4317/v1/logs"},
+		{endpointKey: "http://collector:4317", tlsSkipVerifyKey: "true"},
+		{endpointKey: "https://collector:4317", tlsSkipVerifyKey: "maybe"},
+		{headersKey: "a"},
+		{resourceAttributesKey: "=b"},
+		{batchSizeKey: "0"},
+		{batchIntervalKey: "1"},
+		{timeoutKey: "-1s"},
+	}
+	for _, cfg := range invalid {
+		assert.Check(t, ValidateLogOpt(cfg) != nil, "%v", cfg)
+	}
+}
+
+func TestParseConfigEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		cfg      map[string]string
+		expected string
+	}{
+		{cfg: map[string]string{}, expected: "localhost:4317"},
+		{cfg: map[string]string{endpointKey: "https://collector"}, expected: "collector:4317"},
+		{cfg: map[string]string{protocolKey: protocolHTTP}, expected: "http://localhost:4318/v1/logs"},
+		{cfg: map[string]string{protocolKey: protocolHTTP, endpointKey: "https://collector/otlp/"}, expected: "https://collector/otlp/v1/logs"},
+	} {
+		cfg, err := parseConfig(tc.cfg)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(cfg.endpoint, tc.expected))
+	}
+}
@@ -2,12 +2,15 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"context"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/backend"
 	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	timetypes "github.com/docker/docker/api/types/time"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/logger"
@@ -89,11 +92,17 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 		until = time.Unix(s, n)
 	}
 
+	filter, err := logFilter(config.Filters)
+	if err != nil {
+		return nil, false, err
+	}
+
 	readConfig := logger.ReadConfig{
 		Since:  since,
 		Until:  until,
 		Tail:   tailLines,
 		Follow: follow,
+		Filter: filter,
 	}
 
 	logs := logReader.ReadLogs(readConfig)
@@ -160,6 +169,66 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 	return messageChan, ctr.Config.Tty, nil
 }
 
+var acceptedLogFilterTags = map[string]bool{
+	"regexp":           true,
+	"contains":         true,
+	"exclude-regexp":   true,
+	"exclude-contains": true,
+	"attr":             true,
+}
+
+// logFilter converts the filters of a logs request to a logger.MessageFilter.
+// It returns nil if no filters are set.
+func logFilter(args filters.Args) (*logger.MessageFilter, error) {
+	if args.Len() == 0 {
+		return nil, nil
+	}
+	if err := args.Validate(acceptedLogFilterTags); err != nil {
+		return nil, err
+	}
+
+	var f logger.MessageFilter
+	compile := func(key string, quote bool) ([]*regexp.Regexp, error) {
+		var res []*regexp.Regexp
+		for _, v := range args.Get(key) {
+			if quote {
+				v = regexp.QuoteMeta(v)
+			}
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return nil, errdefs.InvalidParameter(errors.Wrapf(err, "invalid filter '%s'", key))
+			}
+			res = append(res, re)
+		}
+		return res, nil
+	}
+	for _, key := range []string{"regexp", "contains"} {
+		res, err := compile(key, key == "contains")
+		if err != nil {
+			return nil, err
+		}
+		f.Include = append(f.Include, res...)
+	}
+	for _, key := range []string{"exclude-regexp", "exclude-contains"} {
+		res, err := compile(key, key == "exclude-contains")
+		if err != nil {
+			return nil, err
+		}
+		f.Exclude = append(f.Exclude, res...)
+	}
+	for _, attr := range args.Get("attr") {
+		k, v, _ := strings.Cut(attr, "=")
+		if k == "" {
+			return nil, errdefs.InvalidParameter(errors.Errorf("invalid filter 'attr=%s'", attr))
+		}
+		if f.Attrs == nil {
+			f.Attrs = make(map[string]string)
+		}
+		f.Attrs[k] = v
+	}
+	return &f, nil
+}
+
 func (daemon *Daemon) getLogger(container *container.Container) (l logger.Logger, created bool, err error) {
 	container.Lock()
 	if container.State.Running {
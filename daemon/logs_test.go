@@ -3,7 +3,10 @@ package daemon // import "github.com/docker/docker/daemon"
 import (
 	"testing"
 
+	"github.com/docker/docker/api/types/backend"
 	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/daemon/logger"
 )
 
 func TestMergeAndVerifyLogConfigNilConfig(t *testing.T) {
@@ -13,3 +16,44 @@ func TestMergeAndVerifyLogConfigNilConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestLogFilter(t *testing.T) {
+	f, err := logFilter(filters.NewArgs())
+	if err != nil || f != nil {
+		t.Fatalf("expected no filter, got %+v, %v", f, err)
+	}
+
+	f, err = logFilter(filters.NewArgs(
+		filters.Arg("regexp", `^ERROR`),
+		filters.Arg("contains", "a.b"),
+		filters.Arg("exclude-contains", "health"),
+		filters.Arg("attr", "tier=web"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs := []backend.LogAttr{{Key: "tier", Value: "web"}}
+	for line, expected := range map[string]bool{
+		"ERROR: failed":        true,
+		"request a.b":          true,
+		"request axb":          false,
+		"ERROR: health failed": false,
+	} {
+		if actual := f.Match(&logger.Message{Line: []byte(line), Attrs: attrs}); actual != expected {
+			t.Errorf("expected match of %q to be %v, got %v", line, expected, actual)
+		}
+	}
+	if f.Match(&logger.Message{Line: []byte("ERROR: failed")}) {
+		t.Error("expected a message without attributes not to match")
+	}
+
+	for _, args := range []filters.Args{
+		filters.NewArgs(filters.Arg("regexp", "(")),
+		filters.NewArgs(filters.Arg("attr", "=web")),
+		filters.NewArgs(filters.Arg("label", "tier")),
+	} {
+		if _, err := logFilter(args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package daemon // import "github.com/docker/docker/daemon"
 import (
 	"sync"
 
+	"github.com/docker/docker/daemon/stats"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/plugingetter"
 	"github.com/docker/docker/pkg/plugins"
@@ -109,6 +110,15 @@ func (ctr *stateCounter) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.GaugeValue, float64(stopped), "stopped")
 }
 
+// registerContainerMetrics registers the collector of the per-container
+// resource metrics, which publishes the stats sampled for running containers.
+func (daemon *Daemon) registerContainerMetrics() error {
+	ns := metrics.NewNamespace("engine", "container", nil)
+	daemon.containerMetrics = stats.NewMetrics(daemon.statsCollector, ns)
+	ns.Add(daemon.containerMetrics)
+	return prometheus.Register(ns)
+}
+
 func (daemon *Daemon) cleanupMetricsPlugins() {
 	ls := daemon.PluginStore.GetAllManagedPluginsByCap(metricsPluginType)
 	var wg sync.WaitGroup
@@ -22,6 +22,14 @@ func (daemon *Daemon) setStateCounter(c *container.Container) {
 	default:
 		stateCtr.set(c.ID, "stopped")
 	}
+
+	if daemon.containerMetrics != nil {
+		if state := c.StateString(); state == "running" || state == "paused" {
+			daemon.containerMetrics.Watch(c)
+		} else {
+			daemon.containerMetrics.Unwatch(c)
+		}
+	}
 }
 
 func (daemon *Daemon) handleContainerExit(c *container.Container, e *libcontainerdtypes.EventInfo) error {
@@ -104,7 +112,7 @@ func (daemon *Daemon) handleContainerExit(c *container.Container, e *libcontaine
 				// But containerStart will use daemon.netController segment.
 				// So to avoid panic at startup process, here must wait util daemon restore done.
 				daemon.waitForStartupDone()
-				if err = daemon.containerStart(c, "", "", false); err != nil {
+				if err = daemon.containerStart(context.Background(), c, "", "", false); err != nil {
 					logrus.Debugf("failed to restart container: %+v", err)
 				}
 			}
@@ -12,17 +12,31 @@ import (
 
 func (daemon *Daemon) prepareMountPoints(container *container.Container) error {
 	for _, config := range container.MountPoints {
-		if err := daemon.lazyInitializeVolume(container.ID, config); err != nil {
+		if err := daemon.lazyInitializeVolume(container, config); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// restoreVolumeMounts records the mounts of the volumes of a container which
+// kept running while the daemon was down (live-restore).
+func (daemon *Daemon) restoreVolumeMounts(container *container.Container) {
+	for _, m := range container.MountPoints {
+		if len(m.Driver) > 0 && m.ID != "" {
+			daemon.volumes.RestoreMount(m.Name)
+		}
+	}
+}
+
 func (daemon *Daemon) removeMountPoints(container *container.Container, rm bool) error {
 	var rmErrors []string
 	ctx := context.TODO()
 	for _, m := range container.MountPoints {
+		if m.Type == mounttypes.TypeImage {
+			daemon.releaseMountPoint(ctx, container, m)
+			continue
+		}
 		if m.Type != mounttypes.TypeVolume || m.Volume == nil {
 			continue
 		}
@@ -353,6 +353,14 @@ func (daemon *Daemon) createNetwork(create types.NetworkCreateRequest, id string
 		nwOptions = append(nwOptions, libnetwork.NetworkOptionConfigFrom(create.ConfigFrom.Network))
 	}
 
+	if create.DNS != nil {
+		dnsConfig, err := getDNSConfig(create.DNS)
+		if err != nil {
+			return nil, err
+		}
+		nwOptions = append(nwOptions, libnetwork.NetworkOptionDNS(dnsConfig))
+	}
+
 	if agent && driver == "overlay" {
 		nodeIP, exists := daemon.GetAttachmentStore().GetIPForNetwork(id)
 		if !exists {
@@ -428,6 +436,39 @@ func getIpamConfig(data []network.IPAMConfig) ([]*libnetwork.IpamConf, []*libnet
 	return ipamV4Cfg, ipamV6Cfg, nil
 }
 
+// getDNSConfig validates the DNS configuration of a network, and returns it
+// in its libnetwork form.
+func getDNSConfig(data *network.DNSConfig) (libnetwork.DNSConfig, error) {
+	var dnsConfig libnetwork.DNSConfig
+	for _, ns := range data.Nameservers {
+		ip, err := opts.ValidateIPAddress(ns)
+		if err != nil {
+			return dnsConfig, errdefs.InvalidParameter(errors.Wrap(err, "invalid DNS nameserver"))
+		}
+		dnsConfig.Servers = append(dnsConfig.Servers, ip)
+	}
+	for _, s := range data.Search {
+		domain, err := opts.ValidateDNSSearch(s)
+		if err != nil {
+			return dnsConfig, errdefs.InvalidParameter(errors.Wrap(err, "invalid DNS search domain"))
+		}
+		dnsConfig.Search = append(dnsConfig.Search, domain)
+	}
+	for _, o := range data.Options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			return dnsConfig, errdefs.InvalidParameter(errors.New("invalid DNS option: empty option"))
+		}
+		if strings.HasPrefix(o, "ndots:") {
+			if n, err := strconv.Atoi(strings.TrimPrefix(o, "ndots:")); err != nil || n < 0 {
+				return dnsConfig, errdefs.InvalidParameter(errors.Errorf("invalid DNS option %s: ndots must be a non-negative number", o))
+			}
+		}
+		dnsConfig.Options = append(dnsConfig.Options, o)
+	}
+	return dnsConfig, nil
+}
+
 // UpdateContainerServiceConfig updates a service configuration.
 func (daemon *Daemon) UpdateContainerServiceConfig(containerName string, serviceConfig *clustertypes.ServiceConfig) error {
 	ctr, err := daemon.GetContainer(containerName)
@@ -516,6 +557,54 @@ func (daemon *Daemon) DeleteNetwork(networkID string) error {
 	return daemon.deleteNetwork(n, false)
 }
 
+// GetNetworkDNSRecords returns the static DNS records of a network.
+func (daemon *Daemon) GetNetworkDNSRecords(networkID string) ([]network.DNSRecord, error) {
+	n, err := daemon.GetNetworkByID(networkID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find network by ID")
+	}
+	lnRecords := n.Info().DNSRecords()
+	records := make([]network.DNSRecord, 0, len(lnRecords))
+	for _, r := range lnRecords {
+		records = append(records, network.DNSRecord{
+			Name:  r.Name,
+			Type:  r.Type,
+			Value: r.Value,
+			TTL:   r.TTL,
+		})
+	}
+	return records, nil
+}
+
+// SetNetworkDNSRecords replaces the static DNS records of a network. The
+// records are served to the containers connected to the network without
+// restarting them.
+func (daemon *Daemon) SetNetworkDNSRecords(networkID string, records []network.DNSRecord) error {
+	n, err := daemon.GetNetworkByID(networkID)
+	if err != nil {
+		return errors.Wrap(err, "could not find network by ID")
+	}
+	if runconfig.IsPreDefinedNetwork(n.Name()) {
+		return errdefs.Forbidden(fmt.Errorf("static DNS records are not supported on pre-defined network %s", n.Name()))
+	}
+	lnRecords := make([]libnetwork.DNSRecord, 0, len(records))
+	for _, r := range records {
+		lnRecords = append(lnRecords, libnetwork.DNSRecord{
+			Name:  r.Name,
+			Type:  r.Type,
+			Value: r.Value,
+			TTL:   r.TTL,
+		})
+	}
+	if err := n.SetDNSRecords(lnRecords); err != nil {
+		if _, ok := err.(networktypes.BadRequestError); ok {
+			return errdefs.InvalidParameter(err)
+		}
+		return err
+	}
+	return nil
+}
+
 func (daemon *Daemon) deleteNetwork(nw libnetwork.Network, dynamic bool) error {
 	if runconfig.IsPreDefinedNetwork(nw.Name()) && !dynamic {
 		err := fmt.Errorf("%s is a pre-defined network and cannot be removed", nw.Name())
@@ -609,6 +698,14 @@ func buildNetworkResource(nw libnetwork.Network) types.NetworkResource {
 		r.ConfigFrom = network.ConfigReference{Network: cn}
 	}
 
+	if dc := info.DNSConfig(); dc != nil {
+		r.DNS = &network.DNSConfig{
+			Nameservers: dc.Servers,
+			Search:      dc.Search,
+			Options:     dc.Options,
+		}
+	}
+
 	peers := info.Peers()
 	if len(peers) != 0 {
 		r.Peers = buildPeerInfoResources(peers)
@@ -635,7 +732,13 @@ func buildDetailedNetworkResources(r *types.NetworkResource, nw libnetwork.Netwo
 			key = sb.ContainerID()
 		}
 
-		r.Containers[key] = buildEndpointResource(tmpID, e.Name(), ei)
+		er := buildEndpointResource(tmpID, e.Name(), ei)
+		// Only the bridge driver supports bandwidth limits, do not query the
+		// operational data of the endpoints of other, possibly remote, drivers.
+		if nw.Type() == "bridge" {
+			er.Bandwidth = getEndpointBandwidth(e)
+		}
+		r.Containers[key] = er
 	}
 	if !verbose {
 		return
@@ -757,6 +860,25 @@ func buildEndpointResource(id string, name string, info libnetwork.EndpointInfo)
 	return er
 }
 
+// getEndpointBandwidth returns the bandwidth limits the driver applies to the
+// endpoint, if any.
+func getEndpointBandwidth(ep libnetwork.Endpoint) *network.EndpointBandwidth {
+	driverInfo, err := ep.DriverInfo()
+	if err != nil || driverInfo == nil {
+		return nil
+	}
+	bw, ok := driverInfo[netlabel.Bandwidth].(*networktypes.Bandwidth)
+	if !ok || bw == nil {
+		return nil
+	}
+	return &network.EndpointBandwidth{
+		IngressRate:  bw.IngressRate,
+		IngressBurst: bw.IngressBurst,
+		EgressRate:   bw.EgressRate,
+		EgressBurst:  bw.EgressBurst,
+	}
+}
+
 // clearAttachableNetworks removes the attachable networks
 // after disconnecting any connected container
 func (daemon *Daemon) clearAttachableNetworks() {
@@ -0,0 +1,48 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestGetDNSConfig(t *testing.T) {
+	dnsConfig, err := getDNSConfig(&network.DNSConfig{
+		Nameservers: []string{" 10.0.0.53", "2001:db8::53"},
+		Search:      []string{"example.com", "."},
+		Options:     []string{"ndots:2", "rotate"},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(dnsConfig.Servers, []string{"10.0.0.53", "2001:db8::53"}))
+	assert.Check(t, is.DeepEqual(dnsConfig.Search, []string{"example.com", "."}))
+	assert.Check(t, is.DeepEqual(dnsConfig.Options, []string{"ndots:2", "rotate"}))
+
+	for _, tc := range []struct {
+		config      network.DNSConfig
+		expectedErr string
+	}{
+		{
+			config:      network.DNSConfig{Nameservers: []string{"ns.example.com"}},
+			expectedErr: "invalid DNS nameserver: ns.example.com is not an ip address",
+		},
+		{
+			config:      network.DNSConfig{Search: []string{"-example.com"}},
+			expectedErr: "invalid DNS search domain: -example.com is not a valid domain",
+		},
+		{
+			config:      network.DNSConfig{Options: []string{"ndots:-1"}},
+			expectedErr: "invalid DNS option ndots:-1: ndots must be a non-negative number",
+		},
+		{
+			config:      network.DNSConfig{Options: []string{" "}},
+			expectedErr: "invalid DNS option: empty option",
+		},
+	} {
+		_, err := getDNSConfig(&tc.config)
+		assert.Check(t, is.Error(err, tc.expectedErr))
+		assert.Check(t, errdefs.IsInvalidParameter(err))
+	}
+}
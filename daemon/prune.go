@@ -2,16 +2,14 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"context"
-	"fmt"
 	"regexp"
 	"strconv"
 	"sync/atomic"
-	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
-	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/daemon/internal/prunefilters"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/libnetwork"
 	"github.com/docker/docker/runconfig"
@@ -52,7 +50,7 @@ func (daemon *Daemon) ContainersPrune(ctx context.Context, pruneFilters filters.
 		return nil, err
 	}
 
-	until, err := getUntilFromPruneFilters(pruneFilters)
+	until, err := prunefilters.GetUntil(pruneFilters)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +68,7 @@ func (daemon *Daemon) ContainersPrune(ctx context.Context, pruneFilters filters.
 			if !until.IsZero() && c.Created.After(until) {
 				continue
 			}
-			if !matchLabels(pruneFilters, c.Config.Labels) {
+			if !prunefilters.MatchLabels(pruneFilters, c.Config.Labels) {
 				continue
 			}
 			cSize, _ := daemon.imageService.GetContainerLayerSize(c.ID)
@@ -96,7 +94,7 @@ func (daemon *Daemon) ContainersPrune(ctx context.Context, pruneFilters filters.
 func (daemon *Daemon) localNetworksPrune(ctx context.Context, pruneFilters filters.Args) *types.NetworksPruneReport {
 	rep := &types.NetworksPruneReport{}
 
-	until, _ := getUntilFromPruneFilters(pruneFilters)
+	until, _ := prunefilters.GetUntil(pruneFilters)
 
 	// When the function returns true, the walk will stop.
 	l := func(nw libnetwork.Network) bool {
@@ -112,7 +110,7 @@ func (daemon *Daemon) localNetworksPrune(ctx context.Context, pruneFilters filte
 		if !until.IsZero() && nw.Info().Created().After(until) {
 			return false
 		}
-		if !matchLabels(pruneFilters, nw.Info().Labels()) {
+		if !prunefilters.MatchLabels(pruneFilters, nw.Info().Labels()) {
 			return false
 		}
 		nwName := nw.Name()
@@ -137,7 +135,7 @@ func (daemon *Daemon) localNetworksPrune(ctx context.Context, pruneFilters filte
 func (daemon *Daemon) clusterNetworksPrune(ctx context.Context, pruneFilters filters.Args) (*types.NetworksPruneReport, error) {
 	rep := &types.NetworksPruneReport{}
 
-	until, _ := getUntilFromPruneFilters(pruneFilters)
+	until, _ := prunefilters.GetUntil(pruneFilters)
 
 	cluster := daemon.GetCluster()
 
@@ -162,7 +160,7 @@ func (daemon *Daemon) clusterNetworksPrune(ctx context.Context, pruneFilters fil
 			if !until.IsZero() && nw.Created.After(until) {
 				continue
 			}
-			if !matchLabels(pruneFilters, nw.Labels) {
+			if !prunefilters.MatchLabels(pruneFilters, nw.Labels) {
 				continue
 			}
 			// https://github.com/docker/docker/issues/24186
@@ -196,7 +194,7 @@ func (daemon *Daemon) NetworksPrune(ctx context.Context, pruneFilters filters.Ar
 		return nil, err
 	}
 
-	if _, err := getUntilFromPruneFilters(pruneFilters); err != nil {
+	if _, err := prunefilters.GetUntil(pruneFilters); err != nil {
 		return nil, err
 	}
 
@@ -219,38 +217,3 @@ func (daemon *Daemon) NetworksPrune(ctx context.Context, pruneFilters filters.Ar
 	})
 	return rep, nil
 }
-
-func getUntilFromPruneFilters(pruneFilters filters.Args) (time.Time, error) {
-	until := time.Time{}
-	if !pruneFilters.Contains("until") {
-		return until, nil
-	}
-	untilFilters := pruneFilters.Get("until")
-	if len(untilFilters) > 1 {
-		return until, fmt.Errorf("more than one until filter specified")
-	}
-	ts, err := timetypes.GetTimestamp(untilFilters[0], time.Now())
-	if err != nil {
-		return until, err
-	}
-	seconds, nanoseconds, err := timetypes.ParseTimestamps(ts, 0)
-	if err != nil {
-		return until, err
-	}
-	until = time.Unix(seconds, nanoseconds)
-	return until, nil
-}
-
-func matchLabels(pruneFilters filters.Args, labels map[string]string) bool {
-	if !pruneFilters.MatchKVList("label", labels) {
-		return false
-	}
-	// By default MatchKVList will return true if field (like 'label!') does not exist
-	// So we have to add additional Contains("label!") check
-	if pruneFilters.Contains("label!") {
-		if pruneFilters.MatchKVList("label!", labels) {
-			return false
-		}
-	}
-	return true
-}
@@ -11,10 +11,12 @@ import (
 	"github.com/docker/docker/errdefs"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig, checkpoint string, checkpointDir string) error {
+func (daemon *Daemon) ContainerStart(ctx context.Context, name string, hostConfig *containertypes.HostConfig, checkpoint string, checkpointDir string) error {
 	if checkpoint != "" && !daemon.HasExperimental() {
 		return errdefs.InvalidParameter(errors.New("checkpoint is only supported in experimental mode"))
 	}
@@ -91,14 +93,26 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 			return errdefs.InvalidParameter(err)
 		}
 	}
-	return daemon.containerStart(ctr, checkpoint, checkpointDir, true)
+	if err := daemon.startDependencies(ctx, ctr); err != nil {
+		return err
+	}
+	return daemon.containerStart(ctx, ctr, checkpoint, checkpointDir, true)
 }
 
 // containerStart prepares the container to run by setting up everything the
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
 // begin running.
-func (daemon *Daemon) containerStart(container *container.Container, checkpoint string, checkpointDir string, resetRestartManager bool) (err error) {
+func (daemon *Daemon) containerStart(ctx context.Context, container *container.Container, checkpoint string, checkpointDir string, resetRestartManager bool) (err error) {
+	_, span := tracer.Start(ctx, "daemon.containerStart", trace.WithAttributes(
+		attribute.String("container.id", container.ID),
+	))
+	defer func() { endSpan(span, err) }()
+
+	// Starting the container is not canceled with the request it is started
+	// for, but the operations are still traced as part of the request.
+	ctx = trace.ContextWithSpan(context.Background(), span)
+
 	start := time.Now()
 	container.Lock()
 	defer container.Unlock()
@@ -146,7 +160,7 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 		return err
 	}
 
-	if err := daemon.initializeNetworking(container); err != nil {
+	if err := daemon.initializeNetworking(ctx, container); err != nil {
 		return err
 	}
 
@@ -176,9 +190,8 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 		return err
 	}
 
-	ctx := context.TODO()
-
-	err = daemon.containerd.Create(ctx, container.ID, spec, shim, createOptions)
+	createCtx, createSpan := tracer.Start(ctx, "containerd.Create")
+	err = daemon.containerd.Create(createCtx, container.ID, spec, shim, createOptions)
 	if err != nil {
 		if errdefs.IsConflict(err) {
 			logrus.WithError(err).WithField("container", container.ID).Error("Container not cleaned up from containerd from previous run")
@@ -187,19 +200,23 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 			if err := daemon.containerd.Delete(ctx, container.ID); err != nil && !errdefs.IsNotFound(err) {
 				logrus.WithError(err).WithField("container", container.ID).Error("Error cleaning up stale containerd container object")
 			}
-			err = daemon.containerd.Create(ctx, container.ID, spec, shim, createOptions)
+			err = daemon.containerd.Create(createCtx, container.ID, spec, shim, createOptions)
 		}
 		if err != nil {
+			endSpan(createSpan, err)
 			return translateContainerdStartErr(container.Path, container.SetExitCode, err)
 		}
 	}
+	createSpan.End()
 
 	// TODO(mlaventure): we need to specify checkpoint options here
-	pid, err := daemon.containerd.Start(context.Background(), container.ID, checkpointDir,
+	startCtx, startSpan := tracer.Start(ctx, "containerd.Start")
+	pid, err := daemon.containerd.Start(startCtx, container.ID, checkpointDir,
 		container.StreamConfig.Stdin() != nil || container.Config.Tty,
 		container.InitializeStdio)
+	endSpan(startSpan, err)
 	if err != nil {
-		if err := daemon.containerd.Delete(context.Background(), container.ID); err != nil {
+		if err := daemon.containerd.Delete(ctx, container.ID); err != nil {
 			logrus.WithError(err).WithField("container", container.ID).
 				Error("failed to delete failed start container")
 		}
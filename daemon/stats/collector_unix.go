@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
@@ -19,6 +20,9 @@ const (
 	// https://github.com/containerd/cgroups/pull/12
 	clockTicksPerSecond  = 100
 	nanoSecondsPerSecond = 1e9
+
+	// cpuUsageUnit is the unit of the cpu usage of containers.
+	cpuUsageUnit = time.Nanosecond
 )
 
 // getSystemCPUUsage returns the host system's cpu usage in
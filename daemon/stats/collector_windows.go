@@ -1,5 +1,11 @@
 package stats // import "github.com/docker/docker/daemon/stats"
 
+import "time"
+
+// cpuUsageUnit is the unit of the cpu usage of containers, as reported by
+// HCS.
+const cpuUsageUnit = 100 * time.Nanosecond
+
 // getSystemCPUUsage returns the host system's cpu usage in
 // nanoseconds. An error is returned if the format of the underlying
 // file does not match. This is a no-op on Windows.
@@ -0,0 +1,177 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels of the compose project and service of a container.
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// containerLabels are the labels identifying the container of a metric.
+var containerLabels = []string{"id", "name", "image", "compose_project", "compose_service"}
+
+// Metrics is a prometheus collector which publishes the resource stats of
+// watched containers. The stats are those sampled by the stats Collector for
+// its subscribers, so that no additional sampling is done.
+type Metrics struct {
+	collector  *Collector
+	mu         sync.Mutex
+	containers map[string]*containerMetrics
+
+	cpuUsage        *prometheus.Desc
+	memoryUsage     *prometheus.Desc
+	memoryLimit     *prometheus.Desc
+	pids            *prometheus.Desc
+	networkRxBytes  *prometheus.Desc
+	networkTxBytes  *prometheus.Desc
+	networkRxPacket *prometheus.Desc
+	networkTxPacket *prometheus.Desc
+	blkioRead       *prometheus.Desc
+	blkioWrite      *prometheus.Desc
+}
+
+type containerMetrics struct {
+	container *container.Container
+	labels    []string
+	updates   chan interface{}
+	stats     *types.StatsJSON // the last sample, or nil if the container has no stats
+}
+
+// NewMetrics creates a Metrics collector for the stats sampled by collector,
+// with the metrics in the ns namespace.
+func NewMetrics(collector *Collector, ns *metrics.Namespace) *Metrics {
+	networkLabels := append(append([]string{}, containerLabels...), "interface")
+	return &Metrics{
+		collector:       collector,
+		containers:      make(map[string]*containerMetrics),
+		cpuUsage:        ns.NewDesc("cpu_usage_seconds", "The total cpu time consumed by the container", metrics.Total, containerLabels...),
+		memoryUsage:     ns.NewDesc("memory_usage", "The memory usage of the container", metrics.Bytes, containerLabels...),
+		memoryLimit:     ns.NewDesc("memory_limit", "The memory limit of the container", metrics.Bytes, containerLabels...),
+		pids:            ns.NewDesc("pids", "The number of processes and threads of the container", metrics.Unit("current"), containerLabels...),
+		networkRxBytes:  ns.NewDesc("network_receive_bytes", "The number of bytes received by the container", metrics.Total, networkLabels...),
+		networkTxBytes:  ns.NewDesc("network_transmit_bytes", "The number of bytes transmitted by the container", metrics.Total, networkLabels...),
+		networkRxPacket: ns.NewDesc("network_receive_packets", "The number of packets received by the container", metrics.Total, networkLabels...),
+		networkTxPacket: ns.NewDesc("network_transmit_packets", "The number of packets transmitted by the container", metrics.Total, networkLabels...),
+		blkioRead:       ns.NewDesc("blkio_read_bytes", "The number of bytes read from block devices by the container", metrics.Total, containerLabels...),
+		blkioWrite:      ns.NewDesc("blkio_write_bytes", "The number of bytes written to block devices by the container", metrics.Total, containerLabels...),
+	}
+}
+
+// Watch starts publishing the metrics of the container. It is a no-op if the
+// container is already watched.
+func (m *Metrics) Watch(c *container.Container) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.containers[c.ID]; ok {
+		return
+	}
+
+	cm := &containerMetrics{
+		container: c,
+		labels:    []string{c.ID, strings.TrimPrefix(c.Name, "/"), "", "", ""},
+		updates:   m.collector.Collect(c),
+	}
+	if c.Config != nil {
+		cm.labels[2] = c.Config.Image
+		cm.labels[3] = c.Config.Labels[composeProjectLabel]
+		cm.labels[4] = c.Config.Labels[composeServiceLabel]
+	}
+	m.containers[c.ID] = cm
+
+	go func() {
+		for v := range cm.updates {
+			s := v.(types.StatsJSON)
+			m.mu.Lock()
+			if s.Read.IsZero() {
+				// The container is not running.
+				cm.stats = nil
+			} else {
+				cm.stats = &s
+			}
+			m.mu.Unlock()
+		}
+
+		// The channel is closed when the container is unwatched, or when
+		// the collection of its stats is stopped.
+		m.mu.Lock()
+		if m.containers[c.ID] == cm {
+			delete(m.containers, c.ID)
+		}
+		m.mu.Unlock()
+	}()
+}
+
+// Unwatch stops publishing the metrics of the container.
+func (m *Metrics) Unwatch(c *container.Container) {
+	m.mu.Lock()
+	cm, ok := m.containers[c.ID]
+	delete(m.containers, c.ID)
+	m.mu.Unlock()
+	if ok {
+		m.collector.Unsubscribe(cm.container, cm.updates)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		m.cpuUsage, m.memoryUsage, m.memoryLimit, m.pids,
+		m.networkRxBytes, m.networkTxBytes, m.networkRxPacket, m.networkTxPacket,
+		m.blkioRead, m.blkioWrite,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, cm := range m.containers {
+		s := cm.stats
+		if s == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(m.cpuUsage, prometheus.CounterValue, float64(s.CPUStats.CPUUsage.TotalUsage)*cpuUsageUnit.Seconds(), cm.labels...)
+		ch <- prometheus.MustNewConstMetric(m.memoryUsage, prometheus.GaugeValue, float64(s.MemoryStats.Usage), cm.labels...)
+		if s.MemoryStats.Limit != 0 {
+			ch <- prometheus.MustNewConstMetric(m.memoryLimit, prometheus.GaugeValue, float64(s.MemoryStats.Limit), cm.labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(m.pids, prometheus.GaugeValue, float64(s.PidsStats.Current), cm.labels...)
+
+		for iface, n := range s.Networks {
+			labels := append(append([]string{}, cm.labels...), iface)
+			ch <- prometheus.MustNewConstMetric(m.networkRxBytes, prometheus.CounterValue, float64(n.RxBytes), labels...)
+			ch <- prometheus.MustNewConstMetric(m.networkTxBytes, prometheus.CounterValue, float64(n.TxBytes), labels...)
+			ch <- prometheus.MustNewConstMetric(m.networkRxPacket, prometheus.CounterValue, float64(n.RxPackets), labels...)
+			ch <- prometheus.MustNewConstMetric(m.networkTxPacket, prometheus.CounterValue, float64(n.TxPackets), labels...)
+		}
+
+		var read, write uint64
+		for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+			// The operations are capitalized with cgroup v1, and lowercase
+			// with cgroup v2.
+			switch strings.ToLower(e.Op) {
+			case "read":
+				read += e.Value
+			case "write":
+				write += e.Value
+			}
+		}
+		// Windows reports the disk I/O in the storage stats instead.
+		read += s.StorageStats.ReadSizeBytes
+		write += s.StorageStats.WriteSizeBytes
+		ch <- prometheus.MustNewConstMetric(m.blkioRead, prometheus.CounterValue, float64(read), cm.labels...)
+		ch <- prometheus.MustNewConstMetric(m.blkioWrite, prometheus.CounterValue, float64(write), cm.labels...)
+	}
+}
@@ -0,0 +1,94 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+type fakeSupervisor struct{}
+
+func (fakeSupervisor) GetContainerStats(c *container.Container) (*types.StatsJSON, error) {
+	s := &types.StatsJSON{Name: c.Name, ID: c.ID}
+	s.Read = time.Now()
+	s.CPUStats.CPUUsage.TotalUsage = uint64(2 * time.Second / cpuUsageUnit)
+	s.MemoryStats.Usage = 1024
+	s.Networks = map[string]types.NetworkStats{"eth0": {RxBytes: 10, TxBytes: 20}}
+	s.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "Read", Value: 100},
+		{Op: "read", Value: 1},
+		{Op: "write", Value: 200},
+	}
+	return s, nil
+}
+
+func gather(t *testing.T, reg *prometheus.Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+	families, err := reg.Gather()
+	assert.NilError(t, err)
+	m := make(map[string]*dto.MetricFamily)
+	for _, f := range families {
+		m[f.GetName()] = f
+	}
+	return m
+}
+
+func TestMetrics(t *testing.T) {
+	collector := NewCollector(fakeSupervisor{}, 10*time.Millisecond)
+	go collector.Run()
+
+	ns := metrics.NewNamespace("engine", "container", nil)
+	m := NewMetrics(collector, ns)
+	ns.Add(m)
+	reg := prometheus.NewRegistry()
+	assert.NilError(t, reg.Register(ns))
+
+	c := &container.Container{
+		ID:   "c1",
+		Name: "/web",
+		Config: &containertypes.Config{
+			Image:  "nginx",
+			Labels: map[string]string{composeProjectLabel: "shop", composeServiceLabel: "frontend"},
+		},
+	}
+	m.Watch(c)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if len(gather(t.(*testing.T), reg)) == 0 {
+			return poll.Continue("waiting for stats")
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+
+	families := gather(t, reg)
+	cpu := families["engine_container_cpu_usage_seconds_total"]
+	assert.Assert(t, cpu != nil)
+	assert.Check(t, is.Equal(cpu.Metric[0].GetCounter().GetValue(), 2.0))
+	labels := make(map[string]string)
+	for _, l := range cpu.Metric[0].Label {
+		labels[l.GetName()] = l.GetValue()
+	}
+	assert.Check(t, is.DeepEqual(labels, map[string]string{
+		"id":              "c1",
+		"name":            "web",
+		"image":           "nginx",
+		"compose_project": "shop",
+		"compose_service": "frontend",
+	}))
+	assert.Check(t, is.Equal(families["engine_container_memory_usage_bytes"].Metric[0].GetGauge().GetValue(), 1024.0))
+	assert.Check(t, families["engine_container_memory_limit_bytes"] == nil)
+	assert.Check(t, is.Equal(families["engine_container_network_transmit_bytes_total"].Metric[0].GetCounter().GetValue(), 20.0))
+	assert.Check(t, is.Equal(families["engine_container_blkio_read_bytes_total"].Metric[0].GetCounter().GetValue(), 101.0))
+	assert.Check(t, is.Equal(families["engine_container_blkio_write_bytes_total"].Metric[0].GetCounter().GetValue(), 200.0))
+
+	m.Unwatch(c)
+	assert.Check(t, is.Len(gather(t, reg), 0))
+}
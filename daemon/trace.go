@@ -0,0 +1,21 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records the spans of daemon operations. It is a no-op tracer if
+// tracing is not enabled.
+var tracer = otel.Tracer("github.com/docker/docker/daemon")
+
+// endSpan ends the span of an operation, recording the error the operation
+// failed with, if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -68,10 +68,7 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 		// clean up the container mountpoints once return with error
 		if retErr != nil {
 			for _, m := range mountPoints {
-				if m.Volume == nil {
-					continue
-				}
-				daemon.volumes.Release(ctx, m.Volume.Name(), container.ID)
+				daemon.releaseMountPoint(ctx, container, m)
 			}
 		}
 	}()
@@ -79,9 +76,8 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 	dereferenceIfExists := func(destination string) {
 		if v, ok := mountPoints[destination]; ok {
 			logrus.Debugf("Duplicate mount point '%s'", destination)
-			if v.Volume != nil {
-				daemon.volumes.Release(ctx, v.Volume.Name(), container.ID)
-			}
+			daemon.releaseMountPoint(ctx, container, v)
+			delete(mountPoints, destination)
 		}
 	}
 
@@ -115,7 +111,16 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 				CopyData:    false,
 			}
 
-			if len(cp.Source) == 0 {
+			if cp.Type == mounttypes.TypeImage {
+				// The layer of the image mount is named after the
+				// destination, so the mount it replaces is released first.
+				dereferenceIfExists(cp.Destination)
+				im, err := daemon.createImageMount(container, cp)
+				if err != nil {
+					return err
+				}
+				cp.Volume = im
+			} else if len(cp.Source) == 0 {
 				v, err := daemon.volumes.Get(ctx, cp.Name, volumeopts.WithGetDriver(cp.Driver), volumeopts.WithGetReference(container.ID))
 				if err != nil {
 					return err
@@ -216,6 +221,20 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 			}
 		}
 
+		if mp.Type == mounttypes.TypeImage {
+			img, err := daemon.imageService.GetImage(cfg.Source, nil)
+			if err != nil {
+				return err
+			}
+			mp.Name = img.ID().String()
+			dereferenceIfExists(mp.Destination)
+			im, err := daemon.createImageMount(container, mp)
+			if err != nil {
+				return err
+			}
+			mp.Volume = im
+		}
+
 		if mp.Type == mounttypes.TypeBind && (cfg.BindOptions == nil || !cfg.BindOptions.CreateMountpoint) {
 			mp.SkipMountpointCreation = true
 		}
@@ -244,9 +263,13 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 
 // lazyInitializeVolume initializes a mountpoint's volume if needed.
 // This happens after a daemon restart.
-func (daemon *Daemon) lazyInitializeVolume(containerID string, m *volumemounts.MountPoint) error {
+func (daemon *Daemon) lazyInitializeVolume(c *container.Container, m *volumemounts.MountPoint) error {
+	if m.Type == mounttypes.TypeImage && m.Volume == nil {
+		m.Volume = daemon.newImageMount(c, m)
+		return nil
+	}
 	if len(m.Driver) > 0 && m.Volume == nil {
-		v, err := daemon.volumes.Get(context.TODO(), m.Name, volumeopts.WithGetDriver(m.Driver), volumeopts.WithGetReference(containerID))
+		v, err := daemon.volumes.Get(context.TODO(), m.Name, volumeopts.WithGetDriver(m.Driver), volumeopts.WithGetReference(c.ID))
 		if err != nil {
 			return err
 		}
@@ -255,6 +278,24 @@ func (daemon *Daemon) lazyInitializeVolume(containerID string, m *volumemounts.M
 	return nil
 }
 
+// releaseMountPoint releases the reference of the container to the volume of
+// a mount point, or the layer of an image mount.
+func (daemon *Daemon) releaseMountPoint(ctx context.Context, c *container.Container, m *volumemounts.MountPoint) {
+	if m.Type == mounttypes.TypeImage {
+		im, ok := m.Volume.(*imageMount)
+		if !ok {
+			im = daemon.newImageMount(c, m)
+		}
+		if err := im.release(); err != nil {
+			logrus.WithError(err).WithField("container", c.ID).Warnf("Error releasing mount of image %s", m.Name)
+		}
+		return
+	}
+	if m.Volume != nil {
+		daemon.volumes.Release(ctx, m.Volume.Name(), c.ID)
+	}
+}
+
 // VolumesService is used to perform volume operations
 func (daemon *Daemon) VolumesService() *service.VolumesService {
 	return daemon.volumes
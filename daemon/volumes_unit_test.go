@@ -1,9 +1,19 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/volume"
 	volumemounts "github.com/docker/docker/volume/mounts"
+	volumesservice "github.com/docker/docker/volume/service"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestParseVolumesFrom(t *testing.T) {
@@ -39,3 +49,98 @@ func TestParseVolumesFrom(t *testing.T) {
 		}
 	}
 }
+
+func TestVolumeSizeAfterContainerMount(t *testing.T) {
+	ctx := context.Background()
+	volumes, err := volumesservice.NewVolumeService(t.TempDir(), nil, idtools.IdentityMapping{}, nil)
+	assert.NilError(t, err)
+	defer volumes.Shutdown()
+
+	v, err := volumes.Create(ctx, "data", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	file := filepath.Join(v.Mountpoint, "sub", "file")
+	assert.NilError(t, os.Mkdir(filepath.Dir(file), 0755))
+	assert.NilError(t, os.WriteFile(file, make([]byte, 100), 0644))
+
+	size := func() int64 {
+		t.Helper()
+		vols, err := volumes.LocalVolumesSize(ctx)
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(vols, 1))
+		return vols[0].UsageData.Size
+	}
+	assert.Check(t, is.Equal(size(), int64(100)))
+
+	// Mount the volume the way it is mounted in a container.
+	mp := &volumemounts.MountPoint{
+		Type:        mounttypes.TypeVolume,
+		Name:        v.Name,
+		Driver:      v.Driver,
+		Destination: "/data",
+		RW:          true,
+		Volume:      &volumeWrapper{v: v, s: volumes},
+	}
+	_, err = mp.Setup("", idtools.Identity{}, nil)
+	assert.NilError(t, err)
+
+	// The size of a mounted volume is determined again, as its data may
+	// change without changing its data directory.
+	assert.NilError(t, os.WriteFile(file, make([]byte, 300), 0644))
+	assert.Check(t, is.Equal(size(), int64(300)))
+
+	assert.NilError(t, os.WriteFile(file, make([]byte, 500), 0644))
+	assert.NilError(t, mp.Cleanup())
+	assert.Check(t, is.Equal(size(), int64(500)))
+}
+
+func TestVolumeSizeAfterLiveRestore(t *testing.T) {
+	ctx := context.Background()
+	volumes, err := volumesservice.NewVolumeService(t.TempDir(), nil, idtools.IdentityMapping{}, nil)
+	assert.NilError(t, err)
+	defer volumes.Shutdown()
+	daemon := &Daemon{volumes: volumes}
+
+	v, err := volumes.Create(ctx, "data", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	file := filepath.Join(v.Mountpoint, "sub", "file")
+	assert.NilError(t, os.Mkdir(filepath.Dir(file), 0755))
+	assert.NilError(t, os.WriteFile(file, make([]byte, 100), 0644))
+
+	size := func() int64 {
+		t.Helper()
+		vols, err := volumes.LocalVolumesSize(ctx)
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(vols, 1))
+		return vols[0].UsageData.Size
+	}
+	assert.Check(t, is.Equal(size(), int64(100)))
+
+	// A container which kept running while the daemon was down has the ID
+	// of the mount of the volume by the previous daemon process, and the
+	// volume is not mounted again.
+	c := &container.Container{
+		ID: "restored",
+		MountPoints: map[string]*volumemounts.MountPoint{
+			"/data": {
+				Type:        mounttypes.TypeVolume,
+				Name:        v.Name,
+				Driver:      v.Driver,
+				Destination: "/data",
+				RW:          true,
+				ID:          "mount-id",
+			},
+		},
+	}
+	daemon.restoreVolumeMounts(c)
+	assert.NilError(t, daemon.prepareMountPoints(c))
+
+	assert.NilError(t, os.WriteFile(file, make([]byte, 300), 0644))
+	assert.Check(t, is.Equal(size(), int64(300)))
+
+	// The size is cached again once the container stopped.
+	assert.NilError(t, os.WriteFile(file, make([]byte, 500), 0644))
+	assert.NilError(t, c.MountPoints["/data"].Cleanup())
+	assert.Check(t, is.Equal(size(), int64(500)))
+	assert.NilError(t, os.WriteFile(file, make([]byte, 700), 0644))
+	assert.Check(t, is.Equal(size(), int64(500)))
+}
@@ -35,7 +35,7 @@ func (daemon *Daemon) setupMounts(c *container.Container) ([]container.Mount, er
 		if tmpfsMounts[m.Destination] {
 			continue
 		}
-		if err := daemon.lazyInitializeVolume(c.ID, m); err != nil {
+		if err := daemon.lazyInitializeVolume(c, m); err != nil {
 			return nil, err
 		}
 		// If the daemon is being shutdown, we should not let a container start if it is trying to
@@ -63,7 +63,7 @@ func (daemon *Daemon) setupMounts(c *container.Container) ([]container.Mount, er
 			if m.Spec.Type == mounttypes.TypeBind && m.Spec.BindOptions != nil {
 				mnt.NonRecursive = m.Spec.BindOptions.NonRecursive
 			}
-			if m.Volume != nil {
+			if m.Volume != nil && m.Type != mounttypes.TypeImage {
 				attributes := map[string]string{
 					"driver":      m.Volume.DriverName(),
 					"container":   c.ID,
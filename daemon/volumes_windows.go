@@ -21,7 +21,7 @@ import (
 func (daemon *Daemon) setupMounts(c *container.Container) ([]container.Mount, error) {
 	var mnts []container.Mount
 	for _, mount := range c.MountPoints { // type is volumemounts.MountPoint
-		if err := daemon.lazyInitializeVolume(c.ID, mount); err != nil {
+		if err := daemon.lazyInitializeVolume(c, mount); err != nil {
 			return nil, err
 		}
 		s, err := mount.Setup(c.MountLabel, idtools.Identity{}, nil)
@@ -14,8 +14,16 @@ import (
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer records the spans of layer downloads. It is a no-op tracer if
+// tracing is not enabled.
+var tracer = otel.Tracer("github.com/docker/docker/distribution/xfer")
+
 const maxDownloadAttempts = 5
 
 // LayerDownloadManager figures out which layers need to be downloaded, then
@@ -168,10 +176,10 @@ func (ldm *LayerDownloadManager) Download(ctx context.Context, initialRootFS ima
 
 		var xferFunc doFunc
 		if topDownload != nil {
-			xferFunc = ldm.makeDownloadFunc(descriptor, "", topDownload)
+			xferFunc = ldm.makeDownloadFunc(ctx, descriptor, "", topDownload)
 			defer topDownload.transfer.release(watcher)
 		} else {
-			xferFunc = ldm.makeDownloadFunc(descriptor, rootFS.ChainID(), nil)
+			xferFunc = ldm.makeDownloadFunc(ctx, descriptor, rootFS.ChainID(), nil)
 		}
 		topDownloadUncasted, watcher = ldm.tm.transfer(transferKey, xferFunc, progressOutput)
 		topDownload = topDownloadUncasted.(*downloadTransfer)
@@ -227,8 +235,9 @@ func (ldm *LayerDownloadManager) Download(ctx context.Context, initialRootFS ima
 // registration. If parentDownload is non-nil, it waits for that download to
 // complete before the registration step, and registers the downloaded data
 // on top of parentDownload's resulting layer. Otherwise, it registers the
-// layer on top of the ChainID given by parentLayer.
-func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor, parentLayer layer.ChainID, parentDownload *downloadTransfer) doFunc {
+// layer on top of the ChainID given by parentLayer. The download and the
+// registration are traced as part of the operation of ctx.
+func (ldm *LayerDownloadManager) makeDownloadFunc(ctx context.Context, descriptor DownloadDescriptor, parentLayer layer.ChainID, parentDownload *downloadTransfer) doFunc {
 	return func(progressChan chan<- progress.Progress, start <-chan struct{}, inactive chan<- struct{}) transfer {
 		d := &downloadTransfer{
 			transfer:   newTransfer(),
@@ -249,6 +258,17 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				<-start
 			}
 
+			_, span := tracer.Start(ctx, "xfer.DownloadLayer", trace.WithAttributes(
+				attribute.String("layer.id", descriptor.ID()),
+			))
+			defer func() {
+				if d.err != nil {
+					span.RecordError(d.err)
+					span.SetStatus(codes.Error, d.err.Error())
+				}
+				span.End()
+			}()
+
 			if parentDownload != nil {
 				// Did the parent download already fail or get
 				// cancelled?
@@ -350,11 +370,14 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 			if fs, ok := descriptor.(distribution.Describable); ok {
 				src = fs.Descriptor()
 			}
+			// The registration of the layer extracts it.
+			_, registerSpan := tracer.Start(trace.ContextWithSpan(ctx, span), "xfer.RegisterLayer")
 			if ds, ok := d.layerStore.(layer.DescribableStore); ok {
 				d.layer, err = ds.RegisterWithDescriptor(inflatedLayerData, parentLayer, src)
 			} else {
 				d.layer, err = d.layerStore.Register(inflatedLayerData, parentLayer)
 			}
+			registerSpan.End()
 			if err != nil {
 				select {
 				case <-d.transfer.context().Done():
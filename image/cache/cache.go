@@ -75,7 +75,7 @@ func (ic *ImageCache) GetCache(parentID string, cfg *containertypes.Config) (str
 	}
 
 	for _, target := range ic.sources {
-		if !isValidParent(target, parent) || !isValidConfig(cfg, target.History[lenHistory]) {
+		if !IsValidParent(target, parent) || !IsValidConfig(cfg, target.History[lenHistory]) {
 			continue
 		}
 
@@ -110,7 +110,7 @@ func (ic *ImageCache) restoreCachedImage(parent, target *image.Image, cfg *conta
 		lenHistory = len(parent.History)
 	}
 	history = append(history, target.History[lenHistory])
-	if layer := getLayerForHistoryIndex(target, lenHistory); layer != "" {
+	if layer := GetLayerForHistoryIndex(target, lenHistory); layer != "" {
 		rootFS.Append(layer)
 	}
 
@@ -156,7 +156,9 @@ func (ic *ImageCache) isParent(imgID, parentID image.ID) bool {
 	return ic.isParent(nextParent, parentID)
 }
 
-func getLayerForHistoryIndex(image *image.Image, index int) layer.DiffID {
+// GetLayerForHistoryIndex returns the layer created by the step of the history
+// of image at index, or an empty DiffID if the step did not create a layer.
+func GetLayerForHistoryIndex(image *image.Image, index int) layer.DiffID {
 	layerIndex := 0
 	for i, h := range image.History {
 		if i == index {
@@ -172,12 +174,15 @@ func getLayerForHistoryIndex(image *image.Image, index int) layer.DiffID {
 	return image.RootFS.DiffIDs[layerIndex] // validate?
 }
 
-func isValidConfig(cfg *containertypes.Config, h image.History) bool {
+// IsValidConfig returns whether the step of history h was created with cfg.
+func IsValidConfig(cfg *containertypes.Config, h image.History) bool {
 	// todo: make this format better than join that loses data
 	return strings.Join(cfg.Cmd, " ") == h.CreatedBy
 }
 
-func isValidParent(img, parent *image.Image) bool {
+// IsValidParent returns whether the history and layers of parent are a strict
+// prefix of those of img.
+func IsValidParent(img, parent *image.Image) bool {
 	if len(img.History) == 0 {
 		return false
 	}
@@ -225,7 +230,7 @@ func getLocalCachedImage(imageStore image.Store, imgID image.ID, config *contain
 				return nil, fmt.Errorf("unable to find image %q", id)
 			}
 
-			if compare(&img.ContainerConfig, config) {
+			if CompareConfig(&img.ContainerConfig, config) {
 				// check for the most up to date match
 				if match == nil || match.Created.Before(img.Created) {
 					match = img
@@ -114,12 +114,12 @@ func TestCompare(t *testing.T) {
 		{Volumes: volumes1}: {Volumes: volumes3},
 	}
 	for config1, config2 := range sameConfigs {
-		if !compare(config1, config2) {
+		if !CompareConfig(config1, config2) {
 			t.Fatalf("Compare should be true for [%v] and [%v]", config1, config2)
 		}
 	}
 	for config1, config2 := range differentConfigs {
-		if compare(config1, config2) {
+		if CompareConfig(config1, config2) {
 			t.Fatalf("Compare should be false for [%v] and [%v]", config1, config2)
 		}
 	}
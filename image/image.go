@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/layer"
@@ -112,6 +113,28 @@ type Image struct {
 	// computedID is the ID computed from the hash of the image config.
 	// Not to be confused with the legacy V1 ID in V1Image.
 	computedID ID
+
+	// Details holds additional details about the image that are not part of
+	// the image config, such as its references and size. It is only set by
+	// image services that do not provide an image and layer store, and is
+	// not serialized.
+	Details *Details `json:"-"`
+}
+
+// Details provides additional image data
+type Details struct {
+	References  []reference.Named
+	Size        int64
+	Metadata    map[string]string
+	Driver      string
+	LastUpdated time.Time
+}
+
+// NewImage creates a new image with the given ID
+func NewImage(id ID) *Image {
+	return &Image{
+		computedID: id,
+	}
 }
 
 // RawJSON returns the immutable JSON associated with the image.
@@ -0,0 +1,92 @@
+package image // import "github.com/docker/docker/integration/image"
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/testutil/registry"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+var digestRegexp = regexp.MustCompile(`sha256:[a-f0-9]{64}`)
+
+// TestImagePushPull pushes an image to a local registry, removes it, and
+// pulls it back, verifying that the same image is restored.
+func TestImagePushPull(t *testing.T) {
+	skip.If(t, testEnv.IsRemoteDaemon, "cannot run registry when remote daemon")
+	skip.If(t, testEnv.OSType == "windows")
+	skip.If(t, testEnv.IsRootless, "rootless mode has different view of localhost")
+
+	ctx := context.Background()
+
+	t.Run("no auth", func(t *testing.T) {
+		defer setupTest(t)()
+
+		reg := registry.NewV2(t)
+		defer reg.Close()
+		reg.WaitReady(t)
+
+		repo := path.Join(registry.DefaultURL, "test-"+strings.ToLower(t.Name())+":latest")
+		testPushPull(ctx, t, repo, "")
+	})
+
+	t.Run("with htpasswd", func(t *testing.T) {
+		defer setupTest(t)()
+
+		reg := registry.NewV2(t, registry.Htpasswd)
+		defer reg.Close()
+		reg.WaitReady(t)
+
+		repo := path.Join(registry.DefaultURL, "test-"+strings.ToLower(t.Name())+":latest")
+
+		auth := registrytypes.AuthConfig{ServerAddress: registry.DefaultURL, Username: "testuser", Password: "testpassword"}
+		authEncoded, err := json.Marshal(auth)
+		assert.NilError(t, err)
+		testPushPull(ctx, t, repo, base64.URLEncoding.EncodeToString(authEncoded))
+	})
+}
+
+func testPushPull(ctx context.Context, t *testing.T, repo, registryAuth string) {
+	t.Helper()
+	client := testEnv.APIClient()
+
+	err := client.ImageTag(ctx, "busybox:latest", repo)
+	assert.NilError(t, err)
+
+	before, _, err := client.ImageInspectWithRaw(ctx, repo)
+	assert.NilError(t, err)
+
+	rdr, err := client.ImagePush(ctx, repo, types.ImagePushOptions{RegistryAuth: registryAuth})
+	assert.NilError(t, err)
+	buf := &strings.Builder{}
+	err = jsonmessage.DisplayJSONMessagesStream(rdr, buf, 0, false, nil)
+	rdr.Close()
+	assert.NilError(t, err, buf)
+	pushed := digestRegexp.FindString(buf.String())
+	assert.Assert(t, pushed != "", "no digest in push output: %s", buf)
+
+	_, err = client.ImageRemove(ctx, repo, types.ImageRemoveOptions{})
+	assert.NilError(t, err)
+
+	rdr, err = client.ImagePull(ctx, repo, types.ImagePullOptions{RegistryAuth: registryAuth})
+	assert.NilError(t, err)
+	buf.Reset()
+	err = jsonmessage.DisplayJSONMessagesStream(rdr, buf, 0, false, nil)
+	rdr.Close()
+	assert.NilError(t, err, buf)
+	assert.Check(t, is.Contains(buf.String(), "Digest: "+pushed))
+
+	after, _, err := client.ImageInspectWithRaw(ctx, repo)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(before.ID, after.ID))
+}
@@ -59,6 +59,7 @@ import (
 	"github.com/docker/docker/libnetwork/discoverapi"
 	"github.com/docker/docker/libnetwork/driverapi"
 	"github.com/docker/docker/libnetwork/drvregistry"
+	"github.com/docker/docker/libnetwork/firewall"
 	"github.com/docker/docker/libnetwork/ipamapi"
 	"github.com/docker/docker/libnetwork/netlabel"
 	"github.com/docker/docker/libnetwork/options"
@@ -163,6 +164,7 @@ type controller struct {
 	watchCh          chan *endpoint
 	unWatchCh        chan *endpoint
 	svcRecords       map[string]svcInfo
+	staticRecords    map[string]staticZone
 	nmap             map[string]*netWatch
 	serviceBindings  map[serviceKey]*service
 	defOsSbox        osl.Sandbox
@@ -174,6 +176,7 @@ type controller struct {
 	agentStopDone    chan struct{}
 	keys             []*types.EncryptionKey
 	DiagnosticServer *diagnostic.Server
+	firewall         firewall.Firewaller
 	sync.Mutex
 }
 
@@ -189,6 +192,7 @@ func New(cfgOptions ...config.Option) (NetworkController, error) {
 		cfg:              config.ParseConfigOptions(cfgOptions...),
 		sandboxes:        sandboxTable{},
 		svcRecords:       make(map[string]svcInfo),
+		staticRecords:    make(map[string]staticZone),
 		serviceBindings:  make(map[serviceKey]*service),
 		agentInitDone:    make(chan struct{}),
 		networkLocker:    locker.New(),
@@ -200,6 +204,10 @@ func New(cfgOptions ...config.Option) (NetworkController, error) {
 		return nil, err
 	}
 
+	if err := c.initFirewall(); err != nil {
+		return nil, err
+	}
+
 	drvRegistry, err := drvregistry.New(c.getStore(datastore.LocalScope), c.getStore(datastore.GlobalScope), c.RegisterDriver, nil, c.cfg.PluginGetter)
 	if err != nil {
 		return nil, err
@@ -1302,3 +1310,26 @@ func (c *controller) iptablesEnabled() bool {
 	}
 	return enabled
 }
+
+// firewallBackend returns the firewall backend of the bridge driver, which is
+// also the firewall backend of the controller, or an empty string if the
+// default backend is used.
+func (c *controller) firewallBackend() string {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.cfg == nil {
+		return ""
+	}
+	// parse map cfg["bridge"]["generic"]["FirewallBackend"]
+	cfgBridge, ok := c.cfg.Daemon.DriverCfg["bridge"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	cfgGeneric, ok := cfgBridge[netlabel.GenericData].(options.Generic)
+	if !ok {
+		return ""
+	}
+	backend, _ := cfgGeneric["FirewallBackend"].(string)
+	return backend
+}
@@ -0,0 +1,232 @@
+package libnetwork
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/libnetwork/datastore"
+	"github.com/docker/docker/libnetwork/types"
+	"github.com/miekg/dns"
+)
+
+// Types of the static DNS records of networks.
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+	DNSRecordTXT   = "TXT"
+)
+
+// maxTXTLength is the maximum length of the value of a TXT record.
+const maxTXTLength = 4096
+
+// DNSRecord is a static DNS record of a network. Static records are served by
+// the embedded DNS server to the containers connected to the network, and take
+// precedence over the records of external DNS servers for the same name.
+type DNSRecord struct {
+	// Name is the name of the record, without trailing dot.
+	Name string
+	// Type is the type of the record: A, AAAA, CNAME or TXT.
+	Type string
+	// Value is the IP address of A and AAAA records, the target name of CNAME
+	// records, and the text of TXT records.
+	Value string
+	// TTL is the TTL of the record in seconds. The default TTL of the
+	// embedded DNS server is used if it is zero.
+	TTL uint32
+}
+
+// validateDNSRecords validates static DNS records, and returns them in their
+// canonical form, sorted.
+func validateDNSRecords(records []DNSRecord) ([]DNSRecord, error) {
+	var (
+		out   = make([]DNSRecord, 0, len(records))
+		seen  = make(map[DNSRecord]bool, len(records))
+		names = make(map[string][]DNSRecord)
+	)
+	for _, r := range records {
+		r.Name = strings.ToLower(strings.TrimSuffix(r.Name, "."))
+		r.Type = strings.ToUpper(r.Type)
+		if r.Name == "" {
+			return nil, types.BadRequestErrorf("invalid DNS record: empty name")
+		}
+		if _, ok := dns.IsDomainName(r.Name); !ok {
+			return nil, types.BadRequestErrorf("invalid DNS record name: %s", r.Name)
+		}
+		switch r.Type {
+		case DNSRecordA:
+			ip := net.ParseIP(r.Value)
+			if ip == nil || ip.To4() == nil {
+				return nil, types.BadRequestErrorf("invalid value of A record %s: %q is not an IPv4 address", r.Name, r.Value)
+			}
+			r.Value = ip.String()
+		case DNSRecordAAAA:
+			ip := net.ParseIP(r.Value)
+			if ip == nil || ip.To4() != nil {
+				return nil, types.BadRequestErrorf("invalid value of AAAA record %s: %q is not an IPv6 address", r.Name, r.Value)
+			}
+			r.Value = ip.String()
+		case DNSRecordCNAME:
+			r.Value = strings.ToLower(strings.TrimSuffix(r.Value, "."))
+			if _, ok := dns.IsDomainName(r.Value); !ok || r.Value == "" {
+				return nil, types.BadRequestErrorf("invalid value of CNAME record %s: %q is not a domain name", r.Name, r.Value)
+			}
+			if r.Value == r.Name {
+				return nil, types.BadRequestErrorf("invalid value of CNAME record %s: a name cannot be an alias of itself", r.Name)
+			}
+		case DNSRecordTXT:
+			if len(r.Value) > maxTXTLength {
+				return nil, types.BadRequestErrorf("invalid value of TXT record %s: longer than %d bytes", r.Name, maxTXTLength)
+			}
+		default:
+			return nil, types.BadRequestErrorf("invalid type of DNS record %s: %q, must be one of A, AAAA, CNAME or TXT", r.Name, r.Type)
+		}
+		if seen[r] {
+			return nil, types.BadRequestErrorf("duplicate %s record %s: %s", r.Type, r.Name, r.Value)
+		}
+		seen[r] = true
+		names[r.Name] = append(names[r.Name], r)
+		out = append(out, r)
+	}
+
+	// A name which is an alias cannot have other records (RFC 1034).
+	for name, rs := range names {
+		if len(rs) < 2 {
+			continue
+		}
+		for _, r := range rs {
+			if r.Type == DNSRecordCNAME {
+				return nil, types.BadRequestErrorf("invalid DNS records for %s: a name with a CNAME record cannot have other records", name)
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out, nil
+}
+
+// staticZone is the index of the static DNS records of a network by name.
+type staticZone map[string][]DNSRecord
+
+func newStaticZone(records []DNSRecord) staticZone {
+	z := make(staticZone, len(records))
+	for _, r := range records {
+		z[r.Name] = append(z[r.Name], r)
+	}
+	return z
+}
+
+// DNSRecords returns the static DNS records of the network.
+func (n *network) DNSRecords() []DNSRecord {
+	n.Lock()
+	defer n.Unlock()
+
+	if len(n.dnsRecords) == 0 {
+		return nil
+	}
+	return append([]DNSRecord(nil), n.dnsRecords...)
+}
+
+// SetDNSRecords replaces the static DNS records of the network. The records
+// are served to the containers connected to the network as soon as they are
+// stored.
+func (n *network) SetDNSRecords(records []DNSRecord) error {
+	if n.ConfigOnly() {
+		return types.ForbiddenErrorf("static DNS records are not supported on configuration networks")
+	}
+	if n.Dynamic() || n.DataScope() == datastore.SwarmScope {
+		return types.ForbiddenErrorf("static DNS records are not supported on swarm networks")
+	}
+	records, err := validateDNSRecords(records)
+	if err != nil {
+		return err
+	}
+
+	c := n.getController()
+	cs := c.getStore(n.DataScope())
+	if cs == nil {
+		return ErrDataStoreNotInitialized(n.DataScope())
+	}
+	for {
+		n.Lock()
+		n.dnsRecords = records
+		n.Unlock()
+
+		err := c.updateToStore(n)
+		if err == nil {
+			break
+		}
+		if err != datastore.ErrKeyModified {
+			return err
+		}
+		// The network was updated concurrently, get its latest version
+		// before trying again.
+		if err := cs.GetObject(datastore.Key(n.Key()...), n); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	c.staticRecords[n.id] = newStaticZone(records)
+	c.Unlock()
+	return nil
+}
+
+// lookupStaticRecords returns the static DNS records of the network with the
+// given name, and whether the network has records for the name.
+func (c *controller) lookupStaticRecords(nid, name string) ([]DNSRecord, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	c.Lock()
+	z, ok := c.staticRecords[nid]
+	c.Unlock()
+	if !ok {
+		// The records of the network were not loaded since the daemon
+		// started.
+		n, err := c.getNetworkFromStore(nid)
+		if err != nil {
+			return nil, false
+		}
+		c.Lock()
+		if z, ok = c.staticRecords[nid]; !ok {
+			z = newStaticZone(n.DNSRecords())
+			c.staticRecords[nid] = z
+		}
+		c.Unlock()
+	}
+	records, ok := z[name]
+	return records, ok
+}
+
+// ResolveStatic returns the static DNS records of the network with the given
+// name, and whether the network has records for the name.
+func (n *network) ResolveStatic(name string) ([]DNSRecord, bool) {
+	return n.getController().lookupStaticRecords(n.ID(), name)
+}
+
+// ResolveStatic returns the static DNS records with the given name of the
+// networks the sandbox is connected to, and whether any of the networks has
+// records for the name.
+func (sb *sandbox) ResolveStatic(name string) ([]DNSRecord, bool) {
+	var (
+		records []DNSRecord
+		found   bool
+	)
+	for _, ep := range sb.getConnectedEndpoints() {
+		n := ep.getNetwork()
+		if rs, ok := sb.controller.lookupStaticRecords(n.ID(), name); ok {
+			records = append(records, rs...)
+			found = true
+		}
+	}
+	return records, found
+}
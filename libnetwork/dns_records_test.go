@@ -0,0 +1,209 @@
+package libnetwork
+
+import (
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/libnetwork/types"
+	"github.com/miekg/dns"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func TestValidateDNSRecords(t *testing.T) {
+	records, err := validateDNSRecords([]DNSRecord{
+		{Name: "www.Example.com.", Type: "cname", Value: "DB.example.com."},
+		{Name: "db.example.com", Type: "AAAA", Value: "2001:db8:0::10"},
+		{Name: "db.example.com", Type: "A", Value: "10.0.0.10", TTL: 60},
+		{Name: "example.com", Type: "TXT", Value: "v=spf1 -all"},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(records, []DNSRecord{
+		{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10", TTL: 60},
+		{Name: "db.example.com", Type: DNSRecordAAAA, Value: "2001:db8::10"},
+		{Name: "example.com", Type: DNSRecordTXT, Value: "v=spf1 -all"},
+		{Name: "www.example.com", Type: DNSRecordCNAME, Value: "db.example.com"},
+	}))
+
+	for _, tc := range []struct {
+		records     []DNSRecord
+		expectedErr string
+	}{
+		{
+			records:     []DNSRecord{{Name: "", Type: DNSRecordA, Value: "10.0.0.10"}},
+			expectedErr: "invalid DNS record: empty name",
+		},
+		{
+			records:     []DNSRecord{{Name: "db.example.com", Type: "MX", Value: "mail.example.com"}},
+			expectedErr: `invalid type of DNS record db.example.com: "MX", must be one of A, AAAA, CNAME or TXT`,
+		},
+		{
+			records:     []DNSRecord{{Name: "db.example.com", Type: DNSRecordA, Value: "2001:db8::10"}},
+			expectedErr: `invalid value of A record db.example.com: "2001:db8::10" is not an IPv4 address`,
+		},
+		{
+			records:     []DNSRecord{{Name: "db.example.com", Type: DNSRecordAAAA, Value: "10.0.0.10"}},
+			expectedErr: `invalid value of AAAA record db.example.com: "10.0.0.10" is not an IPv6 address`,
+		},
+		{
+			records:     []DNSRecord{{Name: "db.example.com", Type: DNSRecordCNAME, Value: "DB.example.com"}},
+			expectedErr: "invalid value of CNAME record db.example.com: a name cannot be an alias of itself",
+		},
+		{
+			records: []DNSRecord{
+				{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10"},
+				{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10"},
+			},
+			expectedErr: "duplicate A record db.example.com: 10.0.0.10",
+		},
+		{
+			records: []DNSRecord{
+				{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10"},
+				{Name: "db.example.com", Type: DNSRecordCNAME, Value: "db2.example.com"},
+			},
+			expectedErr: "invalid DNS records for db.example.com: a name with a CNAME record cannot have other records",
+		},
+	} {
+		_, err := validateDNSRecords(tc.records)
+		assert.Check(t, is.Error(err, tc.expectedErr))
+		_, ok := err.(types.BadRequestError)
+		assert.Check(t, ok, "expected a bad request error, got %T", err)
+	}
+}
+
+// staticBackend is a DNSBackend which only has static DNS records.
+type staticBackend struct {
+	sandbox
+	zone  staticZone
+	names map[string][]net.IP
+}
+
+func (b *staticBackend) ResolveStatic(name string) ([]DNSRecord, bool) {
+	records, ok := b.zone[strings.ToLower(strings.TrimSuffix(name, "."))]
+	return records, ok
+}
+
+func (b *staticBackend) ResolveName(name string, ipType int) ([]net.IP, bool) {
+	return b.names[name], false
+}
+
+func TestDNSStaticQuery(t *testing.T) {
+	records, err := validateDNSRecords([]DNSRecord{
+		{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10", TTL: 60},
+		{Name: "www.example.com", Type: DNSRecordCNAME, Value: "db.example.com"},
+		{Name: "app.example.com", Type: DNSRecordCNAME, Value: "app"},
+		{Name: "example.com", Type: DNSRecordTXT, Value: "v=spf1 -all"},
+	})
+	assert.NilError(t, err)
+	r := NewResolver(resolverIPSandbox, false, "", &staticBackend{
+		zone:  newStaticZone(records),
+		names: map[string][]net.IP{"app.": {net.ParseIP("172.20.0.2")}},
+	}).(*resolver)
+
+	query := func(name string, qtype uint16) *dns.Msg {
+		w := new(tstwriter)
+		q := new(dns.Msg)
+		q.SetQuestion(name, qtype)
+		r.ServeDNS(w, q)
+		resp := w.GetResponse()
+		assert.Assert(t, resp != nil)
+		return resp
+	}
+
+	resp := query("DB.example.com.", dns.TypeA)
+	assert.Check(t, is.Equal(resp.Rcode, dns.RcodeSuccess))
+	assert.Assert(t, is.Len(resp.Answer, 1))
+	assert.Check(t, is.Equal(resp.Answer[0].String(), "DB.example.com.\t60\tIN\tA\t10.0.0.10"))
+
+	// Aliases are followed to the records of their target.
+	resp = query("www.example.com.", dns.TypeA)
+	assert.Assert(t, is.Len(resp.Answer, 2))
+	assert.Check(t, is.Equal(resp.Answer[0].String(), "www.example.com.\t600\tIN\tCNAME\tdb.example.com."))
+	assert.Check(t, is.Equal(resp.Answer[1].String(), "db.example.com.\t60\tIN\tA\t10.0.0.10"))
+
+	// and to containers and services of the networks.
+	resp = query("app.example.com.", dns.TypeA)
+	assert.Assert(t, is.Len(resp.Answer, 2))
+	assert.Check(t, is.Equal(resp.Answer[1].String(), "app.\t600\tIN\tA\t172.20.0.2"))
+
+	resp = query("example.com.", dns.TypeTXT)
+	assert.Assert(t, is.Len(resp.Answer, 1))
+	assert.Check(t, is.Equal(resp.Answer[0].String(), "example.com.\t600\tIN\tTXT\t\"v=spf1 -all\""))
+
+	// Names with static records are not forwarded for other types.
+	resp = query("db.example.com.", dns.TypeAAAA)
+	assert.Check(t, is.Equal(resp.Rcode, dns.RcodeSuccess))
+	assert.Check(t, is.Len(resp.Answer, 0))
+
+	// Other names are, which fails as the resolver does not proxy queries.
+	resp = query("other.example.com.", dns.TypeA)
+	assert.Check(t, is.Equal(resp.Rcode, dns.RcodeServerFailure))
+}
+
+func TestSetDNSRecords(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "test only works on linux")
+
+	c, err := New()
+	assert.NilError(t, err)
+	defer c.Stop()
+
+	n, err := c.NewNetwork("bridge", "dtnet1", "", nil)
+	assert.NilError(t, err)
+	defer func() {
+		assert.Check(t, n.Delete())
+	}()
+
+	ep, err := n.CreateEndpoint("testep")
+	assert.NilError(t, err)
+	sb, err := c.NewSandbox("c1")
+	assert.NilError(t, err)
+	defer func() {
+		assert.Check(t, sb.Delete())
+	}()
+	assert.NilError(t, ep.Join(sb))
+
+	r := NewResolver(resolverIPSandbox, false, sb.Key(), sb.(*sandbox)).(*resolver)
+	query := func(name string) *dns.Msg {
+		w := new(tstwriter)
+		q := new(dns.Msg)
+		q.SetQuestion(name, dns.TypeA)
+		r.ServeDNS(w, q)
+		resp := w.GetResponse()
+		assert.Assert(t, resp != nil)
+		return resp
+	}
+
+	assert.NilError(t, n.SetDNSRecords([]DNSRecord{
+		{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10"},
+	}))
+	assert.Check(t, is.DeepEqual(n.Info().DNSRecords(), []DNSRecord{
+		{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10"},
+	}))
+	resp := query("db.example.com.")
+	assert.Assert(t, is.Len(resp.Answer, 1))
+	assert.Check(t, is.Equal(resp.Answer[0].(*dns.A).A.String(), "10.0.0.10"))
+
+	// Updates are served to the sandboxes connected to the network.
+	assert.NilError(t, n.SetDNSRecords([]DNSRecord{
+		{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.11"},
+	}))
+	resp = query("db.example.com.")
+	assert.Assert(t, is.Len(resp.Answer, 1))
+	assert.Check(t, is.Equal(resp.Answer[0].(*dns.A).A.String(), "10.0.0.11"))
+
+	// The records are stored with the network.
+	nn, err := c.NetworkByID(n.ID())
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(nn.Info().DNSRecords(), 1))
+
+	assert.NilError(t, n.SetDNSRecords(nil))
+	resp = query("db.example.com.")
+	assert.Check(t, is.Equal(resp.Rcode, dns.RcodeServerFailure))
+
+	err = n.SetDNSRecords([]DNSRecord{{Name: "db.example.com", Type: "MX", Value: "mail.example.com"}})
+	_, ok := err.(types.BadRequestError)
+	assert.Check(t, ok, "expected a bad request error, got %v", err)
+}
@@ -0,0 +1,213 @@
+//go:build linux
+// +build linux
+
+package bridge
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/docker/docker/libnetwork/types"
+	units "github.com/docker/go-units"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// minBurst is the minimum burst size of the bandwidth limits. It must be
+	// large enough for the segmentation offloaded packets going through the
+	// veth pair.
+	minBurst = 64 * units.KiB
+
+	// defaultBurstPeriod is the period of traffic at the limited rate which
+	// makes the default burst size, when it is larger than minBurst.
+	defaultBurstPeriod = 100 * time.Millisecond
+
+	// shapingLatency is the maximum time packets wait in the queue of the
+	// ingress rate limit before being dropped.
+	shapingLatency = 25 * time.Millisecond
+)
+
+// parseBandwidthOptions returns the bandwidth limits set by the driver
+// options of an endpoint, or nil if the options set no limit.
+func parseBandwidthOptions(epOptions map[string]interface{}) (*types.Bandwidth, error) {
+	ingressRate, err := parseBandwidthOption(epOptions, IngressRate)
+	if err != nil {
+		return nil, err
+	}
+	ingressBurst, err := parseBandwidthOption(epOptions, IngressBurst)
+	if err != nil {
+		return nil, err
+	}
+	egressRate, err := parseBandwidthOption(epOptions, EgressRate)
+	if err != nil {
+		return nil, err
+	}
+	egressBurst, err := parseBandwidthOption(epOptions, EgressBurst)
+	if err != nil {
+		return nil, err
+	}
+
+	if ingressBurst != 0 && ingressRate == 0 {
+		return nil, types.BadRequestErrorf("invalid bandwidth options: %s requires %s", IngressBurst, IngressRate)
+	}
+	if egressBurst != 0 && egressRate == 0 {
+		return nil, types.BadRequestErrorf("invalid bandwidth options: %s requires %s", EgressBurst, EgressRate)
+	}
+	if ingressRate == 0 && egressRate == 0 {
+		return nil, nil
+	}
+	// The egress limit is applied by a police action, whose rate is a 32-bit
+	// value.
+	if egressRate > math.MaxUint32 {
+		return nil, types.BadRequestErrorf("invalid value for %s: must be at most %d bytes per second", EgressRate, uint64(math.MaxUint32))
+	}
+
+	bw := &types.Bandwidth{IngressRate: ingressRate, EgressRate: egressRate}
+	if bw.IngressBurst, err = burstSize(IngressBurst, ingressRate, ingressBurst); err != nil {
+		return nil, err
+	}
+	if bw.EgressBurst, err = burstSize(EgressBurst, egressRate, egressBurst); err != nil {
+		return nil, err
+	}
+	return bw, nil
+}
+
+// parseBandwidthOption returns the value in bytes of the bandwidth option
+// with the given label, or zero if it is not set.
+func parseBandwidthOption(epOptions map[string]interface{}, label string) (uint64, error) {
+	opt, ok := epOptions[label]
+	if !ok {
+		return 0, nil
+	}
+	s, ok := opt.(string)
+	if !ok {
+		return 0, types.BadRequestErrorf("invalid value for %s: %v", label, opt)
+	}
+	v, err := units.RAMInBytes(s)
+	if err != nil || v <= 0 {
+		return 0, types.BadRequestErrorf("invalid value for %s: %q must be a positive size, such as 10m", label, s)
+	}
+	return uint64(v), nil
+}
+
+// burstSize returns the burst size of a rate limit, which is the default one
+// if burst is zero.
+func burstSize(label string, rate, burst uint64) (uint64, error) {
+	if rate == 0 {
+		return 0, nil
+	}
+	if burst == 0 {
+		burst = uint64(float64(rate) * defaultBurstPeriod.Seconds())
+		if burst < minBurst {
+			burst = minBurst
+		}
+		if burst > math.MaxUint32 {
+			burst = math.MaxUint32
+		}
+		return burst, nil
+	}
+	if burst < minBurst {
+		return 0, types.BadRequestErrorf("invalid value for %s: must be at least %s", label, units.BytesSize(minBurst))
+	}
+	if burst > math.MaxUint32 {
+		return 0, types.BadRequestErrorf("invalid value for %s: must be at most %d bytes", label, uint64(math.MaxUint32))
+	}
+	return burst, nil
+}
+
+// setupBandwidth applies the bandwidth limits to the host side interface of
+// the veth pair of an endpoint. The traffic received by the container is
+// shaped by a token bucket filter on the egress of the interface, and the
+// traffic sent by the container is policed on the ingress of the interface.
+func setupBandwidth(nlh *netlink.Handle, ifName string, bw *types.Bandwidth) error {
+	link, err := nlh.LinkByName(ifName)
+	if err != nil {
+		return fmt.Errorf("failed to find interface %s: %v", ifName, err)
+	}
+	linkIndex := link.Attrs().Index
+
+	if bw.IngressRate != 0 {
+		limit := uint64(float64(bw.IngressRate)*shapingLatency.Seconds()) + bw.IngressBurst
+		if limit > math.MaxUint32 {
+			limit = math.MaxUint32
+		}
+		tbf := &netlink.Tbf{
+			QdiscAttrs: netlink.QdiscAttrs{
+				LinkIndex: linkIndex,
+				Handle:    netlink.MakeHandle(1, 0),
+				Parent:    netlink.HANDLE_ROOT,
+			},
+			Rate:   bw.IngressRate,
+			Buffer: netlink.Xmittime(bw.IngressRate, uint32(bw.IngressBurst)),
+			Limit:  uint32(limit),
+		}
+		if err := nlh.QdiscReplace(tbf); err != nil {
+			return fmt.Errorf("failed to set ingress rate limit on interface %s: %v", ifName, err)
+		}
+	}
+
+	if bw.EgressRate != 0 {
+		ingress := &netlink.Ingress{
+			QdiscAttrs: netlink.QdiscAttrs{
+				LinkIndex: linkIndex,
+				Handle:    netlink.MakeHandle(0xffff, 0),
+				Parent:    netlink.HANDLE_INGRESS,
+			},
+		}
+		if err := nlh.QdiscReplace(ingress); err != nil {
+			return fmt.Errorf("failed to add ingress qdisc on interface %s: %v", ifName, err)
+		}
+		police := netlink.NewPoliceAction()
+		police.Rate = uint32(bw.EgressRate)
+		police.Burst = uint32(bw.EgressBurst)
+		police.Mtu = math.MaxUint16
+		police.ExceedAction = netlink.TC_POLICE_SHOT
+		filter := &netlink.MatchAll{
+			FilterAttrs: netlink.FilterAttrs{
+				LinkIndex: linkIndex,
+				Parent:    netlink.MakeHandle(0xffff, 0),
+				Handle:    1,
+				Priority:  1,
+				Protocol:  unix.ETH_P_ALL,
+			},
+			Actions: []netlink.Action{police},
+		}
+		if err := nlh.FilterReplace(filter); err != nil {
+			return fmt.Errorf("failed to set egress rate limit on interface %s: %v", ifName, err)
+		}
+	}
+	return nil
+}
+
+// removeBandwidth removes the bandwidth limits from the host side interface
+// of the veth pair of an endpoint.
+func removeBandwidth(nlh *netlink.Handle, ifName string) error {
+	link, err := nlh.LinkByName(ifName)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); ok {
+			return nil
+		}
+		return fmt.Errorf("failed to find interface %s: %v", ifName, err)
+	}
+	qdiscs, err := nlh.QdiscList(link)
+	if err != nil {
+		return fmt.Errorf("failed to list qdiscs of interface %s: %v", ifName, err)
+	}
+	for _, qdisc := range qdiscs {
+		switch q := qdisc.(type) {
+		case *netlink.Tbf:
+			if q.Parent != netlink.HANDLE_ROOT {
+				continue
+			}
+		case *netlink.Ingress:
+		default:
+			continue
+		}
+		if err := nlh.QdiscDel(qdisc); err != nil {
+			return fmt.Errorf("failed to remove %s qdisc of interface %s: %v", qdisc.Type(), ifName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+//go:build linux
+// +build linux
+
+package bridge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/libnetwork/testutils"
+	"github.com/docker/docker/libnetwork/types"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseBandwidthOptions(t *testing.T) {
+	for _, tc := range []struct {
+		options     map[string]interface{}
+		expected    *types.Bandwidth
+		expectedErr string
+	}{
+		{
+			options: map[string]interface{}{},
+		},
+		{
+			options: map[string]interface{}{
+				IngressRate: "10m",
+			},
+			expected: &types.Bandwidth{IngressRate: 10 << 20, IngressBurst: 1 << 20},
+		},
+		{
+			options: map[string]interface{}{
+				EgressRate: "100k",
+			},
+			expected: &types.Bandwidth{EgressRate: 100 << 10, EgressBurst: minBurst},
+		},
+		{
+			options: map[string]interface{}{
+				IngressRate:  "1g",
+				IngressBurst: "256k",
+				EgressRate:   "512k",
+				EgressBurst:  "128k",
+			},
+			expected: &types.Bandwidth{
+				IngressRate:  1 << 30,
+				IngressBurst: 256 << 10,
+				EgressRate:   512 << 10,
+				EgressBurst:  128 << 10,
+			},
+		},
+		{
+			options: map[string]interface{}{
+				IngressBurst: "1m",
+			},
+			expectedErr: "requires " + IngressRate,
+		},
+		{
+			options: map[string]interface{}{
+				EgressRate: "fast",
+			},
+			expectedErr: "must be a positive size",
+		},
+		{
+			options: map[string]interface{}{
+				EgressRate: "0",
+			},
+			expectedErr: "must be a positive size",
+		},
+		{
+			options: map[string]interface{}{
+				EgressRate: "8g",
+			},
+			expectedErr: "must be at most 4294967295 bytes per second",
+		},
+		{
+			options: map[string]interface{}{
+				IngressRate:  "1m",
+				IngressBurst: "1k",
+			},
+			expectedErr: "must be at least 64KiB",
+		},
+	} {
+		bw, err := parseBandwidthOptions(tc.options)
+		if tc.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error containing %q for %v, got %v", tc.expectedErr, tc.options, err)
+			}
+			if _, ok := err.(types.BadRequestError); !ok {
+				t.Fatalf("Expected a bad request error for %v, got %T", tc.options, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", tc.options, err)
+		}
+		if (bw == nil) != (tc.expected == nil) || (bw != nil && *bw != *tc.expected) {
+			t.Fatalf("Expected %+v for %v, got %+v", tc.expected, tc.options, bw)
+		}
+	}
+}
+
+func newTestVeth(t *testing.T) (*netlink.Handle, netlink.Link) {
+	t.Helper()
+	nh, err := netlink.NewHandle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nh.Delete)
+
+	link := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: "veth0"},
+		PeerName:  "veth1",
+	}
+	if err := nh.LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	return nh, link
+}
+
+func bandwidthQdiscs(t *testing.T, nh *netlink.Handle, link netlink.Link) (tbf *netlink.Tbf, ingress bool) {
+	t.Helper()
+	qdiscs, err := nh.QdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, qdisc := range qdiscs {
+		switch q := qdisc.(type) {
+		case *netlink.Tbf:
+			tbf = q
+		case *netlink.Ingress:
+			ingress = true
+		}
+	}
+	return tbf, ingress
+}
+
+func TestSetupBandwidthIngress(t *testing.T) {
+	defer testutils.SetupTestOSContext(t)()
+	nh, link := newTestVeth(t)
+
+	bw := &types.Bandwidth{IngressRate: 1 << 20, IngressBurst: 128 << 10}
+	if err := setupBandwidth(nh, "veth0", bw); err != nil {
+		t.Fatal(err)
+	}
+	tbf, ingress := bandwidthQdiscs(t, nh, link)
+	if tbf == nil || tbf.Rate != bw.IngressRate {
+		t.Fatalf("Expected a tbf qdisc with rate %d, got %+v", bw.IngressRate, tbf)
+	}
+	if ingress {
+		t.Fatal("Unexpected ingress qdisc without egress rate limit")
+	}
+
+	if err := removeBandwidth(nh, "veth0"); err != nil {
+		t.Fatal(err)
+	}
+	if tbf, _ := bandwidthQdiscs(t, nh, link); tbf != nil {
+		t.Fatalf("Unexpected tbf qdisc after removing the bandwidth limits: %+v", tbf)
+	}
+
+	// Removing the limits of an interface which does not exist is a no-op.
+	if err := removeBandwidth(nh, "veth2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupBandwidthEgress(t *testing.T) {
+	defer testutils.SetupTestOSContext(t)()
+	nh, link := newTestVeth(t)
+
+	bw := &types.Bandwidth{EgressRate: 512 << 10, EgressBurst: minBurst}
+	if err := setupBandwidth(nh, "veth0", bw); err != nil {
+		if strings.Contains(err.Error(), unix.ENOENT.Error()) {
+			t.Skip("The kernel does not support matchall filters and police actions")
+		}
+		t.Fatal(err)
+	}
+	if _, ingress := bandwidthQdiscs(t, nh, link); !ingress {
+		t.Fatal("Expected an ingress qdisc")
+	}
+	filters, err := nh.FilterList(link, netlink.MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatalf("Expected 1 ingress filter, got %v", filters)
+	}
+
+	if err := removeBandwidth(nh, "veth0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ingress := bandwidthQdiscs(t, nh, link); ingress {
+		t.Fatal("Unexpected ingress qdisc after removing the bandwidth limits")
+	}
+}
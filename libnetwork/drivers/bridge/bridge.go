@@ -17,6 +17,7 @@ import (
 	"github.com/docker/docker/libnetwork/datastore"
 	"github.com/docker/docker/libnetwork/discoverapi"
 	"github.com/docker/docker/libnetwork/driverapi"
+	"github.com/docker/docker/libnetwork/firewall"
 	"github.com/docker/docker/libnetwork/iptables"
 	"github.com/docker/docker/libnetwork/netlabel"
 	"github.com/docker/docker/libnetwork/netutils"
@@ -37,6 +38,15 @@ const (
 	maxAllocatePortAttempts    = 10
 )
 
+const (
+	// UserlandProxyModeProcess runs a docker-proxy process per published
+	// port. It is the default.
+	UserlandProxyModeProcess = "process"
+	// UserlandProxyModeInternal runs the userland proxies of the published
+	// ports in the daemon.
+	UserlandProxyModeInternal = "internal"
+)
+
 const (
 	// DefaultGatewayV4AuxKey represents the default-gateway configured by the user
 	DefaultGatewayV4AuxKey = "DefaultGatewayIPv4"
@@ -62,6 +72,8 @@ type configuration struct {
 	EnableIP6Tables     bool
 	EnableUserlandProxy bool
 	UserlandProxyPath   string
+	UserlandProxyMode   string
+	FirewallBackend     string
 }
 
 // networkConfiguration for network specific configuration
@@ -101,6 +113,7 @@ const (
 // endpointConfiguration represents the user specified configuration for the sandbox endpoint
 type endpointConfiguration struct {
 	MacAddress net.HardwareAddr
+	Bandwidth  *types.Bandwidth
 }
 
 // containerConfiguration represents the user specified configuration for a container
@@ -119,6 +132,7 @@ type bridgeEndpoint struct {
 	id              string
 	nid             string
 	srcName         string
+	hostIfName      string
 	addr            *net.IPNet
 	addrv6          *net.IPNet
 	macAddress      net.HardwareAddr
@@ -152,6 +166,7 @@ type driver struct {
 	filterChainV6     *iptables.ChainInfo
 	isolationChain1V6 *iptables.ChainInfo
 	isolationChain2V6 *iptables.ChainInfo
+	firewall          firewaller
 	networks          map[string]*bridgeNetwork
 	store             datastore.DataStore
 	nlh               *netlink.Handle
@@ -334,31 +349,33 @@ func (n *bridgeNetwork) isolateNetwork(enable bool) error {
 	}
 
 	// Install the rules to isolate this network against each of the other networks
+	fw := n.driver.getFirewaller()
 	if n.driver.config.EnableIP6Tables {
-		err := setINC(iptables.IPv6, thisConfig.BridgeName, enable)
+		err := fw.isolateNetwork(iptables.IPv6, thisConfig.BridgeName, enable)
 		if err != nil {
 			return err
 		}
 	}
 
 	if n.driver.config.EnableIPTables {
-		return setINC(iptables.IPv4, thisConfig.BridgeName, enable)
+		return fw.isolateNetwork(iptables.IPv4, thisConfig.BridgeName, enable)
 	}
 	return nil
 }
 
+// newPortMapper returns the port mapper of a network, which runs the userland
+// proxies of the published ports in the daemon or in docker-proxy processes.
+func (d *driver) newPortMapper() *portmapper.PortMapper {
+	if d.config.UserlandProxyMode == UserlandProxyModeInternal {
+		return portmapper.NewInternal()
+	}
+	return portmapper.New(d.config.UserlandProxyPath)
+}
+
 func (d *driver) configure(option map[string]interface{}) error {
 	var (
-		config            *configuration
-		err               error
-		natChain          *iptables.ChainInfo
-		filterChain       *iptables.ChainInfo
-		isolationChain1   *iptables.ChainInfo
-		isolationChain2   *iptables.ChainInfo
-		natChainV6        *iptables.ChainInfo
-		filterChainV6     *iptables.ChainInfo
-		isolationChain1V6 *iptables.ChainInfo
-		isolationChain2V6 *iptables.ChainInfo
+		config *configuration
+		err    error
 	)
 
 	genericData, ok := option[netlabel.GenericData]
@@ -387,42 +404,25 @@ func (d *driver) configure(option map[string]interface{}) error {
 		}
 	}
 
-	if config.EnableIPTables {
-		removeIPChains(iptables.IPv4)
+	fw, err := d.newFirewaller(config)
+	if err != nil {
+		return err
+	}
 
-		natChain, filterChain, isolationChain1, isolationChain2, err = setupIPChains(config, iptables.IPv4)
-		if err != nil {
+	if config.EnableIPTables {
+		if err := fw.init(config, iptables.IPv4); err != nil {
 			return err
 		}
-
-		// Make sure on firewall reload, first thing being re-played is chains creation
-		iptables.OnReloaded(func() {
-			logrus.Debugf("Recreating iptables chains on firewall reload")
-			if _, _, _, _, err := setupIPChains(config, iptables.IPv4); err != nil {
-				logrus.WithError(err).Error("Error reloading iptables chains")
-			}
-		})
 	}
 
 	if config.EnableIP6Tables {
-		removeIPChains(iptables.IPv6)
-
-		natChainV6, filterChainV6, isolationChain1V6, isolationChain2V6, err = setupIPChains(config, iptables.IPv6)
-		if err != nil {
+		if err := fw.init(config, iptables.IPv6); err != nil {
 			return err
 		}
-
-		// Make sure on firewall reload, first thing being re-played is chains creation
-		iptables.OnReloaded(func() {
-			logrus.Debugf("Recreating ip6tables chains on firewall reload")
-			if _, _, _, _, err := setupIPChains(config, iptables.IPv6); err != nil {
-				logrus.WithError(err).Error("Error reloading ip6tables chains")
-			}
-		})
 	}
 
 	if config.EnableIPForwarding {
-		err = setupIPForwarding(config.EnableIPTables, config.EnableIP6Tables)
+		err = setupIPForwarding(fw, config.EnableIPTables, config.EnableIP6Tables)
 		if err != nil {
 			logrus.Warn(err)
 			return err
@@ -430,14 +430,7 @@ func (d *driver) configure(option map[string]interface{}) error {
 	}
 
 	d.Lock()
-	d.natChain = natChain
-	d.filterChain = filterChain
-	d.isolationChain1 = isolationChain1
-	d.isolationChain2 = isolationChain2
-	d.natChainV6 = natChainV6
-	d.filterChainV6 = filterChainV6
-	d.isolationChain1V6 = isolationChain1V6
-	d.isolationChain2V6 = isolationChain2V6
+	d.firewall = fw
 	d.config = config
 	d.Unlock()
 
@@ -691,8 +684,8 @@ func (d *driver) createNetwork(config *networkConfiguration) (err error) {
 		id:           config.ID,
 		endpoints:    make(map[string]*bridgeEndpoint),
 		config:       config,
-		portMapper:   portmapper.New(d.config.UserlandProxyPath),
-		portMapperV6: portmapper.New(d.config.UserlandProxyPath),
+		portMapper:   d.newPortMapper(),
+		portMapperV6: d.newPortMapper(),
 		bridge:       bridgeIface,
 		driver:       d,
 	}
@@ -746,6 +739,10 @@ func (d *driver) createNetwork(config *networkConfiguration) (err error) {
 
 	enableIPv6Forwarding := d.config.EnableIPForwarding && config.AddressIPv6 != nil
 
+	// Rules are only lost on firewall reloads when they are programmed with
+	// iptables, nftables tables of the driver are left alone by firewalld.
+	trackFirewalld := d.config.FirewallBackend != firewall.NFTables
+
 	// Conditionally queue setup steps depending on configuration values.
 	for _, step := range []struct {
 		Condition bool
@@ -775,9 +772,9 @@ func (d *driver) createNetwork(config *networkConfiguration) (err error) {
 
 		// We want to track firewalld configuration so that
 		// if it is started/reloaded, the rules can be applied correctly
-		{d.config.EnableIPTables, network.setupFirewalld},
+		{trackFirewalld && d.config.EnableIPTables, network.setupFirewalld},
 		// same for IPv6
-		{config.EnableIPv6 && d.config.EnableIP6Tables, network.setupFirewalld6},
+		{trackFirewalld && config.EnableIPv6 && d.config.EnableIP6Tables, network.setupFirewalld6},
 
 		// Setup DefaultGatewayIPv4
 		{config.DefaultGatewayIPv4 != nil, setupGatewayIPv4},
@@ -1070,6 +1067,7 @@ func (d *driver) CreateEndpoint(nid, eid string, ifInfo driverapi.InterfaceInfo,
 
 	// Store the sandbox side pipe interface parameters
 	endpoint.srcName = containerIfName
+	endpoint.hostIfName = hostIfName
 	endpoint.macAddress = ifInfo.MacAddress()
 	endpoint.addr = ifInfo.Address()
 	endpoint.addrv6 = ifInfo.AddressIPv6()
@@ -1238,6 +1236,10 @@ func (d *driver) EndpointOperInfo(nid, eid string) (map[string]interface{}, erro
 		m[netlabel.MacAddress] = ep.macAddress
 	}
 
+	if ep.config != nil && ep.config.Bandwidth != nil {
+		m[netlabel.Bandwidth] = ep.config.Bandwidth.GetCopy()
+	}
+
 	return m, nil
 }
 
@@ -1284,6 +1286,12 @@ func (d *driver) Join(nid, eid string, sboxKey string, jinfo driverapi.JoinInfo,
 		return err
 	}
 
+	if endpoint.config != nil && endpoint.config.Bandwidth != nil && endpoint.hostIfName != "" {
+		if err = setupBandwidth(d.nlh, endpoint.hostIfName, endpoint.config.Bandwidth); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1311,6 +1319,12 @@ func (d *driver) Leave(nid, eid string) error {
 		}
 	}
 
+	if endpoint.config != nil && endpoint.config.Bandwidth != nil && endpoint.hostIfName != "" {
+		if err = removeBandwidth(d.nlh, endpoint.hostIfName); err != nil {
+			logrus.WithError(err).Warnf("Failed to remove bandwidth limits of endpoint %.7s", endpoint.id)
+		}
+	}
+
 	return nil
 }
 
@@ -1430,7 +1444,7 @@ func (d *driver) link(network *bridgeNetwork, endpoint *bridgeEndpoint, enable b
 				return err
 			}
 
-			l := newLink(parentEndpoint.addr.IP.String(),
+			l := newLink(d.getFirewaller(), parentEndpoint.addr.IP.String(),
 				endpoint.addr.IP.String(),
 				ec.ExposedPorts, network.config.BridgeName)
 			if enable {
@@ -1463,7 +1477,7 @@ func (d *driver) link(network *bridgeNetwork, endpoint *bridgeEndpoint, enable b
 			continue
 		}
 
-		l := newLink(endpoint.addr.IP.String(),
+		l := newLink(d.getFirewaller(), endpoint.addr.IP.String(),
 			childEndpoint.addr.IP.String(),
 			childEndpoint.extConnConfig.ExposedPorts, network.config.BridgeName)
 		if enable {
@@ -1517,6 +1531,12 @@ func parseEndpointOptions(epOptions map[string]interface{}) (*endpointConfigurat
 		}
 	}
 
+	bw, err := parseBandwidthOptions(epOptions)
+	if err != nil {
+		return nil, err
+	}
+	ec.Bandwidth = bw
+
 	return ec, nil
 }
 
@@ -273,6 +273,7 @@ func (ep *bridgeEndpoint) MarshalJSON() ([]byte, error) {
 	epMap["id"] = ep.id
 	epMap["nid"] = ep.nid
 	epMap["SrcName"] = ep.srcName
+	epMap["HostIfName"] = ep.hostIfName
 	epMap["MacAddress"] = ep.macAddress.String()
 	epMap["Addr"] = ep.addr.String()
 	if ep.addrv6 != nil {
@@ -314,6 +315,9 @@ func (ep *bridgeEndpoint) UnmarshalJSON(b []byte) error {
 	ep.id = epMap["id"].(string)
 	ep.nid = epMap["nid"].(string)
 	ep.srcName = epMap["SrcName"].(string)
+	if v, ok := epMap["HostIfName"]; ok {
+		ep.hostIfName = v.(string)
+	}
 	d, _ := json.Marshal(epMap["Config"])
 	if err := json.Unmarshal(d, &ep.config); err != nil {
 		logrus.Warnf("Failed to decode endpoint config %v", err)
@@ -33,7 +33,11 @@ func TestEndpointMarshalling(t *testing.T) {
 		addrv6:     ip2,
 		macAddress: mac,
 		srcName:    "veth123456",
-		config:     &endpointConfiguration{MacAddress: mac},
+		hostIfName: "veth654321",
+		config: &endpointConfiguration{
+			MacAddress: mac,
+			Bandwidth:  &types.Bandwidth{IngressRate: 1 << 20, IngressBurst: 1 << 17},
+		},
 		containerConfig: &containerConfiguration{
 			ParentEndpoints: []string{"one", "due", "three"},
 			ChildEndpoints:  []string{"four", "five", "six"},
@@ -86,7 +90,7 @@ func TestEndpointMarshalling(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if e.id != ee.id || e.nid != ee.nid || e.srcName != ee.srcName || !bytes.Equal(e.macAddress, ee.macAddress) ||
+	if e.id != ee.id || e.nid != ee.nid || e.srcName != ee.srcName || e.hostIfName != ee.hostIfName || !bytes.Equal(e.macAddress, ee.macAddress) ||
 		!types.CompareIPNet(e.addr, ee.addr) || !types.CompareIPNet(e.addrv6, ee.addrv6) ||
 		!compareEpConfig(e.config, ee.config) ||
 		!compareContainerConfig(e.containerConfig, ee.containerConfig) ||
@@ -103,6 +107,10 @@ func compareEpConfig(a, b *endpointConfiguration) bool {
 	if a == nil || b == nil {
 		return false
 	}
+	if (a.Bandwidth == nil) != (b.Bandwidth == nil) ||
+		(a.Bandwidth != nil && *a.Bandwidth != *b.Bandwidth) {
+		return false
+	}
 	return bytes.Equal(a.MacAddress, b.MacAddress)
 }
 
@@ -0,0 +1,128 @@
+//go:build linux
+// +build linux
+
+package bridge
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/docker/docker/libnetwork/firewall"
+	"github.com/docker/docker/libnetwork/iptables"
+	"github.com/sirupsen/logrus"
+)
+
+// firewaller programs the packet filtering and NAT rules of the bridge
+// driver. Which firewaller is used is selected by the FirewallBackend option
+// of the driver, which also selects the firewall.Firewaller programming the
+// rules of the controller.
+type firewaller interface {
+	// init sets up the base rules of the driver for the IP version,
+	// replacing the rules left behind by a previous run of the daemon.
+	init(config *configuration, version iptables.IPVersion) error
+	// dropForward drops forwarded packets of the IP version which are not
+	// accepted by the rules of the driver.
+	dropForward(version iptables.IPVersion) error
+	// setupNetwork sets up the rules of a network for the IP version of the
+	// given masked address of its bridge, and registers the functions
+	// removing them on the network. It also sets up the port mappings of the
+	// network.
+	setupNetwork(n *bridgeNetwork, version iptables.IPVersion, addr *net.IPNet, config *networkConfiguration, i *bridgeInterface) error
+	// isolateNetwork adds or removes the rules isolating the network of the
+	// bridge from other networks.
+	isolateNetwork(version iptables.IPVersion, bridgeName string, enable bool) error
+	// link adds or removes the rules allowing traffic between two linked
+	// containers on a port.
+	link(action iptables.Action, parentIP, childIP net.IP, port int, proto, bridgeName string) error
+}
+
+// newFirewaller returns the firewaller of the firewall backend of the
+// configuration.
+func (d *driver) newFirewaller(config *configuration) (firewaller, error) {
+	switch config.FirewallBackend {
+	case "", firewall.IPTables:
+		return &iptablesFirewall{driver: d}, nil
+	case firewall.NFTables:
+		fw, err := newNFTablesFirewall(config)
+		if err != nil {
+			return nil, err
+		}
+		return fw, nil
+	default:
+		return nil, fmt.Errorf("unknown firewall backend: %s", config.FirewallBackend)
+	}
+}
+
+// getFirewaller returns the firewaller of the driver. The iptables
+// firewaller is used until the driver is configured.
+func (d *driver) getFirewaller() firewaller {
+	d.Lock()
+	defer d.Unlock()
+	if d.firewall == nil {
+		return &iptablesFirewall{driver: d}
+	}
+	return d.firewall
+}
+
+// iptablesFirewall is the firewaller programming rules with iptables.
+type iptablesFirewall struct {
+	driver *driver
+}
+
+func (fw *iptablesFirewall) init(config *configuration, version iptables.IPVersion) error {
+	removeIPChains(version)
+
+	natChain, filterChain, isolationChain1, isolationChain2, err := setupIPChains(config, version)
+	if err != nil {
+		return err
+	}
+
+	// Make sure on firewall reload, first thing being re-played is chains creation
+	cmd := "iptables"
+	if version == iptables.IPv6 {
+		cmd = "ip6tables"
+	}
+	iptables.OnReloaded(func() {
+		logrus.Debugf("Recreating %s chains on firewall reload", cmd)
+		if _, _, _, _, err := setupIPChains(config, version); err != nil {
+			logrus.WithError(err).Errorf("Error reloading %s chains", cmd)
+		}
+	})
+
+	d := fw.driver
+	d.Lock()
+	if version == iptables.IPv6 {
+		d.natChainV6, d.filterChainV6, d.isolationChain1V6, d.isolationChain2V6 = natChain, filterChain, isolationChain1, isolationChain2
+	} else {
+		d.natChain, d.filterChain, d.isolationChain1, d.isolationChain2 = natChain, filterChain, isolationChain1, isolationChain2
+	}
+	d.Unlock()
+	return nil
+}
+
+func (fw *iptablesFirewall) dropForward(version iptables.IPVersion) error {
+	iptable := iptables.GetIptable(version)
+	if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
+		return err
+	}
+	iptables.OnReloaded(func() {
+		logrus.Debug("Setting the default DROP policy on firewall reload")
+		if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
+			logrus.Warnf("Setting the default DROP policy on firewall reload failed, %v", err)
+		}
+	})
+	return nil
+}
+
+func (fw *iptablesFirewall) setupNetwork(n *bridgeNetwork, version iptables.IPVersion, addr *net.IPNet, config *networkConfiguration, i *bridgeInterface) error {
+	return n.setupIPTables(version, addr, config, i)
+}
+
+func (fw *iptablesFirewall) isolateNetwork(version iptables.IPVersion, bridgeName string, enable bool) error {
+	return setINC(version, bridgeName, enable)
+}
+
+func (fw *iptablesFirewall) link(action iptables.Action, parentIP, childIP net.IP, port int, proto, bridgeName string) error {
+	chain := iptables.ChainInfo{Name: DockerChain}
+	return chain.Link(action, parentIP, childIP, port, proto, bridgeName)
+}
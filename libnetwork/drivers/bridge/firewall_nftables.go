@@ -0,0 +1,584 @@
+//go:build linux
+// +build linux
+
+package bridge
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"sync"
+
+	"github.com/docker/docker/libnetwork/iptables"
+	"github.com/docker/docker/libnetwork/nftables"
+	"github.com/sirupsen/logrus"
+)
+
+// nftTable is the name of the nftables tables of the driver, one for each IP
+// version.
+const nftTable = "docker"
+
+// nftablesFirewall is the firewaller programming rules with nftables. The
+// rules of the driver are kept in a table of its own for each IP version. The
+// table and its chains are built once, when the firewall is initialized, and
+// networks, port mappings and links then add and remove the elements of its
+// sets and maps, and the chains of their bridge.
+type nftablesFirewall struct {
+	mu sync.Mutex
+	// hairpin is true when the userland proxy is disabled, and published
+	// ports are reached from containers, and the host, through NAT only.
+	hairpin  bool
+	families map[iptables.IPVersion]*nftFamily
+}
+
+// nftFamily is the state of the table of an IP version.
+type nftFamily struct {
+	// table is nil until the firewall of the IP version is initialized.
+	table       *nftables.Table
+	dropForward bool
+	networks    map[string]*nftNetwork
+	isolated    map[string]bool
+	ports       map[nftPort]bool
+	links       map[nftLink]bool
+}
+
+type nftNetwork struct {
+	bridge     string
+	addr       *net.IPNet
+	hostIP     net.IP
+	internal   bool
+	icc        bool
+	masquerade bool
+}
+
+// nftPort is a port mapping. An empty hostIP maps the port on all the
+// addresses of the host.
+type nftPort struct {
+	bridge   string
+	hostIP   string
+	hostPort int
+	proto    string
+	destIP   string
+	destPort int
+}
+
+type nftLink struct {
+	bridge   string
+	parentIP string
+	childIP  string
+	port     int
+	proto    string
+}
+
+func newNFTablesFirewall(config *configuration) (*nftablesFirewall, error) {
+	if err := nftables.Available(); err != nil {
+		return nil, err
+	}
+	return &nftablesFirewall{
+		hairpin: !config.EnableUserlandProxy,
+		families: map[iptables.IPVersion]*nftFamily{
+			iptables.IPv4: newNFTFamily(),
+			iptables.IPv6: newNFTFamily(),
+		},
+	}, nil
+}
+
+func newNFTFamily() *nftFamily {
+	return &nftFamily{
+		networks: make(map[string]*nftNetwork),
+		isolated: make(map[string]bool),
+		ports:    make(map[nftPort]bool),
+		links:    make(map[nftLink]bool),
+	}
+}
+
+func (fw *nftablesFirewall) init(config *configuration, version iptables.IPVersion) error {
+	// Remove the rules left behind by the iptables backend, so that switching
+	// backends does not leave containers reachable through stale rules.
+	cmd := "iptables"
+	if version == iptables.IPv6 {
+		cmd = "ip6tables"
+	}
+	if _, err := exec.LookPath(cmd); err == nil {
+		removeIPChains(version)
+	}
+
+	family := nftables.IPv4
+	if version == iptables.IPv6 {
+		family = nftables.IPv6
+	}
+	t := nftables.NewTable(family, nftTable)
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	f := fw.families[version]
+	fw.program(t, f)
+	if err := t.Flush(); err != nil {
+		return fmt.Errorf("failed to program nftables table %s: %v", nftTable, err)
+	}
+	f.table = t
+	return nil
+}
+
+// program queues the replacement of the table with the chains, sets and maps
+// of the driver, and the chains and elements of the networks, port mappings
+// and links of the state of the IP version.
+func (fw *nftablesFirewall) program(t *nftables.Table, f *nftFamily) {
+	var (
+		addrType = t.Family().AddrType()
+		loopback = &net.IPNet{IP: net.IPv4(127, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	)
+	if t.Family() == nftables.IPv6 {
+		loopback = &net.IPNet{IP: net.IPv6loopback, Mask: net.CIDRMask(128, 128)}
+	}
+
+	t.Reset()
+
+	// Published ports, by host address, protocol and port, to the address
+	// and port of the container.
+	t.AddMap("ports", nftables.Concat(addrType, nftables.TypeInetProto, nftables.TypeInetService), nftables.Concat(addrType, nftables.TypeInetService))
+	t.AddMap("ports-any", nftables.Concat(nftables.TypeInetProto, nftables.TypeInetService), nftables.Concat(addrType, nftables.TypeInetService))
+	// Addresses, protocols and ports of containers which are published.
+	t.AddSet("published", nftables.Concat(addrType, nftables.TypeInetProto, nftables.TypeInetService))
+	// Published ports of containers reached by the container itself.
+	t.AddSet("hairpin", nftables.Concat(addrType, addrType, nftables.TypeInetProto, nftables.TypeInetService))
+	// Links between containers, by bridge, parent and child addresses,
+	// protocol and port.
+	t.AddSet("links", nftables.Concat(nftables.TypeIfName, addrType, addrType, nftables.TypeInetProto, nftables.TypeInetService))
+	// Bridges whose traffic is not translated to published ports, because
+	// they reach them through the userland proxy.
+	t.AddSet("no-dnat", nftables.TypeIfName)
+	// Bridges isolated from the bridges of other networks.
+	t.AddSet("isolated", nftables.TypeIfName)
+	// Bridges of the networks which are not internal.
+	t.AddSet("outbound", nftables.TypeIfName)
+	// Chains filtering the forwarded traffic of each bridge.
+	t.AddMap("forward-in", nftables.TypeIfName, nftables.TypeVerdict)
+	t.AddMap("forward-out", nftables.TypeIfName, nftables.TypeVerdict)
+
+	t.AddBaseChain("prerouting", nftables.NATPrerouting, false)
+	t.AddBaseChain("output", nftables.NATOutput, false)
+	t.AddChain("dnat")
+	t.AddBaseChain("postrouting", nftables.NATPostrouting, false)
+	t.AddBaseChain("forward", nftables.FilterForward, f.dropForward)
+
+	t.AddRule("prerouting", "", nftables.LocalAddr(nftables.DstAddr), nftables.Jump("dnat"))
+	if fw.hairpin {
+		t.AddRule("output", "", nftables.LocalAddr(nftables.DstAddr), nftables.Jump("dnat"))
+	} else {
+		t.AddRule("output", "", t.InNet(nftables.DstAddr, loopback, true), nftables.LocalAddr(nftables.DstAddr), nftables.Jump("dnat"))
+	}
+	t.AddRule("dnat", "", t.Lookup("no-dnat", nftables.IIFName), nftables.Return())
+	t.AddRule("dnat", "", t.DNATMap("ports", nftables.DstAddr, nftables.L4Proto, nftables.DstPort))
+	t.AddRule("dnat", "", t.DNATMap("ports-any", nftables.L4Proto, nftables.DstPort))
+	t.AddRule("postrouting", "", t.Lookup("hairpin", nftables.SrcAddr, nftables.DstAddr, nftables.L4Proto, nftables.DstPort), nftables.Masquerade())
+	// The chains of the bridges drop the traffic between networks, and out
+	// of internal networks, before any traffic is accepted.
+	t.AddRule("forward", "", t.VMap("forward-in", nftables.IIFName))
+	t.AddRule("forward", "", t.VMap("forward-out", nftables.OIFName))
+	t.AddRule("forward", "", t.Lookup("outbound", nftables.IIFName), nftables.Accept())
+
+	for _, nw := range f.networks {
+		fw.addNetworkRules(t, nw)
+	}
+	for bridge := range f.isolated {
+		t.AddElements("isolated", nftables.Element{Key: nftables.IfName(bridge)})
+	}
+	ports := make([]nftPort, 0, len(f.ports))
+	for p := range f.ports {
+		ports = append(ports, p)
+	}
+	addPortElements(t, ports)
+	for l := range f.links {
+		t.AddElements("links", linkElement(t, l))
+	}
+}
+
+// addNetworkRules queues the addition of the chains, rules and elements of a
+// network.
+func (fw *nftablesFirewall) addNetworkRules(t *nftables.Table, nw *nftNetwork) {
+	var (
+		br  = nftables.IfName(nw.bridge)
+		in  = "forward-in-" + nw.bridge
+		out = "forward-out-" + nw.bridge
+	)
+
+	t.AddChain(in)
+	t.AddChain(out)
+	t.AddRule(in, "", t.Lookup("isolated", nftables.IIFName), t.Lookup("isolated", nftables.OIFName), t.Cmp(nftables.OIFName, br, true), nftables.Drop())
+	if nw.internal {
+		t.AddRule(in, "", t.InNet(nftables.DstAddr, nw.addr, true), nftables.Drop())
+		t.AddRule(out, "", t.InNet(nftables.SrcAddr, nw.addr, true), nftables.Drop())
+	} else {
+		t.AddRule(out, "", nftables.RelatedEstablished(), nftables.Accept())
+		t.AddRule(out, "", t.Cmp(nftables.IIFName, br, true), t.Lookup("published", nftables.DstAddr, nftables.L4Proto, nftables.DstPort), nftables.Accept())
+	}
+	t.AddRule(out, "", t.Lookup("links", nftables.IIFName, nftables.SrcAddr, nftables.DstAddr, nftables.L4Proto, nftables.DstPort), nftables.Accept())
+	t.AddRule(out, "", t.Lookup("links", nftables.IIFName, nftables.DstAddr, nftables.SrcAddr, nftables.L4Proto, nftables.SrcPort), nftables.Accept())
+	icc := nftables.Drop()
+	if nw.icc {
+		icc = nftables.Accept()
+	}
+	t.AddRule(out, "", t.Cmp(nftables.IIFName, br, false), icc)
+
+	if !nw.internal {
+		nat := nftables.Masquerade()
+		if nw.hostIP != nil {
+			nat = t.SNAT(nw.hostIP, 0)
+		}
+		if nw.masquerade {
+			t.AddRule("postrouting", nw.bridge, t.InNet(nftables.SrcAddr, nw.addr, false), t.Cmp(nftables.OIFName, br, true), nat)
+		}
+		if fw.hairpin {
+			t.AddRule("postrouting", nw.bridge, t.Cmp(nftables.OIFName, br, false), nftables.LocalAddr(nftables.SrcAddr), nat)
+		}
+	}
+
+	t.AddElements("forward-in", nftables.Element{Key: br, Jump: in})
+	t.AddElements("forward-out", nftables.Element{Key: br, Jump: out})
+	for set, ok := range fw.networkSets(nw) {
+		if ok {
+			t.AddElements(set, nftables.Element{Key: br})
+		}
+	}
+}
+
+// deleteNetworkRules queues the deletion of the chains, rules and elements of
+// a network.
+func (fw *nftablesFirewall) deleteNetworkRules(t *nftables.Table, nw *nftNetwork) {
+	br := nftables.IfName(nw.bridge)
+	t.DeleteRules("postrouting", nw.bridge)
+	t.DeleteElements("forward-in", nftables.Element{Key: br})
+	t.DeleteElements("forward-out", nftables.Element{Key: br})
+	for set, ok := range fw.networkSets(nw) {
+		if ok {
+			t.DeleteElements(set, nftables.Element{Key: br})
+		}
+	}
+	t.DeleteChain("forward-in-" + nw.bridge)
+	t.DeleteChain("forward-out-" + nw.bridge)
+}
+
+// networkSets returns whether the bridge of a network is an element of each
+// of the sets of bridges, other than the isolated one.
+func (fw *nftablesFirewall) networkSets(nw *nftNetwork) map[string]bool {
+	return map[string]bool{
+		"no-dnat":  !nw.internal && nw.masquerade && !fw.hairpin,
+		"outbound": !nw.internal,
+	}
+}
+
+func (fw *nftablesFirewall) dropForward(version iptables.IPVersion) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	f := fw.families[version]
+	if t := f.table; t != nil {
+		t.AddBaseChain("forward", nftables.FilterForward, true)
+		if err := t.Flush(); err != nil {
+			return err
+		}
+	}
+	f.dropForward = true
+	return nil
+}
+
+func (fw *nftablesFirewall) setupNetwork(n *bridgeNetwork, version iptables.IPVersion, addr *net.IPNet, config *networkConfiguration, i *bridgeInterface) error {
+	nw := &nftNetwork{
+		bridge:     config.BridgeName,
+		addr:       addr,
+		internal:   config.Internal,
+		icc:        config.EnableICC,
+		masquerade: config.EnableIPMasquerade,
+	}
+	if config.HostIP != nil && (config.HostIP.To4() != nil) == (version == iptables.IPv4) {
+		nw.hostIP = config.HostIP
+	}
+
+	fw.mu.Lock()
+	err := fw.addNetwork(version, nw)
+	fw.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("Failed to setup nftables rules: %v", err)
+	}
+
+	n.registerIptCleanFunc(func() error {
+		fw.mu.Lock()
+		defer fw.mu.Unlock()
+		return fw.removeNetwork(version, nw.bridge)
+	})
+
+	if !config.Internal {
+		fwd := &nftablesForwarder{fw: fw, version: version}
+		if version == iptables.IPv4 {
+			n.portMapper.SetForwarder(fwd, n.getNetworkBridgeName())
+		} else {
+			n.portMapperV6.SetForwarder(fwd, n.getNetworkBridgeName())
+		}
+	}
+	return nil
+}
+
+// addNetwork adds a network to the state of the IP version, replacing the
+// network with the same bridge if any. It is expected that callers of this
+// function hold the lock.
+func (fw *nftablesFirewall) addNetwork(version iptables.IPVersion, nw *nftNetwork) error {
+	f := fw.families[version]
+	if t := f.table; t != nil {
+		if prev, ok := f.networks[nw.bridge]; ok {
+			fw.deleteNetworkRules(t, prev)
+		}
+		fw.addNetworkRules(t, nw)
+		if err := t.Flush(); err != nil {
+			return err
+		}
+	}
+	f.networks[nw.bridge] = nw
+	return nil
+}
+
+// removeNetwork removes a network, and its port mappings and links, from the
+// state of the IP version. It is expected that callers of this function hold
+// the lock.
+func (fw *nftablesFirewall) removeNetwork(version iptables.IPVersion, bridge string) error {
+	f := fw.families[version]
+	nw, ok := f.networks[bridge]
+	if !ok {
+		return nil
+	}
+	var (
+		ports []nftPort
+		links []nftLink
+	)
+	for p := range f.ports {
+		if p.bridge == bridge {
+			ports = append(ports, p)
+		}
+	}
+	for l := range f.links {
+		if l.bridge == bridge {
+			links = append(links, l)
+		}
+	}
+
+	if t := f.table; t != nil {
+		deletePortElements(t, f, ports)
+		for _, l := range links {
+			t.DeleteElements("links", linkElement(t, l))
+		}
+		fw.deleteNetworkRules(t, nw)
+		if err := t.Flush(); err != nil {
+			return err
+		}
+	}
+	delete(f.networks, bridge)
+	for _, p := range ports {
+		delete(f.ports, p)
+	}
+	for _, l := range links {
+		delete(f.links, l)
+	}
+	return nil
+}
+
+func (fw *nftablesFirewall) isolateNetwork(version iptables.IPVersion, bridgeName string, enable bool) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	f := fw.families[version]
+	if f.isolated[bridgeName] == enable {
+		return nil
+	}
+	if t := f.table; t != nil {
+		e := nftables.Element{Key: nftables.IfName(bridgeName)}
+		if enable {
+			t.AddElements("isolated", e)
+		} else {
+			t.DeleteElements("isolated", e)
+		}
+		if err := t.Flush(); err != nil {
+			if !enable {
+				logrus.Warnf("unable to remove inter-network communication rule: %v", err)
+				return nil
+			}
+			return fmt.Errorf("unable to add inter-network communication rule: %v", err)
+		}
+	}
+	if enable {
+		f.isolated[bridgeName] = true
+	} else {
+		delete(f.isolated, bridgeName)
+	}
+	return nil
+}
+
+func (fw *nftablesFirewall) link(action iptables.Action, parentIP, childIP net.IP, port int, proto, bridgeName string) error {
+	if _, err := nftables.Proto(proto); err != nil {
+		return err
+	}
+	l := nftLink{
+		bridge:   bridgeName,
+		parentIP: parentIP.String(),
+		childIP:  childIP.String(),
+		port:     port,
+		proto:    proto,
+	}
+	version := iptables.IPv4
+	if parentIP.To4() == nil {
+		version = iptables.IPv6
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	f := fw.families[version]
+	del := action == iptables.Delete
+	if f.links[l] != del {
+		return nil
+	}
+	if t := f.table; t != nil {
+		if del {
+			t.DeleteElements("links", linkElement(t, l))
+		} else {
+			t.AddElements("links", linkElement(t, l))
+		}
+		if err := t.Flush(); err != nil {
+			return err
+		}
+	}
+	if del {
+		delete(f.links, l)
+	} else {
+		f.links[l] = true
+	}
+	return nil
+}
+
+// linkElement returns the element of the links set of a link. The protocol of
+// the link is expected to be valid.
+func linkElement(t *nftables.Table, l nftLink) nftables.Element {
+	proto, _ := nftables.Proto(l.proto)
+	return nftables.Element{Key: nftables.Key(
+		nftables.IfName(l.bridge),
+		t.Addr(net.ParseIP(l.parentIP)),
+		t.Addr(net.ParseIP(l.childIP)),
+		proto,
+		nftables.Port(l.port),
+	)}
+}
+
+// nftablesForwarder programs the rules of the port mappings of a portmapper.
+type nftablesForwarder struct {
+	fw      *nftablesFirewall
+	version iptables.IPVersion
+}
+
+// Forward adds or removes the rules forwarding traffic of a host port to a
+// port of a container.
+func (f *nftablesForwarder) Forward(action iptables.Action, ip net.IP, port int, proto, destAddr string, destPort int, bridgeName string) error {
+	dest := net.ParseIP(destAddr)
+	if dest == nil || (dest.To4() != nil) != (f.version == iptables.IPv4) {
+		return nil
+	}
+	if _, err := nftables.Proto(proto); err != nil {
+		return err
+	}
+	p := nftPort{
+		bridge:   bridgeName,
+		hostPort: port,
+		proto:    proto,
+		destIP:   dest.String(),
+		destPort: destPort,
+	}
+	if ip != nil && !ip.IsUnspecified() {
+		p.hostIP = ip.String()
+	}
+
+	f.fw.mu.Lock()
+	defer f.fw.mu.Unlock()
+	fam := f.fw.families[f.version]
+	del := action == iptables.Delete
+	if fam.ports[p] != del {
+		return nil
+	}
+	if t := fam.table; t != nil {
+		if del {
+			deletePortElements(t, fam, []nftPort{p})
+		} else {
+			addPortElements(t, []nftPort{p})
+		}
+		if err := t.Flush(); err != nil {
+			return err
+		}
+	}
+	if del {
+		delete(fam.ports, p)
+	} else {
+		fam.ports[p] = true
+	}
+	return nil
+}
+
+// nftPortElements are the elements of the ports maps, and of the published
+// and hairpin sets, of a port mapping. The published and hairpin elements are
+// shared by the port mappings to the same port of a container.
+type nftPortElements struct {
+	ports     string
+	mapping   nftables.Element
+	published nftables.Element
+	hairpin   nftables.Element
+}
+
+// portElements returns the elements of a port mapping. The protocol of the
+// port mapping is expected to be valid.
+func portElements(t *nftables.Table, p nftPort) nftPortElements {
+	var (
+		proto, _ = nftables.Proto(p.proto)
+		dest     = t.Addr(net.ParseIP(p.destIP))
+		e        = nftPortElements{ports: "ports-any"}
+	)
+	e.mapping = nftables.Element{
+		Key:   nftables.Key(proto, nftables.Port(p.hostPort)),
+		Value: nftables.Key(dest, nftables.Port(p.destPort)),
+	}
+	if p.hostIP != "" {
+		e.ports = "ports"
+		e.mapping.Key = nftables.Key(t.Addr(net.ParseIP(p.hostIP)), proto, nftables.Port(p.hostPort))
+	}
+	e.published = nftables.Element{Key: nftables.Key(dest, proto, nftables.Port(p.destPort))}
+	e.hairpin = nftables.Element{Key: nftables.Key(dest, dest, proto, nftables.Port(p.destPort))}
+	return e
+}
+
+// addPortElements queues the addition of the elements of port mappings.
+func addPortElements(t *nftables.Table, ports []nftPort) {
+	for _, p := range ports {
+		e := portElements(t, p)
+		t.AddElements(e.ports, e.mapping)
+		// Adding an element which is already in a set is a no-op.
+		t.AddElements("published", e.published)
+		t.AddElements("hairpin", e.hairpin)
+	}
+}
+
+// deletePortElements queues the deletion of the elements of port mappings of
+// the state of the IP version. The published and hairpin elements are kept
+// until no other port mapping of the state shares them.
+func deletePortElements(t *nftables.Table, f *nftFamily, ports []nftPort) {
+	deleted := make(map[nftPort]bool, len(ports))
+	for _, p := range ports {
+		deleted[p] = true
+	}
+	shared := make(map[string]bool)
+	for p := range f.ports {
+		if !deleted[p] {
+			shared[string(portElements(t, p).published.Key)] = true
+		}
+	}
+	for _, p := range ports {
+		e := portElements(t, p)
+		t.DeleteElements(e.ports, e.mapping)
+		if key := string(e.published.Key); !shared[key] {
+			shared[key] = true
+			t.DeleteElements("published", e.published)
+			t.DeleteElements("hairpin", e.hairpin)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+//go:build linux
+// +build linux
+
+package bridge
+
+import (
+	"net"
+	"testing"
+
+	"github.com/docker/docker/libnetwork/iptables"
+	"github.com/docker/docker/libnetwork/nftables"
+	"github.com/docker/docker/libnetwork/testutils"
+	gnftables "github.com/google/nftables"
+)
+
+func newTestNFTablesFirewall(t *testing.T, config *configuration, version iptables.IPVersion) *nftablesFirewall {
+	t.Helper()
+	if err := nftables.Available(); err != nil {
+		t.Skip(err)
+	}
+	fw, err := newNFTablesFirewall(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.init(config, version); err != nil {
+		t.Fatal(err)
+	}
+	return fw
+}
+
+func nftTestTable(version iptables.IPVersion) *gnftables.Table {
+	family := gnftables.TableFamilyIPv4
+	if version == iptables.IPv6 {
+		family = gnftables.TableFamilyIPv6
+	}
+	return &gnftables.Table{Family: family, Name: nftTable}
+}
+
+// nftChains returns the names of the chains of the table of the driver.
+func nftChains(t *testing.T, version iptables.IPVersion) map[string]bool {
+	t.Helper()
+	chains, err := (&gnftables.Conn{}).ListChains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := nftTestTable(version)
+	names := make(map[string]bool)
+	for _, c := range chains {
+		if c.Table.Name == table.Name && c.Table.Family == table.Family {
+			names[c.Name] = true
+		}
+	}
+	return names
+}
+
+// nftElements returns the keys of the elements of a set of the table of the
+// driver.
+func nftElements(t *testing.T, version iptables.IPVersion, set string) map[string]bool {
+	t.Helper()
+	elements, err := (&gnftables.Conn{}).GetSetElements(&gnftables.Set{Table: nftTestTable(version), Name: set})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]bool)
+	for _, e := range elements {
+		keys[string(e.Key)] = true
+	}
+	return keys
+}
+
+// nftRules returns the number of rules of a chain of the table of the driver.
+func nftRules(t *testing.T, version iptables.IPVersion, chain string) int {
+	t.Helper()
+	table := nftTestTable(version)
+	rules, err := (&gnftables.Conn{}).GetRules(table, &gnftables.Chain{Table: table, Name: chain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(rules)
+}
+
+func TestNFTablesFirewall(t *testing.T) {
+	for _, tc := range []struct {
+		version  iptables.IPVersion
+		addr     *net.IPNet
+		parentIP net.IP
+		childIP  net.IP
+	}{
+		{
+			version:  iptables.IPv4,
+			addr:     &net.IPNet{IP: net.ParseIP("172.17.0.0").To4(), Mask: net.CIDRMask(16, 32)},
+			parentIP: net.ParseIP("172.17.0.2"),
+			childIP:  net.ParseIP("172.17.0.3"),
+		},
+		{
+			version:  iptables.IPv6,
+			addr:     &net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(64, 128)},
+			parentIP: net.ParseIP("fd00::2"),
+			childIP:  net.ParseIP("fd00::3"),
+		},
+	} {
+		t.Run(string(tc.version), func(t *testing.T) {
+			defer testutils.SetupTestOSContext(t)()
+			config := &configuration{EnableUserlandProxy: true}
+			fw := newTestNFTablesFirewall(t, config, tc.version)
+			table := fw.families[tc.version].table
+			br := nftables.IfName("docker0")
+
+			nw := &nftNetwork{bridge: "docker0", addr: tc.addr, icc: true, masquerade: true}
+			if err := fw.addNetwork(tc.version, nw); err != nil {
+				t.Fatal(err)
+			}
+			if chains := nftChains(t, tc.version); !chains["forward-in-docker0"] || !chains["forward-out-docker0"] {
+				t.Fatalf("Expected the chains of the bridge, got %v", chains)
+			}
+			for _, set := range []string{"forward-in", "forward-out", "outbound", "no-dnat"} {
+				if !nftElements(t, tc.version, set)[string(br)] {
+					t.Fatalf("Expected the bridge in set %s", set)
+				}
+			}
+			// The hairpin masquerade rule, and the masquerade rule of the
+			// network.
+			if n := nftRules(t, tc.version, "postrouting"); n != 2 {
+				t.Fatalf("Expected 2 postrouting rules, got %d", n)
+			}
+
+			fwd := &nftablesForwarder{fw: fw, version: tc.version}
+			for _, hostPort := range []int{8080, 8081} {
+				if err := fwd.Forward(iptables.Append, nil, hostPort, "tcp", tc.parentIP.String(), 80, "docker0"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Port mappings of the other IP version are ignored.
+			other := "192.0.2.1"
+			if tc.version == iptables.IPv4 {
+				other = "2001:db8::1"
+			}
+			if err := fwd.Forward(iptables.Append, nil, 8082, "tcp", other, 80, "docker0"); err != nil {
+				t.Fatal(err)
+			}
+			tcp, _ := nftables.Proto("tcp")
+			dest := table.Addr(tc.parentIP)
+			if ports := nftElements(t, tc.version, "ports-any"); len(ports) != 2 || !ports[string(nftables.Key(tcp, nftables.Port(8080)))] {
+				t.Fatalf("Expected the port mappings in map ports-any, got %v", ports)
+			}
+			published := string(nftables.Key(dest, tcp, nftables.Port(80)))
+			if !nftElements(t, tc.version, "published")[published] {
+				t.Fatal("Expected the published port of the container")
+			}
+			if err := fwd.Forward(iptables.Delete, nil, 8080, "tcp", tc.parentIP.String(), 80, "docker0"); err != nil {
+				t.Fatal(err)
+			}
+			if !nftElements(t, tc.version, "published")[published] {
+				t.Fatal("Expected the published port of the container to be kept for the other port mapping")
+			}
+
+			if err := fw.link(iptables.Append, tc.parentIP, tc.childIP, 5432, "tcp", "docker0"); err != nil {
+				t.Fatal(err)
+			}
+			link := string(nftables.Key(br, dest, table.Addr(tc.childIP), tcp, nftables.Port(5432)))
+			if !nftElements(t, tc.version, "links")[link] {
+				t.Fatal("Expected the link in set links")
+			}
+			// Adding a link twice is a no-op.
+			if err := fw.link(iptables.Append, tc.parentIP, tc.childIP, 5432, "tcp", "docker0"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := fw.isolateNetwork(tc.version, "docker0", true); err != nil {
+				t.Fatal(err)
+			}
+			if !nftElements(t, tc.version, "isolated")[string(br)] {
+				t.Fatal("Expected the bridge in set isolated")
+			}
+			if err := fw.dropForward(tc.version); err != nil {
+				t.Fatal(err)
+			}
+
+			// Initializing the firewall again programs the table from the
+			// state, instead of adding to the table.
+			if err := fw.init(config, tc.version); err != nil {
+				t.Fatal(err)
+			}
+			if n := nftRules(t, tc.version, "postrouting"); n != 2 {
+				t.Fatalf("Expected 2 postrouting rules, got %d", n)
+			}
+			if !nftElements(t, tc.version, "links")[link] {
+				t.Fatal("Expected the link in set links")
+			}
+
+			if err := fw.removeNetwork(tc.version, "docker0"); err != nil {
+				t.Fatal(err)
+			}
+			if chains := nftChains(t, tc.version); chains["forward-in-docker0"] || chains["forward-out-docker0"] {
+				t.Fatalf("Unexpected chains of the removed bridge: %v", chains)
+			}
+			for _, set := range []string{"ports-any", "published", "hairpin", "links", "forward-in", "forward-out", "outbound", "no-dnat"} {
+				if elements := nftElements(t, tc.version, set); len(elements) != 0 {
+					t.Fatalf("Unexpected elements in set %s after removing the network: %v", set, elements)
+				}
+			}
+			if n := nftRules(t, tc.version, "postrouting"); n != 1 {
+				t.Fatalf("Expected 1 postrouting rule, got %d", n)
+			}
+			// The isolation of the bridge is removed by the driver, and not
+			// with the network.
+			if err := fw.isolateNetwork(tc.version, "docker0", false); err != nil {
+				t.Fatal(err)
+			}
+			if elements := nftElements(t, tc.version, "isolated"); len(elements) != 0 {
+				t.Fatalf("Unexpected elements in set isolated: %v", elements)
+			}
+		})
+	}
+}
+
+func TestNFTablesFirewallHairpin(t *testing.T) {
+	defer testutils.SetupTestOSContext(t)()
+	config := &configuration{EnableUserlandProxy: false}
+	fw := newTestNFTablesFirewall(t, config, iptables.IPv4)
+
+	nw := &nftNetwork{
+		bridge:     "docker0",
+		addr:       &net.IPNet{IP: net.ParseIP("172.17.0.0").To4(), Mask: net.CIDRMask(16, 32)},
+		hostIP:     net.ParseIP("192.0.2.1"),
+		masquerade: true,
+	}
+	if err := fw.addNetwork(iptables.IPv4, nw); err != nil {
+		t.Fatal(err)
+	}
+	// Without the userland proxy, the traffic of the bridge is translated to
+	// published ports, and the traffic of the host to the bridge is
+	// translated to the address of the host.
+	if elements := nftElements(t, iptables.IPv4, "no-dnat"); len(elements) != 0 {
+		t.Fatalf("Unexpected elements in set no-dnat: %v", elements)
+	}
+	if n := nftRules(t, iptables.IPv4, "postrouting"); n != 3 {
+		t.Fatalf("Expected 3 postrouting rules, got %d", n)
+	}
+
+	fwd := &nftablesForwarder{fw: fw, version: iptables.IPv4}
+	if err := fwd.Forward(iptables.Append, net.ParseIP("127.0.0.1"), 5353, "udp", "172.17.0.3", 53, "docker0"); err != nil {
+		t.Fatal(err)
+	}
+	udp, _ := nftables.Proto("udp")
+	table := fw.families[iptables.IPv4].table
+	key := nftables.Key(table.Addr(net.ParseIP("127.0.0.1")), udp, nftables.Port(5353))
+	if !nftElements(t, iptables.IPv4, "ports")[string(key)] {
+		t.Fatal("Expected the port mapping in map ports")
+	}
+	if err := fwd.Forward(iptables.Append, nil, 5353, "icmp", "172.17.0.3", 53, "docker0"); err == nil {
+		t.Fatal("Expected an error for an unsupported protocol")
+	}
+}
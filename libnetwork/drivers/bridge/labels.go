@@ -18,4 +18,20 @@ const (
 
 	// DefaultBridge label
 	DefaultBridge = "com.docker.network.bridge.default_bridge"
+
+	// IngressRate label for the rate limit of the traffic received by the
+	// container on an endpoint, in bytes per second
+	IngressRate = "com.docker.network.bridge.ingress_rate"
+
+	// IngressBurst label for the burst size of the traffic received by the
+	// container on an endpoint, in bytes
+	IngressBurst = "com.docker.network.bridge.ingress_burst"
+
+	// EgressRate label for the rate limit of the traffic sent by the
+	// container on an endpoint, in bytes per second
+	EgressRate = "com.docker.network.bridge.egress_rate"
+
+	// EgressBurst label for the burst size of the traffic sent by the
+	// container on an endpoint, in bytes
+	EgressBurst = "com.docker.network.bridge.egress_burst"
 )
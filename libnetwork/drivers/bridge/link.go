@@ -13,6 +13,7 @@ import (
 )
 
 type link struct {
+	fw       firewaller
 	parentIP string
 	childIP  string
 	ports    []types.TransportPort
@@ -23,8 +24,9 @@ func (l *link) String() string {
 	return fmt.Sprintf("%s <-> %s [%v] on %s", l.parentIP, l.childIP, l.ports, l.bridge)
 }
 
-func newLink(parentIP, childIP string, ports []types.TransportPort, bridge string) *link {
+func newLink(fw firewaller, parentIP, childIP string, ports []types.TransportPort, bridge string) *link {
 	return &link{
+		fw:       fw,
 		childIP:  childIP,
 		parentIP: parentIP,
 		ports:    ports,
@@ -36,7 +38,7 @@ func newLink(parentIP, childIP string, ports []types.TransportPort, bridge strin
 func (l *link) Enable() error {
 	// -A == iptables append flag
 	linkFunction := func() error {
-		return linkContainers("-A", l.parentIP, l.childIP, l.ports, l.bridge, false)
+		return linkContainers(l.fw, "-A", l.parentIP, l.childIP, l.ports, l.bridge, false)
 	}
 
 	iptables.OnReloaded(func() { linkFunction() })
@@ -45,7 +47,7 @@ func (l *link) Enable() error {
 
 func (l *link) Disable() {
 	// -D == iptables delete flag
-	err := linkContainers("-D", l.parentIP, l.childIP, l.ports, l.bridge, true)
+	err := linkContainers(l.fw, "-D", l.parentIP, l.childIP, l.ports, l.bridge, true)
 	if err != nil {
 		logrus.Errorf("Error removing IPTables rules for a link %s due to %s", l.String(), err.Error())
 	}
@@ -53,7 +55,7 @@ func (l *link) Disable() {
 	// that returns typed errors
 }
 
-func linkContainers(action, parentIP, childIP string, ports []types.TransportPort, bridge string,
+func linkContainers(fw firewaller, action, parentIP, childIP string, ports []types.TransportPort, bridge string,
 	ignoreErrors bool) error {
 	var nfAction iptables.Action
 
@@ -77,9 +79,8 @@ func linkContainers(action, parentIP, childIP string, ports []types.TransportPor
 		return InvalidLinkIPAddrError(childIP)
 	}
 
-	chain := iptables.ChainInfo{Name: DockerChain}
 	for _, port := range ports {
-		err := chain.Link(nfAction, ip1, ip2, int(port.Port), port.Proto.String(), bridge)
+		err := fw.link(nfAction, ip1, ip2, int(port.Port), port.Proto.String(), bridge)
 		if !ignoreErrors && err != nil {
 			return err
 		}
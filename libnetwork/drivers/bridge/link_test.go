@@ -20,7 +20,7 @@ func getPorts() []types.TransportPort {
 func TestLinkNew(t *testing.T) {
 	ports := getPorts()
 
-	link := newLink("172.0.17.3", "172.0.17.2", ports, "docker0")
+	link := newLink(&iptablesFirewall{}, "172.0.17.3", "172.0.17.2", ports, "docker0")
 
 	if link == nil {
 		t.FailNow()
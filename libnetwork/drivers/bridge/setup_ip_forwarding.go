@@ -24,7 +24,7 @@ func configureIPForwarding(enable bool) error {
 	return os.WriteFile(ipv4ForwardConf, []byte{val, '\n'}, ipv4ForwardConfPerm)
 }
 
-func setupIPForwarding(enableIPTables bool, enableIP6Tables bool) error {
+func setupIPForwarding(fw firewaller, enableIPTables bool, enableIP6Tables bool) error {
 	// Get current IPv4 forward setup
 	ipv4ForwardData, err := os.ReadFile(ipv4ForwardConf)
 	if err != nil {
@@ -40,34 +40,20 @@ func setupIPForwarding(enableIPTables bool, enableIP6Tables bool) error {
 		// When enabling ip_forward set the default policy on forward chain to
 		// drop only if the daemon option iptables is not set to false.
 		if enableIPTables {
-			iptable := iptables.GetIptable(iptables.IPv4)
-			if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
+			if err := fw.dropForward(iptables.IPv4); err != nil {
 				if err := configureIPForwarding(false); err != nil {
 					logrus.Errorf("Disabling IP forwarding failed, %v", err)
 				}
 				return err
 			}
-			iptables.OnReloaded(func() {
-				logrus.Debug("Setting the default DROP policy on firewall reload")
-				if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
-					logrus.Warnf("Setting the default DROP policy on firewall reload failed, %v", err)
-				}
-			})
 		}
 	}
 
 	// add only iptables rules - forwarding is handled by setupIPv6Forwarding in setup_ipv6
 	if enableIP6Tables {
-		iptable := iptables.GetIptable(iptables.IPv6)
-		if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
+		if err := fw.dropForward(iptables.IPv6); err != nil {
 			logrus.Warnf("Setting the default DROP policy on firewall reload failed, %v", err)
 		}
-		iptables.OnReloaded(func() {
-			logrus.Debug("Setting the default DROP policy on firewall reload")
-			if err := iptable.SetDefaultPolicy(iptables.Filter, "FORWARD", iptables.Drop); err != nil {
-				logrus.Warnf("Setting the default DROP policy on firewall reload failed, %v", err)
-			}
-		})
 	}
 
 	return nil
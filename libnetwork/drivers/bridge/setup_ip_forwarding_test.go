@@ -20,7 +20,7 @@ func TestSetupIPForwarding(t *testing.T) {
 	}
 
 	// Set IP Forwarding
-	if err := setupIPForwarding(true, true); err != nil {
+	if err := setupIPForwarding(&iptablesFirewall{}, true, true); err != nil {
 		t.Fatalf("Failed to setup IP forwarding: %v", err)
 	}
 
@@ -113,7 +113,7 @@ func (n *bridgeNetwork) setupIP4Tables(config *networkConfiguration, i *bridgeIn
 		IP:   i.bridgeIPv4.IP.Mask(i.bridgeIPv4.Mask),
 		Mask: i.bridgeIPv4.Mask,
 	}
-	return n.setupIPTables(iptables.IPv4, maskedAddrv4, config, i)
+	return d.getFirewaller().setupNetwork(n, iptables.IPv4, maskedAddrv4, config, i)
 }
 
 func (n *bridgeNetwork) setupIP6Tables(config *networkConfiguration, i *bridgeInterface) error {
@@ -132,7 +132,7 @@ func (n *bridgeNetwork) setupIP6Tables(config *networkConfiguration, i *bridgeIn
 		Mask: i.bridgeIPv6.Mask,
 	}
 
-	return n.setupIPTables(iptables.IPv6, maskedAddrv6, config, i)
+	return d.getFirewaller().setupNetwork(n, iptables.IPv6, maskedAddrv6, config, i)
 }
 
 func (n *bridgeNetwork) setupIPTables(ipVersion iptables.IPVersion, maskedAddr *net.IPNet, config *networkConfiguration, i *bridgeInterface) error {
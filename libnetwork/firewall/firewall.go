@@ -0,0 +1,29 @@
+// Package firewall provides the firewall backends programming the rules of
+// libnetwork which are not specific to a network driver, like the rules
+// redirecting the DNS traffic of containers to the embedded resolver.
+package firewall
+
+import "net"
+
+const (
+	// IPTables is the firewall backend programming rules with iptables, and
+	// firewalld if it is running.
+	IPTables = "iptables"
+	// NFTables is the firewall backend programming rules with nftables over
+	// netlink, in tables of its own.
+	NFTables = "nftables"
+
+	// dnsPort is the port of the DNS traffic redirected to the embedded
+	// resolver.
+	dnsPort = 53
+)
+
+// Firewaller programs the rules of libnetwork with a firewall backend.
+type Firewaller interface {
+	// Backend returns the name of the firewall backend.
+	Backend() string
+	// RedirectDNS sets up the rules of the network namespace at nsPath
+	// redirecting the DNS traffic to resolverIP to the UDP and TCP sockets
+	// of the embedded resolver, replacing the rules set up before.
+	RedirectDNS(nsPath string, resolverIP net.IP, udpAddr *net.UDPAddr, tcpAddr *net.TCPAddr) error
+}
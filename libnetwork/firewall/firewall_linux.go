@@ -0,0 +1,16 @@
+package firewall
+
+import "fmt"
+
+// New returns the Firewaller of a firewall backend. The iptables backend is
+// used if backend is empty.
+func New(backend string) (Firewaller, error) {
+	switch backend {
+	case "", IPTables:
+		return &iptablesFirewaller{}, nil
+	case NFTables:
+		return &nftablesFirewaller{}, nil
+	default:
+		return nil, fmt.Errorf("unknown firewall backend: %s", backend)
+	}
+}
@@ -0,0 +1,70 @@
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/docker/docker/libnetwork/nftables"
+)
+
+// dnsTable is the name of the table of the rules redirecting the DNS traffic
+// to the embedded resolver, in the network namespace of a container.
+const dnsTable = "docker-dns"
+
+// nftablesFirewaller is the Firewaller programming rules with nftables over
+// netlink.
+type nftablesFirewaller struct{}
+
+func (fw *nftablesFirewaller) Backend() string {
+	return NFTables
+}
+
+// RedirectDNS programs the rules in the network namespace over a netlink
+// socket of the namespace, so it does not need to reexecute the daemon like
+// the iptables firewaller.
+func (fw *nftablesFirewaller) RedirectDNS(nsPath string, resolverIP net.IP, udpAddr *net.UDPAddr, tcpAddr *net.TCPAddr) error {
+	// TODO IPv6 support
+	if resolverIP.To4() == nil {
+		return fmt.Errorf("invalid IPv4 address of the resolver: %v", resolverIP)
+	}
+	f, err := os.Open(nsPath)
+	if err != nil {
+		return fmt.Errorf("failed get network namespace %q: %v", nsPath, err)
+	}
+	defer f.Close()
+
+	t := nftables.NewNetNSTable(nftables.IPv4, dnsTable, int(f.Fd()))
+	t.Reset()
+	t.AddBaseChain("output", nftables.NATOutput, false)
+	t.AddBaseChain("postrouting", nftables.NATPostrouting, false)
+	for _, addr := range []struct {
+		proto string
+		ip    net.IP
+		port  int
+	}{
+		{"udp", udpAddr.IP, udpAddr.Port},
+		{"tcp", tcpAddr.IP, tcpAddr.Port},
+	} {
+		proto, err := nftables.Proto(addr.proto)
+		if err != nil {
+			return err
+		}
+		t.AddRule("output", "",
+			t.Cmp(nftables.DstAddr, t.Addr(resolverIP), false),
+			t.Cmp(nftables.L4Proto, proto, false),
+			t.Cmp(nftables.DstPort, nftables.Port(dnsPort), false),
+			t.DNAT(addr.ip, addr.port),
+		)
+		t.AddRule("postrouting", "",
+			t.Cmp(nftables.SrcAddr, t.Addr(resolverIP), false),
+			t.Cmp(nftables.L4Proto, proto, false),
+			t.Cmp(nftables.SrcPort, nftables.Port(addr.port), false),
+			t.SNAT(nil, dnsPort),
+		)
+	}
+	if err := t.Flush(); err != nil {
+		return fmt.Errorf("failed to set up the rules of the embedded resolver in %s: %v", nsPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/libnetwork/nftables"
+	"github.com/docker/docker/libnetwork/testutils"
+	gnftables "github.com/google/nftables"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestNFTablesRedirectDNS(t *testing.T) {
+	if err := nftables.Available(); err != nil {
+		t.Skip(err)
+	}
+	defer testutils.SetupTestOSContext(t)()
+	lo, err := netlink.LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := netlink.LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+
+	resolverIP := net.ParseIP("127.0.0.11")
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: resolverIP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tcpListen, err := net.ListenTCP("tcp", &net.TCPAddr{IP: resolverIP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpListen.Close()
+
+	fw, err := New(NFTables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nsPath := fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid())
+	// Redirecting the traffic again replaces the rules.
+	for i := 0; i < 2; i++ {
+		if err := fw.RedirectDNS(nsPath, resolverIP, conn.LocalAddr().(*net.UDPAddr), tcpListen.Addr().(*net.TCPAddr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table := &gnftables.Table{Family: gnftables.TableFamilyIPv4, Name: dnsTable}
+	for _, chain := range []string{"output", "postrouting"} {
+		rules, err := (&gnftables.Conn{}).GetRules(table, &gnftables.Chain{Table: table, Name: chain})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rules) != 2 {
+			t.Fatalf("Expected 2 rules in chain %s, got %d", chain, len(rules))
+		}
+	}
+
+	// A query to the DNS port of the resolver is received by the socket of
+	// the resolver, and the reply is sent back from the DNS port.
+	client, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: resolverIP, Port: dnsPort})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.Write([]byte("query")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 16)
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, from, err := conn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "query" {
+		t.Fatalf("Unexpected query %q", buf[:n])
+	}
+	if _, err := conn.WriteToUDP([]byte("reply"), from); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = client.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "reply" {
+		t.Fatalf("Unexpected reply %q", buf[:n])
+	}
+}
@@ -1,6 +1,7 @@
 package libnetwork
 
 import (
+	"github.com/docker/docker/libnetwork/firewall"
 	"github.com/docker/docker/libnetwork/iptables"
 	"github.com/sirupsen/logrus"
 )
@@ -9,6 +10,17 @@ const userChain = "DOCKER-USER"
 
 var ctrl *controller
 
+// initFirewall sets up the firewaller of the controller, with the firewall
+// backend of the bridge driver.
+func (c *controller) initFirewall() error {
+	fw, err := firewall.New(c.firewallBackend())
+	if err != nil {
+		return err
+	}
+	c.firewall = fw
+	return nil
+}
+
 func setupArrangeUserFilterRule(c *controller) {
 	ctrl = c
 	iptables.OnReloaded(arrangeUserFilterRule)
@@ -24,6 +36,12 @@ func arrangeUserFilterRule() {
 	if ctrl == nil || !ctrl.iptablesEnabled() {
 		return
 	}
+	// With the nftables backend, the rules of the bridge driver are kept in
+	// tables of their own, and user policies are configured in tables of the
+	// user, as a packet dropped by any of the tables is dropped.
+	if ctrl.firewallBackend() == firewall.NFTables {
+		return
+	}
 	// TODO IPv6 support
 	iptable := iptables.GetIptable(iptables.IPv4)
 	_, err := iptable.NewChain(userChain, iptables.Filter, false)
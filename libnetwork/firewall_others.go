@@ -3,5 +3,6 @@
 
 package libnetwork
 
+func (c *controller) initFirewall() error      { return nil }
 func setupArrangeUserFilterRule(c *controller) {}
 func arrangeUserFilterRule()                   {}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"reflect"
 	"runtime"
 	"testing"
 	"time"
@@ -31,6 +32,15 @@ func TestNetworkMarshalling(t *testing.T) {
 		persist:     true,
 		configOnly:  true,
 		configFrom:  "configOnlyX",
+		dnsConfig: &DNSConfig{
+			Servers: []string{"10.0.0.53"},
+			Search:  []string{"example.com"},
+			Options: []string{"ndots:2"},
+		},
+		dnsRecords: []DNSRecord{
+			{Name: "db.example.com", Type: DNSRecordA, Value: "10.0.0.10", TTL: 60},
+			{Name: "www.example.com", Type: DNSRecordCNAME, Value: "db.example.com"},
+		},
 		ipamOptions: map[string]string{
 			netlabel.MacAddress: "a:b:c:d:e:f",
 			"primary":           "",
@@ -143,7 +153,9 @@ func TestNetworkMarshalling(t *testing.T) {
 		!compareStringMaps(n.ipamOptions, nn.ipamOptions) ||
 		!compareStringMaps(n.labels, nn.labels) ||
 		!n.created.Equal(nn.created) ||
-		n.configOnly != nn.configOnly || n.configFrom != nn.configFrom {
+		n.configOnly != nn.configOnly || n.configFrom != nn.configFrom ||
+		!reflect.DeepEqual(n.dnsConfig, nn.dnsConfig) ||
+		!reflect.DeepEqual(n.dnsRecords, nn.dnsRecords) {
 		t.Fatalf("JSON marsh/unmarsh failed."+
 			"\nOriginal:\n%#v\nDecoded:\n%#v"+
 			"\nOriginal ipamV4Conf: %#v\n\nDecoded ipamV4Conf: %#v"+
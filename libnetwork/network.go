@@ -57,6 +57,9 @@ type Network interface {
 
 	// Info returns certain operational data belonging to this network.
 	Info() NetworkInfo
+
+	// SetDNSRecords replaces the static DNS records of the network.
+	SetDNSRecords(records []DNSRecord) error
 }
 
 // NetworkInfo returns some configuration and operational information about the network
@@ -72,6 +75,12 @@ type NetworkInfo interface {
 	ConfigFrom() string
 	ConfigOnly() bool
 	Labels() map[string]string
+	// DNSConfig returns the DNS configuration of the network, or nil if the
+	// containers connected to the network use the DNS configuration of the
+	// daemon.
+	DNSConfig() *DNSConfig
+	// DNSRecords returns the static DNS records of the network.
+	DNSRecords() []DNSRecord
 	Dynamic() bool
 	Created() time.Time
 	// Peers returns a slice of PeerInfo structures which has the information about the peer
@@ -143,6 +152,28 @@ type IpamConf struct {
 	AuxAddresses map[string]string
 }
 
+// DNSConfig contains the DNS configuration of the containers connected to a
+// network, which overrides the DNS configuration of the daemon.
+type DNSConfig struct {
+	// Servers are the nameservers the queries are forwarded to.
+	Servers []string
+	// Search are the search domains.
+	Search []string
+	// Options are the resolv.conf options, such as "ndots:2".
+	Options []string
+}
+
+func (c *DNSConfig) copy() *DNSConfig {
+	if c == nil {
+		return nil
+	}
+	return &DNSConfig{
+		Servers: append([]string(nil), c.Servers...),
+		Search:  append([]string(nil), c.Search...),
+		Options: append([]string(nil), c.Options...),
+	}
+}
+
 // Validate checks whether the configuration is valid
 func (c *IpamConf) Validate() error {
 	if c.Gateway != "" && nil == net.ParseIP(c.Gateway) {
@@ -234,6 +265,8 @@ type network struct {
 	configFrom       string
 	loadBalancerIP   net.IP
 	loadBalancerMode string
+	dnsConfig        *DNSConfig
+	dnsRecords       []DNSRecord
 	sync.Mutex
 }
 
@@ -385,7 +418,7 @@ func (n *network) validateConfiguration() error {
 		}
 		if n.ipamType != "" &&
 			n.ipamType != defaultIpamForNetworkType(n.networkType) ||
-			n.enableIPv6 ||
+			n.enableIPv6 || n.dnsConfig != nil ||
 			len(n.labels) > 0 || len(n.ipamOptions) > 0 ||
 			len(n.ipamV4Config) > 0 || len(n.ipamV6Config) > 0 {
 			return types.ForbiddenErrorf("user specified configurations are not supported if the network depends on a configuration network")
@@ -419,6 +452,7 @@ func (n *network) validateConfiguration() error {
 // applyConfigurationTo applies network specific configurations.
 func (n *network) applyConfigurationTo(to *network) error {
 	to.enableIPv6 = n.enableIPv6
+	to.dnsConfig = n.dnsConfig.copy()
 	if len(n.labels) > 0 {
 		to.labels = make(map[string]string, len(n.labels))
 		for k, v := range n.labels {
@@ -481,6 +515,8 @@ func (n *network) CopyTo(o datastore.KVObject) error {
 	dstN.configFrom = n.configFrom
 	dstN.loadBalancerIP = n.loadBalancerIP
 	dstN.loadBalancerMode = n.loadBalancerMode
+	dstN.dnsConfig = n.dnsConfig.copy()
+	dstN.dnsRecords = append([]DNSRecord(nil), n.dnsRecords...)
 
 	// copy labels
 	if dstN.labels == nil {
@@ -607,6 +643,20 @@ func (n *network) MarshalJSON() ([]byte, error) {
 	netMap["configFrom"] = n.configFrom
 	netMap["loadBalancerIP"] = n.loadBalancerIP
 	netMap["loadBalancerMode"] = n.loadBalancerMode
+	if n.dnsConfig != nil {
+		dc, err := json.Marshal(n.dnsConfig)
+		if err != nil {
+			return nil, err
+		}
+		netMap["dnsConfig"] = string(dc)
+	}
+	if len(n.dnsRecords) > 0 {
+		dr, err := json.Marshal(n.dnsRecords)
+		if err != nil {
+			return nil, err
+		}
+		netMap["dnsRecords"] = string(dr)
+	}
 	return json.Marshal(netMap)
 }
 
@@ -724,6 +774,16 @@ func (n *network) UnmarshalJSON(b []byte) (err error) {
 	if v, ok := netMap["loadBalancerMode"]; ok {
 		n.loadBalancerMode = v.(string)
 	}
+	if v, ok := netMap["dnsConfig"]; ok {
+		if err := json.Unmarshal([]byte(v.(string)), &n.dnsConfig); err != nil {
+			return err
+		}
+	}
+	if v, ok := netMap["dnsRecords"]; ok {
+		if err := json.Unmarshal([]byte(v.(string)), &n.dnsRecords); err != nil {
+			return err
+		}
+	}
 	// Reconcile old networks with the recently added `--ipv6` flag
 	if !n.enableIPv6 {
 		n.enableIPv6 = len(n.ipamV6Info) > 0
@@ -886,6 +946,14 @@ func NetworkOptionConfigFrom(name string) NetworkOption {
 	}
 }
 
+// NetworkOptionDNS sets the DNS configuration of the containers connected to
+// the network.
+func NetworkOptionDNS(config DNSConfig) NetworkOption {
+	return func(n *network) {
+		n.dnsConfig = config.copy()
+	}
+}
+
 func (n *network) processOptions(options ...NetworkOption) {
 	for _, opt := range options {
 		if opt != nil {
@@ -1109,6 +1177,10 @@ removeFromStore:
 		return fmt.Errorf("error deleting network from store: %v", err)
 	}
 
+	c.Lock()
+	delete(c.staticRecords, n.id)
+	c.Unlock()
+
 	return nil
 }
 
@@ -1937,6 +2009,13 @@ func (n *network) Labels() map[string]string {
 	return lbls
 }
 
+func (n *network) DNSConfig() *DNSConfig {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.dnsConfig.copy()
+}
+
 func (n *network) TableEventRegister(tableName string, objType driverapi.ObjectType) error {
 	if !driverapi.IsValidType(objType) {
 		return fmt.Errorf("invalid object type %v in registering table, %s", objType, tableName)
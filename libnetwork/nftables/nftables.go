@@ -0,0 +1,562 @@
+//go:build linux
+// +build linux
+
+// Package nftables provides helpers to program nftables tables over netlink.
+//
+// Changes to a table are queued, and committed to the kernel by Flush in a
+// single netlink batch, so that they are applied either completely or not at
+// all.
+package nftables
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+)
+
+// Family is the address family of an nftables table.
+type Family nftables.TableFamily
+
+const (
+	// IPv4 is the family of tables processing IPv4 packets.
+	IPv4 = Family(nftables.TableFamilyIPv4)
+	// IPv6 is the family of tables processing IPv6 packets.
+	IPv6 = Family(nftables.TableFamilyIPv6)
+)
+
+// addrLen returns the length in bytes of the addresses of the family.
+func (f Family) addrLen() uint32 {
+	if f == IPv6 {
+		return net.IPv6len
+	}
+	return net.IPv4len
+}
+
+// AddrType returns the type of the addresses of the family.
+func (f Family) AddrType() SetType {
+	if f == IPv6 {
+		return nftables.TypeIP6Addr
+	}
+	return nftables.TypeIPAddr
+}
+
+// Available returns an error if nftables tables cannot be programmed, because
+// the kernel does not support nftables.
+func Available() error {
+	if _, err := newConn(0).ListTablesOfFamily(nftables.TableFamilyIPv4); err != nil {
+		return fmt.Errorf("nftables not available: %v", err)
+	}
+	return nil
+}
+
+// SetType is the type of the keys, or the values, of a set or a map.
+type SetType = nftables.SetDatatype
+
+// Types of the keys and values of sets and maps.
+var (
+	TypeIfName      = nftables.TypeIFName
+	TypeInetProto   = nftables.TypeInetProto
+	TypeInetService = nftables.TypeInetService
+	TypeVerdict     = nftables.TypeVerdict
+)
+
+// Concat returns the type of the concatenation of the given types.
+func Concat(types ...SetType) SetType {
+	return nftables.MustConcatSetType(types...)
+}
+
+// Hook is the netfilter hook of a base chain, with the type and priority of
+// the chain.
+type Hook struct {
+	chainType nftables.ChainType
+	hooknum   nftables.ChainHook
+	priority  nftables.ChainPriority
+}
+
+// Hooks of the base chains.
+var (
+	// NATPrerouting is the hook of chains translating the destination of
+	// packets received by the host.
+	NATPrerouting = Hook{nftables.ChainTypeNAT, nftables.ChainHookPrerouting, nftables.ChainPriorityNATDest}
+	// NATOutput is the hook of chains translating the destination of
+	// packets sent by the host.
+	NATOutput = Hook{nftables.ChainTypeNAT, nftables.ChainHookOutput, nftables.ChainPriorityNATDest}
+	// NATPostrouting is the hook of chains translating the source of
+	// packets leaving the host.
+	NATPostrouting = Hook{nftables.ChainTypeNAT, nftables.ChainHookPostrouting, nftables.ChainPriorityNATSource}
+	// FilterForward is the hook of chains filtering the packets forwarded by
+	// the host.
+	FilterForward = Hook{nftables.ChainTypeFilter, nftables.ChainHookForward, nftables.ChainPriorityFilter}
+)
+
+// Field is a field of packets, which is loaded in registers to be compared or
+// looked up in sets and maps.
+type Field int
+
+const (
+	// IIFName is the name of the interface a packet was received on.
+	IIFName Field = iota
+	// OIFName is the name of the interface a packet is sent on.
+	OIFName
+	// SrcAddr is the source address of a packet.
+	SrcAddr
+	// DstAddr is the destination address of a packet.
+	DstAddr
+	// L4Proto is the transport protocol of a packet.
+	L4Proto
+	// SrcPort is the transport source port of a packet.
+	SrcPort
+	// DstPort is the transport destination port of a packet.
+	DstPort
+)
+
+// Expr is a list of expressions of a rule, such as a match or a statement.
+type Expr []expr.Any
+
+// Element is an element of a set or a map. Value is the value of the key in a
+// map, and Jump the chain the key jumps to in a verdict map.
+type Element struct {
+	Key   []byte
+	Value []byte
+	Jump  string
+}
+
+// Table is an nftables table of the host, or of another network namespace,
+// programmed over netlink. Errors queueing changes are returned by Flush.
+type Table struct {
+	netns  int
+	conn   *nftables.Conn
+	table  *nftables.Table
+	chains map[string]*nftables.Chain
+	sets   map[string]*nftables.Set
+	err    error
+}
+
+// NewTable returns the table with the given family and name, in the network
+// namespace of the host. The table is created by Reset.
+func NewTable(family Family, name string) *Table {
+	return NewNetNSTable(family, name, 0)
+}
+
+// NewNetNSTable returns the table with the given family and name, in the
+// network namespace of the file descriptor nsFD, which must stay open until
+// the table is flushed. The table is created by Reset.
+func NewNetNSTable(family Family, name string, nsFD int) *Table {
+	return &Table{
+		netns:  nsFD,
+		conn:   newConn(nsFD),
+		table:  &nftables.Table{Family: nftables.TableFamily(family), Name: name},
+		chains: make(map[string]*nftables.Chain),
+		sets:   make(map[string]*nftables.Set),
+	}
+}
+
+// newConn returns a connection opening a netlink connection to the network
+// namespace of the file descriptor nsFD, or of the host if it is 0, for each
+// operation, like the ones returned by nftables.New.
+func newConn(nsFD int) *nftables.Conn {
+	return &nftables.Conn{NetNS: nsFD}
+}
+
+// Family returns the address family of the table.
+func (t *Table) Family() Family {
+	return Family(t.table.Family)
+}
+
+// Flush commits the changes queued since the last flush. If queueing one of
+// the changes failed, none of them is committed.
+func (t *Table) Flush() error {
+	if err := t.err; err != nil {
+		// Drop the queued changes.
+		t.conn = newConn(t.netns)
+		t.err = nil
+		return err
+	}
+	return t.conn.Flush()
+}
+
+// Reset queues the deletion of the table, and all its chains, sets and maps,
+// and its creation with no chain or set.
+func (t *Table) Reset() {
+	// Adding the table first creates it if it does not exist, so that
+	// deleting it does not fail.
+	t.conn.AddTable(t.table)
+	t.conn.DelTable(t.table)
+	t.conn.AddTable(t.table)
+	t.chains = make(map[string]*nftables.Chain)
+	t.sets = make(map[string]*nftables.Set)
+}
+
+// AddChain queues the creation of a regular chain.
+func (t *Table) AddChain(name string) {
+	t.chains[name] = t.conn.AddChain(&nftables.Chain{Table: t.table, Name: name})
+}
+
+// AddBaseChain queues the creation of a base chain, or the update of its
+// policy if it exists.
+func (t *Table) AddBaseChain(name string, hook Hook, drop bool) {
+	policy := nftables.ChainPolicyAccept
+	if drop {
+		policy = nftables.ChainPolicyDrop
+	}
+	t.chains[name] = t.conn.AddChain(&nftables.Chain{
+		Table:    t.table,
+		Name:     name,
+		Type:     hook.chainType,
+		Hooknum:  hook.hooknum,
+		Priority: hook.priority,
+		Policy:   &policy,
+	})
+}
+
+// DeleteChain queues the deletion of a regular chain, and its rules.
+func (t *Table) DeleteChain(name string) {
+	c := t.chain(name)
+	t.conn.FlushChain(c)
+	t.conn.DelChain(c)
+	delete(t.chains, name)
+}
+
+func (t *Table) chain(name string) *nftables.Chain {
+	if c, ok := t.chains[name]; ok {
+		return c
+	}
+	return &nftables.Chain{Table: t.table, Name: name}
+}
+
+// AddRule queues the addition of a rule at the end of a chain. The tag is
+// stored in the rule, so that the rule can be deleted by DeleteRules.
+func (t *Table) AddRule(chain, tag string, exprs ...Expr) {
+	r := &nftables.Rule{Table: t.table, Chain: t.chain(chain)}
+	if tag != "" {
+		r.UserData = []byte(tag)
+	}
+	for _, e := range exprs {
+		r.Exprs = append(r.Exprs, e...)
+	}
+	t.conn.AddRule(r)
+}
+
+// DeleteRules queues the deletion of the rules of a chain with the given tag.
+// The rules of the chain are listed from the kernel, so rules added since the
+// last flush are not deleted.
+func (t *Table) DeleteRules(chain, tag string) {
+	if t.err != nil {
+		return
+	}
+	rules, err := t.conn.GetRules(t.table, t.chain(chain))
+	if err != nil {
+		t.err = fmt.Errorf("failed to list the rules of chain %s: %v", chain, err)
+		return
+	}
+	for _, r := range rules {
+		if string(r.UserData) != tag {
+			continue
+		}
+		r.Chain = t.chain(chain)
+		if err := t.conn.DelRule(r); err != nil {
+			t.err = err
+			return
+		}
+	}
+}
+
+// AddSet queues the creation of a set.
+func (t *Table) AddSet(name string, key SetType) {
+	t.addSet(&nftables.Set{Table: t.table, Name: name, KeyType: key})
+}
+
+// AddMap queues the creation of a map. Use TypeVerdict as the type of the
+// values of verdict maps.
+func (t *Table) AddMap(name string, key, value SetType) {
+	t.addSet(&nftables.Set{Table: t.table, Name: name, KeyType: key, DataType: value, IsMap: true})
+}
+
+func (t *Table) addSet(s *nftables.Set) {
+	if err := t.conn.AddSet(s, nil); err != nil && t.err == nil {
+		t.err = fmt.Errorf("failed to add set %s: %v", s.Name, err)
+	}
+	t.sets[s.Name] = s
+}
+
+func (t *Table) set(name string) *nftables.Set {
+	if s, ok := t.sets[name]; ok {
+		return s
+	}
+	return &nftables.Set{Table: t.table, Name: name}
+}
+
+// AddElements queues the addition of elements to a set or a map.
+func (t *Table) AddElements(set string, elements ...Element) {
+	if len(elements) == 0 {
+		return
+	}
+	if err := t.conn.SetAddElements(t.set(set), setElements(elements)); err != nil && t.err == nil {
+		t.err = fmt.Errorf("failed to add elements to set %s: %v", set, err)
+	}
+}
+
+// DeleteElements queues the deletion of elements of a set or a map. Deleting
+// an element which is not in the set fails.
+func (t *Table) DeleteElements(set string, elements ...Element) {
+	if len(elements) == 0 {
+		return
+	}
+	if err := t.conn.SetDeleteElements(t.set(set), setElements(elements)); err != nil && t.err == nil {
+		t.err = fmt.Errorf("failed to delete elements of set %s: %v", set, err)
+	}
+}
+
+func setElements(elements []Element) []nftables.SetElement {
+	s := make([]nftables.SetElement, 0, len(elements))
+	for _, e := range elements {
+		se := nftables.SetElement{Key: e.Key, Val: e.Value}
+		if e.Jump != "" {
+			se.VerdictData = &expr.Verdict{Kind: expr.VerdictJump, Chain: e.Jump}
+		}
+		s = append(s, se)
+	}
+	return s
+}
+
+// Key returns the key, or value, of an element of a set or a map with a
+// concatenation of values. Each value is padded to a multiple of 32 bits.
+func Key(values ...[]byte) []byte {
+	var k []byte
+	for _, v := range values {
+		k = append(k, v...)
+		if pad := len(v) % 4; pad != 0 {
+			k = append(k, make([]byte, 4-pad)...)
+		}
+	}
+	return k
+}
+
+// IfName returns the value of an interface name.
+func IfName(name string) []byte {
+	b := make([]byte, unix.IFNAMSIZ)
+	copy(b, name)
+	return b
+}
+
+// Addr returns the value of an address of the family of the table.
+func (t *Table) Addr(ip net.IP) []byte {
+	if t.Family() == IPv4 {
+		return ip.To4()
+	}
+	return ip.To16()
+}
+
+// Proto returns the value of a transport protocol.
+func Proto(proto string) ([]byte, error) {
+	switch proto {
+	case "tcp":
+		return []byte{unix.IPPROTO_TCP}, nil
+	case "udp":
+		return []byte{unix.IPPROTO_UDP}, nil
+	case "sctp":
+		return []byte{unix.IPPROTO_SCTP}, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol %s", proto)
+	}
+}
+
+// Port returns the value of a transport port.
+func Port(port int) []byte {
+	return binaryutil.BigEndian.PutUint16(uint16(port))
+}
+
+// reg32 is the first 32-bit register, from which concatenations of fields are
+// loaded. The registers of the concatenations of the sets and maps of a table
+// are not larger than the 64 bytes of the registers following it.
+const reg32 = unix.NFT_REG32_00
+
+// load returns the expressions loading the fields into consecutive registers,
+// and the length in bytes of the loaded fields, each padded to a multiple of
+// 32 bits.
+func (t *Table) load(fields ...Field) (Expr, uint32) {
+	var (
+		e   Expr
+		off uint32
+	)
+	for _, f := range fields {
+		reg := reg32 + off/4
+		var l uint32
+		switch f {
+		case IIFName, OIFName:
+			key := expr.MetaKeyIIFNAME
+			if f == OIFName {
+				key = expr.MetaKeyOIFNAME
+			}
+			e = append(e, &expr.Meta{Key: key, Register: reg})
+			l = unix.IFNAMSIZ
+		case SrcAddr, DstAddr:
+			l = t.Family().addrLen()
+			// The source address is followed by the destination address
+			// in the headers of both IPv4 and IPv6.
+			offset := uint32(12)
+			if t.Family() == IPv6 {
+				offset = 8
+			}
+			if f == DstAddr {
+				offset += l
+			}
+			e = append(e, &expr.Payload{DestRegister: reg, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: l})
+		case L4Proto:
+			e = append(e, &expr.Meta{Key: expr.MetaKeyL4PROTO, Register: reg})
+			l = 1
+		case SrcPort, DstPort:
+			offset := uint32(0)
+			if f == DstPort {
+				offset = 2
+			}
+			e = append(e, &expr.Payload{DestRegister: reg, Base: expr.PayloadBaseTransportHeader, Offset: offset, Len: 2})
+			l = 2
+		}
+		off += (l + 3) / 4 * 4
+	}
+	return e, off
+}
+
+// Cmp returns the match of a field equal, or not equal if neq is true, to a
+// value.
+func (t *Table) Cmp(field Field, value []byte, neq bool) Expr {
+	e, _ := t.load(field)
+	op := expr.CmpOpEq
+	if neq {
+		op = expr.CmpOpNeq
+	}
+	return append(e, &expr.Cmp{Op: op, Register: reg32, Data: value})
+}
+
+// InNet returns the match of an address field in a network, or not in the
+// network if neq is true.
+func (t *Table) InNet(field Field, nw *net.IPNet, neq bool) Expr {
+	e, l := t.load(field)
+	op := expr.CmpOpEq
+	if neq {
+		op = expr.CmpOpNeq
+	}
+	return append(e,
+		&expr.Bitwise{SourceRegister: reg32, DestRegister: reg32, Len: l, Mask: t.Addr(net.IP(nw.Mask)), Xor: make([]byte, l)},
+		&expr.Cmp{Op: op, Register: reg32, Data: t.Addr(nw.IP.Mask(nw.Mask))},
+	)
+}
+
+// Lookup returns the match of the concatenation of fields in a set.
+func (t *Table) Lookup(set string, fields ...Field) Expr {
+	e, _ := t.load(fields...)
+	s := t.set(set)
+	return append(e, &expr.Lookup{SourceRegister: reg32, SetName: s.Name, SetID: s.ID})
+}
+
+// VMap returns the statement jumping to the chain of the concatenation of
+// fields in a verdict map.
+func (t *Table) VMap(set string, fields ...Field) Expr {
+	e, _ := t.load(fields...)
+	s := t.set(set)
+	return append(e, &expr.Lookup{SourceRegister: reg32, SetName: s.Name, SetID: s.ID, IsDestRegSet: true, DestRegister: unix.NFT_REG_VERDICT})
+}
+
+// DNATMap returns the statement translating the destination of packets to
+// the address and port of the concatenation of fields in a map.
+func (t *Table) DNATMap(set string, fields ...Field) Expr {
+	e, _ := t.load(fields...)
+	s := t.set(set)
+	return append(e,
+		&expr.Lookup{SourceRegister: reg32, SetName: s.Name, SetID: s.ID, IsDestRegSet: true, DestRegister: reg32},
+		&expr.NAT{
+			Type:        expr.NATTypeDestNAT,
+			Family:      uint32(t.table.Family),
+			RegAddrMin:  reg32,
+			RegProtoMin: reg32 + t.Family().addrLen()/4,
+		},
+	)
+}
+
+// DNAT returns the statement translating the destination of packets to an
+// address and port.
+func (t *Table) DNAT(ip net.IP, port int) Expr {
+	return t.nat(expr.NATTypeDestNAT, ip, port)
+}
+
+// SNAT returns the statement translating the source of packets to an address,
+// and a port if it is not 0. The address is kept if ip is nil.
+func (t *Table) SNAT(ip net.IP, port int) Expr {
+	return t.nat(expr.NATTypeSourceNAT, ip, port)
+}
+
+// nat returns the statement translating the address of packets to ip, unless
+// it is nil, and the port to port, unless it is 0.
+func (t *Table) nat(typ expr.NATType, ip net.IP, port int) Expr {
+	var (
+		e   Expr
+		nat = &expr.NAT{Type: typ, Family: uint32(t.table.Family)}
+		reg = uint32(reg32)
+	)
+	if ip != nil {
+		e = append(e, &expr.Immediate{Register: reg, Data: t.Addr(ip)})
+		nat.RegAddrMin = reg
+		reg += t.Family().addrLen() / 4
+	}
+	if port != 0 {
+		e = append(e, &expr.Immediate{Register: reg, Data: Port(port)})
+		nat.RegProtoMin = reg
+	}
+	return append(e, nat)
+}
+
+// LocalAddr returns the match of the source, or destination, address of
+// packets which is an address of the host.
+func LocalAddr(field Field) Expr {
+	return Expr{
+		&expr.Fib{Register: reg32, FlagSADDR: field == SrcAddr, FlagDADDR: field == DstAddr, ResultADDRTYPE: true},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: reg32, Data: binaryutil.NativeEndian.PutUint32(unix.RTN_LOCAL)},
+	}
+}
+
+// RelatedEstablished returns the match of packets of established connections,
+// or related to them.
+func RelatedEstablished() Expr {
+	return Expr{
+		&expr.Ct{Key: expr.CtKeySTATE, Register: reg32},
+		&expr.Bitwise{
+			SourceRegister: reg32,
+			DestRegister:   reg32,
+			Len:            4,
+			Mask:           binaryutil.NativeEndian.PutUint32(expr.CtStateBitESTABLISHED | expr.CtStateBitRELATED),
+			Xor:            binaryutil.NativeEndian.PutUint32(0),
+		},
+		&expr.Cmp{Op: expr.CmpOpNeq, Register: reg32, Data: binaryutil.NativeEndian.PutUint32(0)},
+	}
+}
+
+// Masquerade returns the statement translating the source of packets to the
+// address of the interface they are sent on.
+func Masquerade() Expr {
+	return Expr{&expr.Masq{}}
+}
+
+// Accept returns the statement accepting packets.
+func Accept() Expr {
+	return Expr{&expr.Verdict{Kind: expr.VerdictAccept}}
+}
+
+// Drop returns the statement dropping packets.
+func Drop() Expr {
+	return Expr{&expr.Verdict{Kind: expr.VerdictDrop}}
+}
+
+// Return returns the statement returning from a chain.
+func Return() Expr {
+	return Expr{&expr.Verdict{Kind: expr.VerdictReturn}}
+}
+
+// Jump returns the statement jumping to a chain.
+func Jump(chain string) Expr {
+	return Expr{&expr.Verdict{Kind: expr.VerdictJump, Chain: chain}}
+}
@@ -0,0 +1,165 @@
+//go:build linux
+// +build linux
+
+package nftables
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+)
+
+func TestLoad(t *testing.T) {
+	for _, tc := range []struct {
+		family   Family
+		expected Expr
+	}{
+		{
+			// The fields are loaded into consecutive 32-bit registers from
+			// NFT_REG32_00 (8), each padded to a multiple of 32 bits.
+			family: IPv4,
+			expected: Expr{
+				&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 8},
+				&expr.Payload{DestRegister: 12, Base: expr.PayloadBaseNetworkHeader, Offset: 12, Len: 4},
+				&expr.Payload{DestRegister: 13, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 14},
+				&expr.Payload{DestRegister: 15, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 2},
+				&expr.Payload{DestRegister: 16, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+				&expr.Lookup{SourceRegister: 8, SetName: "set"},
+			},
+		},
+		{
+			family: IPv6,
+			expected: Expr{
+				&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 8},
+				&expr.Payload{DestRegister: 12, Base: expr.PayloadBaseNetworkHeader, Offset: 8, Len: 16},
+				&expr.Payload{DestRegister: 16, Base: expr.PayloadBaseNetworkHeader, Offset: 24, Len: 16},
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 20},
+				&expr.Payload{DestRegister: 21, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 2},
+				&expr.Payload{DestRegister: 22, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+				&expr.Lookup{SourceRegister: 8, SetName: "set"},
+			},
+		},
+	} {
+		t.Run(familyName(tc.family), func(t *testing.T) {
+			e := NewTable(tc.family, "test").Lookup("set", IIFName, SrcAddr, DstAddr, L4Proto, SrcPort, DstPort)
+			assert.DeepEqual(t, e, tc.expected)
+		})
+	}
+}
+
+func TestCmp(t *testing.T) {
+	table := NewTable(IPv4, "test")
+	assert.DeepEqual(t, table.Cmp(OIFName, IfName("docker0"), true), Expr{
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 8},
+		&expr.Cmp{Op: expr.CmpOpNeq, Register: 8, Data: IfName("docker0")},
+	})
+	// Ports are compared in network byte order.
+	assert.DeepEqual(t, table.Cmp(DstPort, Port(80), false), Expr{
+		&expr.Payload{DestRegister: 8, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 8, Data: []byte{0x00, 0x50}},
+	})
+}
+
+func TestInNet(t *testing.T) {
+	for _, tc := range []struct {
+		family   Family
+		network  string
+		expected Expr
+	}{
+		{
+			family:  IPv4,
+			network: "172.17.0.0/16",
+			expected: Expr{
+				&expr.Payload{DestRegister: 8, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+				&expr.Bitwise{SourceRegister: 8, DestRegister: 8, Len: 4, Mask: []byte{0xff, 0xff, 0, 0}, Xor: make([]byte, 4)},
+				&expr.Cmp{Op: expr.CmpOpNeq, Register: 8, Data: []byte{172, 17, 0, 0}},
+			},
+		},
+		{
+			family:  IPv6,
+			network: "fd00::/64",
+			expected: Expr{
+				&expr.Payload{DestRegister: 8, Base: expr.PayloadBaseNetworkHeader, Offset: 24, Len: 16},
+				&expr.Bitwise{SourceRegister: 8, DestRegister: 8, Len: 16, Mask: net.ParseIP("ffff:ffff:ffff:ffff::").To16(), Xor: make([]byte, 16)},
+				&expr.Cmp{Op: expr.CmpOpNeq, Register: 8, Data: net.ParseIP("fd00::").To16()},
+			},
+		},
+	} {
+		t.Run(familyName(tc.family), func(t *testing.T) {
+			_, nw, err := net.ParseCIDR(tc.network)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, NewTable(tc.family, "test").InNet(DstAddr, nw, true), tc.expected)
+		})
+	}
+}
+
+func TestNAT(t *testing.T) {
+	for _, tc := range []struct {
+		family  Family
+		ip      net.IP
+		addr    []byte
+		portReg uint32
+	}{
+		// The port follows the address in the registers.
+		{family: IPv4, ip: net.ParseIP("172.17.0.2"), addr: []byte{172, 17, 0, 2}, portReg: 9},
+		{family: IPv6, ip: net.ParseIP("fd00::2"), addr: net.ParseIP("fd00::2").To16(), portReg: 12},
+	} {
+		t.Run(familyName(tc.family), func(t *testing.T) {
+			table := NewTable(tc.family, "test")
+			fam := uint32(tc.family)
+
+			assert.DeepEqual(t, table.DNATMap("ports", L4Proto, DstPort), Expr{
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 8},
+				&expr.Payload{DestRegister: 9, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+				&expr.Lookup{SourceRegister: 8, SetName: "ports", IsDestRegSet: true, DestRegister: 8},
+				&expr.NAT{Type: expr.NATTypeDestNAT, Family: fam, RegAddrMin: 8, RegProtoMin: tc.portReg},
+			})
+			assert.DeepEqual(t, table.DNAT(tc.ip, 8080), Expr{
+				&expr.Immediate{Register: 8, Data: tc.addr},
+				&expr.Immediate{Register: tc.portReg, Data: []byte{0x1f, 0x90}},
+				&expr.NAT{Type: expr.NATTypeDestNAT, Family: fam, RegAddrMin: 8, RegProtoMin: tc.portReg},
+			})
+			assert.DeepEqual(t, table.SNAT(tc.ip, 0), Expr{
+				&expr.Immediate{Register: 8, Data: tc.addr},
+				&expr.NAT{Type: expr.NATTypeSourceNAT, Family: fam, RegAddrMin: 8},
+			})
+			// Without an address, the port is in the first register.
+			assert.DeepEqual(t, table.SNAT(nil, 53), Expr{
+				&expr.Immediate{Register: 8, Data: []byte{0x00, 0x35}},
+				&expr.NAT{Type: expr.NATTypeSourceNAT, Family: fam, RegProtoMin: 8},
+			})
+		})
+	}
+}
+
+func TestHostByteOrder(t *testing.T) {
+	// Unlike the fields of packets, the results of fib and the connection
+	// tracking state are in the byte order of the host.
+	assert.DeepEqual(t, LocalAddr(SrcAddr), Expr{
+		&expr.Fib{Register: 8, FlagSADDR: true, ResultADDRTYPE: true},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 8, Data: binaryutil.NativeEndian.PutUint32(unix.RTN_LOCAL)},
+	})
+	assert.DeepEqual(t, RelatedEstablished(), Expr{
+		&expr.Ct{Key: expr.CtKeySTATE, Register: 8},
+		&expr.Bitwise{
+			SourceRegister: 8,
+			DestRegister:   8,
+			Len:            4,
+			Mask:           binaryutil.NativeEndian.PutUint32(expr.CtStateBitESTABLISHED | expr.CtStateBitRELATED),
+			Xor:            []byte{0, 0, 0, 0},
+		},
+		&expr.Cmp{Op: expr.CmpOpNeq, Register: 8, Data: []byte{0, 0, 0, 0}},
+	})
+}
+
+func familyName(f Family) string {
+	if f == IPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
@@ -36,6 +36,14 @@ func New(proxyPath string) *PortMapper {
 	return NewWithPortAllocator(portallocator.Get(), proxyPath)
 }
 
+// NewInternal returns a new instance of PortMapper which runs the userland
+// proxies of its mappings in the daemon, instead of in docker-proxy processes.
+func NewInternal() *PortMapper {
+	pm := NewWithPortAllocator(portallocator.Get(), "")
+	pm.internalProxy = true
+	return pm
+}
+
 // NewWithPortAllocator returns a new instance of PortMapper which will use the specified PortAllocator
 func NewWithPortAllocator(allocator *portallocator.PortAllocator, proxyPath string) *PortMapper {
 	return &PortMapper{
@@ -75,7 +83,7 @@ func (pm *PortMapper) MapRange(container net.Addr, hostIP net.IP, hostPortStart,
 		}
 
 		if useProxy {
-			m.userlandProxy, err = newProxy(proto, hostIP, allocatedHostPort, t.IP, t.Port, pm.proxyPath)
+			m.userlandProxy, err = pm.newUserlandProxy(proto, hostIP, allocatedHostPort, t.IP, t.Port)
 			if err != nil {
 				return nil, err
 			}
@@ -98,7 +106,7 @@ func (pm *PortMapper) MapRange(container net.Addr, hostIP net.IP, hostPortStart,
 		}
 
 		if useProxy {
-			m.userlandProxy, err = newProxy(proto, hostIP, allocatedHostPort, t.IP, t.Port, pm.proxyPath)
+			m.userlandProxy, err = pm.newUserlandProxy(proto, hostIP, allocatedHostPort, t.IP, t.Port)
 			if err != nil {
 				return nil, err
 			}
@@ -125,7 +133,7 @@ func (pm *PortMapper) MapRange(container net.Addr, hostIP net.IP, hostPortStart,
 			if len(sctpAddr.IPAddrs) == 0 {
 				return nil, ErrSCTPAddrNoIP
 			}
-			m.userlandProxy, err = newProxy(proto, hostIP, allocatedHostPort, sctpAddr.IPAddrs[0].IP, sctpAddr.Port, pm.proxyPath)
+			m.userlandProxy, err = pm.newUserlandProxy(proto, hostIP, allocatedHostPort, sctpAddr.IPAddrs[0].IP, sctpAddr.Port)
 			if err != nil {
 				return nil, err
 			}
@@ -229,6 +237,15 @@ func (pm *PortMapper) ReMapAll() {
 	}
 }
 
+// newUserlandProxy returns the userland proxy of a mapping, running in the
+// daemon or in a docker-proxy process.
+func (pm *PortMapper) newUserlandProxy(proto string, hostIP net.IP, hostPort int, containerIP net.IP, containerPort int) (userlandProxy, error) {
+	if pm.internalProxy {
+		return newInternalProxy(proto, hostIP, hostPort, containerIP, containerPort)
+	}
+	return newProxy(proto, hostIP, hostPort, containerIP, containerPort, pm.proxyPath)
+}
+
 func getKey(a net.Addr) string {
 	switch t := a.(type) {
 	case *net.TCPAddr:
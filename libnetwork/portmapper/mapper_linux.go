@@ -17,14 +17,33 @@ type PortMapper struct {
 	lock            sync.Mutex
 
 	proxyPath string
+	// internalProxy is set if the userland proxies of the mappings run in
+	// the daemon.
+	internalProxy bool
 
 	Allocator *portallocator.PortAllocator
-	chain     *iptables.ChainInfo
+	forwarder Forwarder
+}
+
+// Forwarder programs the rules forwarding traffic of a host port to a port
+// of a container. It is implemented by iptables.ChainInfo.
+type Forwarder interface {
+	Forward(action iptables.Action, ip net.IP, port int, proto, destAddr string, destPort int, bridgeName string) error
 }
 
 // SetIptablesChain sets the specified chain into portmapper
 func (pm *PortMapper) SetIptablesChain(c *iptables.ChainInfo, bridgeName string) {
-	pm.chain = c
+	if c == nil {
+		pm.SetForwarder(nil, bridgeName)
+		return
+	}
+	pm.SetForwarder(c, bridgeName)
+}
+
+// SetForwarder sets the forwarder programming the rules of the port mappings
+// of the portmapper.
+func (pm *PortMapper) SetForwarder(f Forwarder, bridgeName string) {
+	pm.forwarder = f
 	pm.bridgeName = bridgeName
 }
 
@@ -39,8 +58,8 @@ func (pm *PortMapper) DeleteForwardingTableEntry(proto string, sourceIP net.IP,
 }
 
 func (pm *PortMapper) forward(action iptables.Action, proto string, sourceIP net.IP, sourcePort int, containerIP string, containerPort int) error {
-	if pm.chain == nil {
+	if pm.forwarder == nil {
 		return nil
 	}
-	return pm.chain.Forward(action, sourceIP, sourcePort, proto, containerIP, containerPort, pm.bridgeName)
+	return pm.forwarder.Forward(action, sourceIP, sourcePort, proto, containerIP, containerPort, pm.bridgeName)
 }
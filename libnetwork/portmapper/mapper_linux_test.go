@@ -1,11 +1,14 @@
 package portmapper
 
 import (
+	"io"
 	"net"
 	"strings"
 	"testing"
 
 	"github.com/docker/docker/libnetwork/iptables"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func init() {
@@ -20,12 +23,12 @@ func TestSetIptablesChain(t *testing.T) {
 		Name: "TEST",
 	}
 
-	if pm.chain != nil {
+	if pm.forwarder != nil {
 		t.Fatal("chain should be nil at init")
 	}
 
 	pm.SetIptablesChain(c, "lo")
-	if pm.chain == nil {
+	if pm.forwarder == nil {
 		t.Fatal("chain should not be nil after set")
 	}
 }
@@ -76,6 +79,74 @@ func TestMapTCPPorts(t *testing.T) {
 	}
 }
 
+func TestMapInternalProxy(t *testing.T) {
+	backend, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	pm := NewInternal()
+	host, err := pm.MapRange(backend.Addr(), net.ParseIP("127.0.0.1"), 0, 0, true)
+	if err != nil {
+		t.Fatalf("Failed to allocate port: %s", err)
+	}
+
+	client, err := net.Dial("tcp", host.String())
+	if err != nil {
+		t.Fatalf("Failed to connect to the proxy: %s", err)
+	}
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(client, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("Expected ping, got %q", buf)
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	proxyMetrics.Collect(ch)
+	close(ch)
+	var conns float64
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range metric.GetLabel() {
+			if l.GetName() == "host_port" && l.GetValue() == strings.Split(host.String(), ":")[1] {
+				conns = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	if conns != 1 {
+		t.Fatalf("Expected 1 active connection for %s, got %v", host, conns)
+	}
+	client.Close()
+
+	if err := pm.Unmap(host); err != nil {
+		t.Fatalf("Failed to release port: %s", err)
+	}
+	if _, err := net.Dial("tcp", host.String()); err == nil {
+		t.Fatal("Connected to the proxy of a released port")
+	}
+}
+
 func TestGetUDPKey(t *testing.T) {
 	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.5"), Port: 53}
 
@@ -16,6 +16,9 @@ type PortMapper struct {
 	lock            sync.Mutex
 
 	proxyPath string
+	// internalProxy is set if the userland proxies of the mappings run in
+	// the daemon.
+	internalProxy bool
 
 	Allocator *portallocator.PortAllocator
 }
@@ -0,0 +1,126 @@
+package portmapper
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/libnetwork/proxy"
+	metrics "github.com/docker/go-metrics"
+	"github.com/ishidawataru/sctp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// internalProxy runs the userland proxy of a mapping in the daemon. It
+// forwards the traffic of the host port to the container port the same way
+// docker-proxy does, with goroutines instead of a process.
+type internalProxy struct {
+	proto     string
+	host      net.Addr
+	container net.Addr
+	proxy     proxy.Proxy
+	done      chan struct{}
+}
+
+func newInternalProxy(proto string, hostIP net.IP, hostPort int, containerIP net.IP, containerPort int) (userlandProxy, error) {
+	p := &internalProxy{proto: proto}
+	switch proto {
+	case "tcp":
+		p.host = &net.TCPAddr{IP: hostIP, Port: hostPort}
+		p.container = &net.TCPAddr{IP: containerIP, Port: containerPort}
+	case "udp":
+		p.host = &net.UDPAddr{IP: hostIP, Port: hostPort}
+		p.container = &net.UDPAddr{IP: containerIP, Port: containerPort}
+	case "sctp":
+		p.host = &sctp.SCTPAddr{IPAddrs: []net.IPAddr{{IP: hostIP}}, Port: hostPort}
+		p.container = &sctp.SCTPAddr{IPAddrs: []net.IPAddr{{IP: containerIP}}, Port: containerPort}
+	default:
+		return nil, fmt.Errorf("Unknown addr type: %s", proto)
+	}
+	return p, nil
+}
+
+func (p *internalProxy) Start() error {
+	px, err := proxy.NewProxy(p.host, p.container)
+	if err != nil {
+		return err
+	}
+	p.proxy = px
+	p.done = make(chan struct{})
+	go func() {
+		px.Run()
+		close(p.done)
+	}()
+	proxyMetrics.add(p)
+	return nil
+}
+
+func (p *internalProxy) Stop() error {
+	if p.proxy == nil {
+		return nil
+	}
+	proxyMetrics.remove(p)
+	p.proxy.Close()
+	<-p.done
+	p.proxy = nil
+	return nil
+}
+
+// proxyCollector collects the number of active connections of the userland
+// proxies running in the daemon, per mapping.
+type proxyCollector struct {
+	mu      sync.Mutex
+	proxies map[*internalProxy]struct{}
+	desc    *prometheus.Desc
+}
+
+var proxyMetrics *proxyCollector
+
+func init() {
+	ns := metrics.NewNamespace("engine", "daemon", nil)
+	proxyMetrics = &proxyCollector{
+		proxies: make(map[*internalProxy]struct{}),
+		desc: ns.NewDesc("userland_proxy_connections", "The number of active connections of the userland proxies running in the daemon", metrics.Unit(""),
+			"proto",
+			"host_ip",
+			"host_port",
+			"container_ip",
+			"container_port",
+		),
+	}
+	ns.Add(proxyMetrics)
+	metrics.Register(ns)
+}
+
+func (c *proxyCollector) add(p *internalProxy) {
+	c.mu.Lock()
+	c.proxies[p] = struct{}{}
+	c.mu.Unlock()
+}
+
+func (c *proxyCollector) remove(p *internalProxy) {
+	c.mu.Lock()
+	delete(c.proxies, p)
+	c.mu.Unlock()
+}
+
+func (c *proxyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *proxyCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for p := range c.proxies {
+		hostIP, hostPort := getIPAndPort(p.host)
+		containerIP, containerPort := getIPAndPort(p.container)
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(p.proxy.Connections()),
+			p.proto,
+			hostIP.String(),
+			strconv.Itoa(hostPort),
+			containerIP.String(),
+			strconv.Itoa(containerPort),
+		)
+	}
+}
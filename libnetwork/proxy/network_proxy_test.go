@@ -1,4 +1,4 @@
-package main
+package proxy
 
 import (
 	"bytes"
@@ -7,6 +7,7 @@ import (
 	"net"
 	"runtime"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -163,6 +164,11 @@ func testProxyAt(t *testing.T, proto string, proxy Proxy, addr string, halfClose
 	if !bytes.Equal(testBuf, recvBuf) {
 		t.Fatal(fmt.Errorf("Expected [%v] but got [%v]", testBuf, recvBuf))
 	}
+	if !halfClose {
+		if conns := proxy.Connections(); conns != 1 {
+			t.Fatalf("Expected 1 active connection but got %d", conns)
+		}
+	}
 }
 
 func testProxy(t *testing.T, proto string, proxy Proxy, halfClose bool) {
@@ -189,6 +195,61 @@ func TestTCP4ProxyHalfClose(t *testing.T) {
 	testTCP4Proxy(t, true)
 }
 
+// failingListener fails to accept the first connections.
+type failingListener struct {
+	net.Listener
+	failures int
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	if l.failures > 0 {
+		l.failures--
+		return nil, &net.OpError{Op: "accept", Net: "tcp", Err: syscall.EMFILE}
+	}
+	return l.Listener.Accept()
+}
+
+func TestTCP4ProxyAcceptError(t *testing.T) {
+	backend := NewEchoServer(t, "tcp", "127.0.0.1:0", EchoServerOptions{})
+	defer backend.Close()
+	backend.Run()
+	frontendAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0}
+	proxy, err := NewTCPProxy(frontendAddr, backend.LocalAddr().(*net.TCPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.listener = &failingListener{Listener: proxy.listener, failures: 3}
+
+	done := make(chan struct{})
+	go func() {
+		proxy.Run()
+		close(done)
+	}()
+	client, err := net.Dial("tcp", proxy.FrontendAddr().String())
+	if err != nil {
+		t.Fatalf("Can't connect to the proxy: %v", err)
+	}
+	defer client.Close()
+	client.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err = client.Write(testBuf); err != nil {
+		t.Fatal(err)
+	}
+	recvBuf := make([]byte, testBufSize)
+	if _, err = io.ReadFull(client, recvBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(testBuf, recvBuf) {
+		t.Fatalf("Expected [%v] but got [%v]", testBuf, recvBuf)
+	}
+
+	proxy.Close()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the proxy to stop once closed")
+	}
+}
+
 func TestTCP6Proxy(t *testing.T) {
 	t.Skip("Need to start CI docker with --ipv6")
 	backend := NewEchoServer(t, "tcp", "[::1]:0", EchoServerOptions{})
@@ -233,6 +294,25 @@ func TestUDP4Proxy(t *testing.T) {
 	testProxy(t, "udp", proxy, false)
 }
 
+func TestUDP4ProxyReadError(t *testing.T) {
+	backend := NewEchoServer(t, "udp", "127.0.0.1:0", EchoServerOptions{})
+	defer backend.Close()
+	backend.Run()
+	frontendAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0}
+	proxy, err := NewUDPProxy(frontendAddr, backend.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reading datagrams fails until the deadline is removed. The datagram
+	// of the client is read once it is.
+	proxy.listener.SetReadDeadline(time.Now())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		proxy.listener.SetReadDeadline(time.Time{})
+	}()
+	testProxy(t, "udp", proxy, false)
+}
+
 func TestUDP6Proxy(t *testing.T) {
 	t.Skip("Need to start CI docker with --ipv6")
 	backend := NewEchoServer(t, "udp", "[::1]:0", EchoServerOptions{})
@@ -294,6 +374,21 @@ func TestSCTP4Proxy(t *testing.T) {
 	testProxy(t, "sctp", proxy, false)
 }
 
+func TestSCTP4ProxyAcceptError(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "sctp is not supported on windows")
+
+	backend := NewEchoServer(t, "sctp", "127.0.0.1:0", EchoServerOptions{})
+	defer backend.Close()
+	backend.Run()
+	frontendAddr := &sctp.SCTPAddr{IPAddrs: []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, Port: 0}
+	proxy, err := NewSCTPProxy(frontendAddr, backend.LocalAddr().(*sctp.SCTPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.listener = &failingListener{Listener: proxy.listener, failures: 3}
+	testProxy(t, "sctp", proxy, false)
+}
+
 func TestSCTP6Proxy(t *testing.T) {
 	t.Skip("Need to start CI docker with --ipv6")
 	skip.If(t, runtime.GOOS == "windows", "sctp is not supported on windows")
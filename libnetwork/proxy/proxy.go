@@ -1,9 +1,11 @@
-// docker-proxy provides a network Proxy interface and implementations for TCP
-// and UDP.
-package main
+// Package proxy provides a network Proxy interface and implementations for
+// TCP, UDP and SCTP. They are used by docker-proxy, and by the daemon when the
+// userland proxy runs in the daemon.
+package proxy
 
 import (
 	"net"
+	"time"
 
 	"github.com/ishidawataru/sctp"
 )
@@ -18,6 +20,26 @@ const (
 	ipv6 ipVersion = "6"
 )
 
+const (
+	// minRetryDelay and maxRetryDelay bound the delay before accepting
+	// connections, or reading datagrams, again after a failure, such as
+	// running out of file descriptors.
+	minRetryDelay = 5 * time.Millisecond
+	maxRetryDelay = 1 * time.Second
+)
+
+// retryDelay returns the delay before retrying after a failure, given the
+// delay used after the previous consecutive failure, or 0 if there is none.
+func retryDelay(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return minRetryDelay
+	}
+	if delay *= 2; delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
 // Proxy defines the behavior of a proxy. It forwards traffic back and forth
 // between two endpoints : the frontend and the backend.
 // It can be used to do software port-mapping between two addresses.
@@ -33,6 +55,9 @@ type Proxy interface {
 	FrontendAddr() net.Addr
 	// BackendAddr returns the proxied address.
 	BackendAddr() net.Addr
+	// Connections returns the number of active connections of the proxy. For
+	// UDP, it is the number of clients whose datagrams are being forwarded.
+	Connections() int
 }
 
 // NewProxy creates a Proxy according to the specified frontendAddr and backendAddr.
@@ -1,18 +1,22 @@
-package main
+package proxy
 
 import (
+	"errors"
 	"io"
-	"log"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ishidawataru/sctp"
+	"github.com/sirupsen/logrus"
 )
 
 // SCTPProxy is a proxy for SCTP connections. It implements the Proxy interface to
 // handle SCTP traffic forwarding between the frontend and backend addresses.
 type SCTPProxy struct {
-	listener     *sctp.SCTPListener
+	conns        int64 // accessed atomically, keep 64-bit aligned
+	listener     net.Listener
 	frontendAddr *sctp.SCTPAddr
 	backendAddr  *sctp.SCTPAddr
 }
@@ -31,7 +35,7 @@ func NewSCTPProxy(frontendAddr, backendAddr *sctp.SCTPAddr) (*SCTPProxy, error)
 	// If the port in frontendAddr was 0 then ListenSCTP will have a picked
 	// a port to listen on, hence the call to Addr to get that actual port:
 	return &SCTPProxy{
-		listener:     listener,
+		listener:     &sctpListener{SCTPListener: listener},
 		frontendAddr: listener.Addr().(*sctp.SCTPAddr),
 		backendAddr:  backendAddr,
 	}, nil
@@ -40,10 +44,12 @@ func NewSCTPProxy(frontendAddr, backendAddr *sctp.SCTPAddr) (*SCTPProxy, error)
 func (proxy *SCTPProxy) clientLoop(client *sctp.SCTPConn, quit chan bool) {
 	backend, err := sctp.DialSCTP("sctp", nil, proxy.backendAddr)
 	if err != nil {
-		log.Printf("Can't forward traffic to backend sctp/%v: %s\n", proxy.backendAddr, err)
+		logrus.Errorf("Can't forward traffic to backend sctp/%v: %s", proxy.backendAddr, err)
 		client.Close()
 		return
 	}
+	atomic.AddInt64(&proxy.conns, 1)
+	defer atomic.AddInt64(&proxy.conns, -1)
 	clientC := sctp.NewSCTPSndRcvInfoWrappedConn(client)
 	backendC := sctp.NewSCTPSndRcvInfoWrappedConn(backend)
 
@@ -74,16 +80,25 @@ func (proxy *SCTPProxy) clientLoop(client *sctp.SCTPConn, quit chan bool) {
 	<-finish
 }
 
-// Run starts forwarding the traffic using SCTP.
+// Run starts forwarding the traffic using SCTP. It returns once the proxy is
+// closed. Failures to accept associations are retried with a backoff delay.
 func (proxy *SCTPProxy) Run() {
 	quit := make(chan bool)
 	defer close(quit)
+	var delay time.Duration
 	for {
 		client, err := proxy.listener.Accept()
 		if err != nil {
-			log.Printf("Stopping proxy on sctp/%v for sctp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
-			return
+			if errors.Is(err, net.ErrClosed) {
+				logrus.Infof("Stopping proxy on sctp/%v for sctp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
+				return
+			}
+			delay = retryDelay(delay)
+			logrus.Warnf("Failed to accept association on sctp/%v for sctp/%v, retrying in %v: %s", proxy.frontendAddr, proxy.backendAddr, delay, err)
+			time.Sleep(delay)
+			continue
 		}
+		delay = 0
 		go proxy.clientLoop(client.(*sctp.SCTPConn), quit)
 	}
 }
@@ -91,8 +106,35 @@ func (proxy *SCTPProxy) Run() {
 // Close stops forwarding the traffic.
 func (proxy *SCTPProxy) Close() { proxy.listener.Close() }
 
+// sctpListener is an SCTP listener returning net.ErrClosed once it is closed,
+// like the listeners of the net package, instead of the error of the accept
+// system call on the closed socket.
+type sctpListener struct {
+	*sctp.SCTPListener
+	closed int32 // accessed atomically
+}
+
+func (l *sctpListener) Accept() (net.Conn, error) {
+	conn, err := l.SCTPListener.Accept()
+	if err != nil {
+		if atomic.LoadInt32(&l.closed) != 0 {
+			err = &net.OpError{Op: "accept", Net: "sctp", Addr: l.Addr(), Err: net.ErrClosed}
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (l *sctpListener) Close() error {
+	atomic.StoreInt32(&l.closed, 1)
+	return l.SCTPListener.Close()
+}
+
 // FrontendAddr returns the SCTP address on which the proxy is listening.
 func (proxy *SCTPProxy) FrontendAddr() net.Addr { return proxy.frontendAddr }
 
 // BackendAddr returns the SCTP proxied address.
 func (proxy *SCTPProxy) BackendAddr() net.Addr { return proxy.backendAddr }
+
+// Connections returns the number of active SCTP associations.
+func (proxy *SCTPProxy) Connections() int { return int(atomic.LoadInt64(&proxy.conns)) }
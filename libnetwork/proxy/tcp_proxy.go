@@ -1,16 +1,21 @@
-package main
+package proxy
 
 import (
+	"errors"
 	"io"
-	"log"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 // TCPProxy is a proxy for TCP connections. It implements the Proxy interface to
 // handle TCP traffic forwarding between the frontend and backend addresses.
 type TCPProxy struct {
-	listener     *net.TCPListener
+	conns        int64 // accessed atomically, keep 64-bit aligned
+	listener     net.Listener
 	frontendAddr *net.TCPAddr
 	backendAddr  *net.TCPAddr
 }
@@ -38,10 +43,12 @@ func NewTCPProxy(frontendAddr, backendAddr *net.TCPAddr) (*TCPProxy, error) {
 func (proxy *TCPProxy) clientLoop(client *net.TCPConn, quit chan bool) {
 	backend, err := net.DialTCP("tcp", nil, proxy.backendAddr)
 	if err != nil {
-		log.Printf("Can't forward traffic to backend tcp/%v: %s\n", proxy.backendAddr, err)
+		logrus.Errorf("Can't forward traffic to backend tcp/%v: %s", proxy.backendAddr, err)
 		client.Close()
 		return
 	}
+	atomic.AddInt64(&proxy.conns, 1)
+	defer atomic.AddInt64(&proxy.conns, -1)
 
 	var wg sync.WaitGroup
 	var broker = func(to, from *net.TCPConn) {
@@ -70,16 +77,25 @@ func (proxy *TCPProxy) clientLoop(client *net.TCPConn, quit chan bool) {
 	<-finish
 }
 
-// Run starts forwarding the traffic using TCP.
+// Run starts forwarding the traffic using TCP. It returns once the proxy is
+// closed. Failures to accept connections are retried with a backoff delay.
 func (proxy *TCPProxy) Run() {
 	quit := make(chan bool)
 	defer close(quit)
+	var delay time.Duration
 	for {
 		client, err := proxy.listener.Accept()
 		if err != nil {
-			log.Printf("Stopping proxy on tcp/%v for tcp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
-			return
+			if errors.Is(err, net.ErrClosed) {
+				logrus.Infof("Stopping proxy on tcp/%v for tcp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
+				return
+			}
+			delay = retryDelay(delay)
+			logrus.Warnf("Failed to accept connection on tcp/%v for tcp/%v, retrying in %v: %s", proxy.frontendAddr, proxy.backendAddr, delay, err)
+			time.Sleep(delay)
+			continue
 		}
+		delay = 0
 		go proxy.clientLoop(client.(*net.TCPConn), quit)
 	}
 }
@@ -92,3 +108,6 @@ func (proxy *TCPProxy) FrontendAddr() net.Addr { return proxy.frontendAddr }
 
 // BackendAddr returns the TCP proxied address.
 func (proxy *TCPProxy) BackendAddr() net.Addr { return proxy.backendAddr }
+
+// Connections returns the number of active TCP connections.
+func (proxy *TCPProxy) Connections() int { return int(atomic.LoadInt64(&proxy.conns)) }
@@ -1,13 +1,14 @@
-package main
+package proxy
 
 import (
 	"encoding/binary"
-	"log"
+	"errors"
 	"net"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -106,20 +107,26 @@ func (proxy *UDPProxy) replyLoop(proxyConn *net.UDPConn, clientAddr *net.UDPAddr
 	}
 }
 
-// Run starts forwarding the traffic using UDP.
+// Run starts forwarding the traffic using UDP. It returns once the proxy is
+// closed. Failures to read datagrams are retried with a backoff delay.
 func (proxy *UDPProxy) Run() {
 	readBuf := make([]byte, UDPBufSize)
+	var delay time.Duration
 	for {
 		read, from, err := proxy.listener.ReadFromUDP(readBuf)
 		if err != nil {
 			// NOTE: Apparently ReadFrom doesn't return
 			// ECONNREFUSED like Read do (see comment in
 			// UDPProxy.replyLoop)
-			if !isClosedError(err) {
-				log.Printf("Stopping proxy on udp/%v for udp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
+			if errors.Is(err, net.ErrClosed) {
+				break
 			}
-			break
+			delay = retryDelay(delay)
+			logrus.Warnf("Failed to read datagram on udp/%v for udp/%v, retrying in %v: %s", proxy.frontendAddr, proxy.backendAddr, delay, err)
+			time.Sleep(delay)
+			continue
 		}
+		delay = 0
 
 		fromKey := newConnTrackKey(from)
 		proxy.connTrackLock.Lock()
@@ -127,7 +134,7 @@ func (proxy *UDPProxy) Run() {
 		if !hit {
 			proxyConn, err = net.DialUDP("udp", nil, proxy.backendAddr)
 			if err != nil {
-				log.Printf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
+				logrus.Errorf("Can't proxy a datagram to udp/%s: %s", proxy.backendAddr, err)
 				proxy.connTrackLock.Unlock()
 				continue
 			}
@@ -138,7 +145,7 @@ func (proxy *UDPProxy) Run() {
 		for i := 0; i != read; {
 			written, err := proxyConn.Write(readBuf[i:read])
 			if err != nil {
-				log.Printf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
+				logrus.Errorf("Can't proxy a datagram to udp/%s: %s", proxy.backendAddr, err)
 				break
 			}
 			i += written
@@ -162,12 +169,10 @@ func (proxy *UDPProxy) FrontendAddr() net.Addr { return proxy.frontendAddr }
 // BackendAddr returns the proxied UDP address.
 func (proxy *UDPProxy) BackendAddr() net.Addr { return proxy.backendAddr }
 
-func isClosedError(err error) bool {
-	/* This comparison is ugly, but unfortunately, net.go doesn't export errClosing.
-	 * See:
-	 * http://golang.org/src/pkg/net/net.go
-	 * https://code.google.com/p/go/issues/detail?id=4337
-	 * https://groups.google.com/forum/#!msg/golang-nuts/0_aaCvBmOcM/SptmDyX1XJMJ
-	 */
-	return strings.HasSuffix(err.Error(), "use of closed network connection")
+// Connections returns the number of clients whose datagrams are being
+// forwarded, until they time out.
+func (proxy *UDPProxy) Connections() int {
+	proxy.connTrackLock.Lock()
+	defer proxy.connTrackLock.Unlock()
+	return len(proxy.connTrackTable)
 }
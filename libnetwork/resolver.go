@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/docker/libnetwork/firewall"
 	"github.com/docker/docker/libnetwork/types"
 	"github.com/miekg/dns"
 	"github.com/sirupsen/logrus"
@@ -57,6 +58,11 @@ type DNSBackend interface {
 	// HandleQueryResp passes the name & IP from a response to the backend. backend
 	// can use it to maintain any required state about the resolution
 	HandleQueryResp(name string, ip net.IP)
+	// ResolveStatic returns the static DNS records with the passed name of the
+	// networks the sandbox is connected to. The second return value is true if
+	// any of the networks has records for the name, in which case queries for
+	// the name shouldn't be forwarded to external nameservers.
+	ResolveStatic(name string) ([]DNSRecord, bool)
 }
 
 const (
@@ -69,6 +75,7 @@ const (
 	defaultRespSize = 512
 	maxConcurrent   = 1024
 	logInterval     = 2 * time.Second
+	maxCNAMEChain   = 8 // max number of static CNAME records to follow
 )
 
 type extDNSEntry struct {
@@ -91,7 +98,9 @@ type resolver struct {
 	listenAddress string
 	proxyDNS      bool
 	resolverKey   string
+	firewall      firewall.Firewaller
 	startCh       chan struct{}
+	cache         *answerCache
 }
 
 func init() {
@@ -100,13 +109,22 @@ func init() {
 
 // NewResolver creates a new instance of the Resolver
 func NewResolver(address string, proxyDNS bool, resolverKey string, backend DNSBackend) Resolver {
+	return newResolver(address, proxyDNS, resolverKey, backend, nil)
+}
+
+// newResolver creates a new instance of the Resolver, redirecting the DNS
+// traffic of the sandbox with the firewaller. The default firewaller is used
+// if fw is nil.
+func newResolver(address string, proxyDNS bool, resolverKey string, backend DNSBackend, fw firewall.Firewaller) *resolver {
 	return &resolver{
 		backend:       backend,
 		proxyDNS:      proxyDNS,
 		listenAddress: address,
 		resolverKey:   resolverKey,
+		firewall:      fw,
 		err:           fmt.Errorf("setup not done yet"),
 		startCh:       make(chan struct{}, 1),
+		cache:         newAnswerCache(maxCacheEntries),
 	}
 }
 
@@ -198,6 +216,8 @@ func (r *resolver) SetExtServers(extDNS []extDNSEntry) {
 	for i := 0; i < l; i++ {
 		r.extDNSList[i] = extDNS[i]
 	}
+	// Responses of the previous servers may not be valid anymore.
+	r.cache.flush()
 }
 
 func (r *resolver) NameServer() string {
@@ -287,6 +307,103 @@ func (r *resolver) handleIPQuery(query *dns.Msg, ipType int) (*dns.Msg, error) {
 	return resp, nil
 }
 
+// handleStaticQuery answers the query from the static DNS records of the
+// networks. Once a name has static records, it is not forwarded to external
+// nameservers: queries for types without records get an empty response.
+func (r *resolver) handleStaticQuery(query *dns.Msg) *dns.Msg {
+	var (
+		name  = query.Question[0].Name
+		qtype = query.Question[0].Qtype
+	)
+	records, ok := r.backend.ResolveStatic(name)
+	if !ok {
+		return nil
+	}
+
+	logrus.Debugf("[resolver] lookup for %s: static records %v", name, records)
+
+	resp := createRespMsg(query)
+	for i := 0; i < maxCNAMEChain; i++ {
+		var target string
+		for _, rec := range records {
+			rr := staticRR(name, rec)
+			if rr == nil {
+				continue
+			}
+			switch h := rr.Header(); {
+			case h.Rrtype == qtype || qtype == dns.TypeANY:
+				resp.Answer = append(resp.Answer, rr)
+			case h.Rrtype == dns.TypeCNAME:
+				resp.Answer = append(resp.Answer, rr)
+				target = dns.Fqdn(rec.Value)
+			}
+		}
+		if target == "" {
+			break
+		}
+		name = target
+		if records, ok = r.backend.ResolveStatic(name); ok {
+			continue
+		}
+		// The alias may point to a container or a service of the networks.
+		switch qtype {
+		case dns.TypeA, dns.TypeAAAA:
+			ipType := types.IPv4
+			if qtype == dns.TypeAAAA {
+				ipType = types.IPv6
+			}
+			addr, _ := r.backend.ResolveName(name, ipType)
+			for _, ip := range addr {
+				resp.Answer = append(resp.Answer, ipRR(name, ip, respTTL))
+			}
+		}
+		break
+	}
+	return resp
+}
+
+// staticRR returns the resource record of a static DNS record of a network.
+func staticRR(name string, rec DNSRecord) dns.RR {
+	ttl := rec.TTL
+	if ttl == 0 {
+		ttl = respTTL
+	}
+	hdr := dns.RR_Header{Name: name, Class: dns.ClassINET, Ttl: ttl}
+	switch rec.Type {
+	case DNSRecordA, DNSRecordAAAA:
+		ip := net.ParseIP(rec.Value)
+		if ip == nil {
+			return nil
+		}
+		return ipRR(name, ip, ttl)
+	case DNSRecordCNAME:
+		hdr.Rrtype = dns.TypeCNAME
+		return &dns.CNAME{Hdr: hdr, Target: dns.Fqdn(rec.Value)}
+	case DNSRecordTXT:
+		hdr.Rrtype = dns.TypeTXT
+		return &dns.TXT{Hdr: hdr, Txt: splitTXT(rec.Value)}
+	}
+	return nil
+}
+
+func ipRR(name string, ip net.IP, ttl uint32) dns.RR {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}, A: ip4}
+	}
+	return &dns.AAAA{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl}, AAAA: ip}
+}
+
+// splitTXT splits the text of a TXT record into character strings, which are
+// at most 255 bytes long.
+func splitTXT(txt string) []string {
+	var out []string
+	for len(txt) > 255 {
+		out = append(out, txt[:255])
+		txt = txt[255:]
+	}
+	return append(out, txt)
+}
+
 func (r *resolver) handlePTRQuery(query *dns.Msg) (*dns.Msg, error) {
 	var (
 		parts []string
@@ -401,6 +518,10 @@ func (r *resolver) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
 		return
 	}
 
+	if resp == nil {
+		resp = r.handleStaticQuery(query)
+	}
+
 	if resp == nil {
 		// If the backend doesn't support proxying dns request
 		// fail the response
@@ -439,6 +560,10 @@ func (r *resolver) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
 		}
 	}
 
+	if resp == nil {
+		resp = r.cache.get(query)
+	}
+
 	if resp != nil {
 		if resp.Len() > maxSize {
 			truncateResp(resp, maxSize, proto == "tcp")
@@ -556,6 +681,7 @@ func (r *resolver) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
 		if resp == nil {
 			return
 		}
+		r.cache.set(query, resp)
 	}
 
 	if err = w.WriteMsg(resp); err != nil {
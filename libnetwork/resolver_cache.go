@@ -0,0 +1,196 @@
+package libnetwork
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	maxCacheEntries = 4096
+	// maxCacheTTL is the maximum time in seconds a response is cached,
+	// whatever the TTL of its records.
+	maxCacheTTL = 3600
+	// maxNegCacheTTL is the maximum time in seconds a response is cached for a
+	// name which does not exist, or which has no records of the queried type.
+	maxNegCacheTTL = 900
+)
+
+type cacheKey struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+	// dnssec is set for queries with the DO or CD bits, whose responses
+	// include DNSSEC records, or are not validated.
+	dnssec bool
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	msg     *dns.Msg
+	stored  time.Time
+	expires time.Time
+}
+
+// answerCache caches the responses of external DNS servers for the TTL of
+// their records, as well as the responses for names which do not exist, for
+// the TTL of the SOA record of their zone (RFC 2308). The least recently used
+// responses are evicted once the cache is full.
+type answerCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+func newAnswerCache(max int) *answerCache {
+	return &answerCache{
+		max:     max,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+func newCacheKey(query *dns.Msg) cacheKey {
+	q := query.Question[0]
+	key := cacheKey{
+		name:   strings.ToLower(q.Name),
+		qtype:  q.Qtype,
+		qclass: q.Qclass,
+	}
+	if opt := query.IsEdns0(); opt != nil && opt.Do() {
+		key.dnssec = true
+	}
+	if query.CheckingDisabled {
+		key.dnssec = true
+	}
+	return key
+}
+
+// get returns the cached response for the query, with the TTLs of its
+// records decreased by the time it has been cached, or nil if there is no
+// response for the query in the cache.
+func (c *answerCache) get(query *dns.Msg) *dns.Msg {
+	key := newCacheKey(query)
+	now := c.now()
+
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if !now.Before(e.expires) {
+		c.remove(el)
+		c.mu.Unlock()
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	resp := e.msg.Copy()
+	elapsed := uint32(now.Sub(e.stored) / time.Second)
+	c.mu.Unlock()
+
+	resp.Id = query.Id
+	resp.Question = query.Question
+	for _, rrs := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, rr := range rrs {
+			h := rr.Header()
+			if h.Rrtype == dns.TypeOPT {
+				continue
+			}
+			if h.Ttl > elapsed {
+				h.Ttl -= elapsed
+			} else {
+				h.Ttl = 0
+			}
+		}
+	}
+	return resp
+}
+
+// set caches the response to the query, if it may be cached.
+func (c *answerCache) set(query, resp *dns.Msg) {
+	ttl, ok := cacheTTL(resp)
+	if !ok {
+		return
+	}
+	key := newCacheKey(query)
+	now := c.now()
+	e := &cacheEntry{
+		key:     key,
+		msg:     resp.Copy(),
+		stored:  now,
+		expires: now.Add(time.Duration(ttl) * time.Second),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+	}
+}
+
+// flush removes all the responses from the cache.
+func (c *answerCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.lru.Init()
+}
+
+// remove removes an element of the cache. It is expected that callers of
+// this function hold the lock.
+func (c *answerCache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+// cacheTTL returns the time in seconds the response may be cached for, and
+// false if the response may not be cached.
+func cacheTTL(resp *dns.Msg) (uint32, bool) {
+	if resp.Truncated {
+		return 0, false
+	}
+	switch {
+	case resp.Rcode == dns.RcodeSuccess && len(resp.Answer) > 0:
+		ttl := uint32(maxCacheTTL)
+		for _, rrs := range [][]dns.RR{resp.Answer, resp.Ns} {
+			for _, rr := range rrs {
+				if h := rr.Header(); h.Ttl < ttl {
+					ttl = h.Ttl
+				}
+			}
+		}
+		return ttl, ttl > 0
+	case resp.Rcode == dns.RcodeSuccess, resp.Rcode == dns.RcodeNameError:
+		// Negative responses are only cached when they include the SOA
+		// record of the zone, which determines for how long (RFC 2308).
+		for _, rr := range resp.Ns {
+			soa, ok := rr.(*dns.SOA)
+			if !ok {
+				continue
+			}
+			ttl := soa.Hdr.Ttl
+			if soa.Minttl < ttl {
+				ttl = soa.Minttl
+			}
+			if ttl > maxNegCacheTTL {
+				ttl = maxNegCacheTTL
+			}
+			return ttl, ttl > 0
+		}
+	}
+	return 0, false
+}
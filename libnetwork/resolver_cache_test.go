@@ -0,0 +1,133 @@
+package libnetwork
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestCache(max int) (*answerCache, *time.Time) {
+	now := time.Now()
+	c := newAnswerCache(max)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func newTestResp(query *dns.Msg, ttl uint32) *dns.Msg {
+	resp := new(dns.Msg)
+	resp.SetReply(query)
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: query.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+	return resp
+}
+
+func TestAnswerCache(t *testing.T) {
+	c, now := newTestCache(10)
+
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+	assert.Check(t, is.Nil(c.get(q)))
+
+	c.set(q, newTestResp(q, 60))
+
+	// Lookups are case insensitive, and answered with the ID and the
+	// question of the query.
+	q2 := new(dns.Msg)
+	q2.SetQuestion("EXAMPLE.com.", dns.TypeA)
+	*now = now.Add(20 * time.Second)
+	resp := c.get(q2)
+	assert.Assert(t, resp != nil)
+	assert.Check(t, is.Equal(resp.Id, q2.Id))
+	assert.Check(t, is.Equal(resp.Question[0].Name, "EXAMPLE.com."))
+	assert.Assert(t, is.Len(resp.Answer, 1))
+	assert.Check(t, is.Equal(resp.Answer[0].Header().Ttl, uint32(40)))
+
+	// Other query types are not answered from the cache.
+	q3 := new(dns.Msg)
+	q3.SetQuestion("example.com.", dns.TypeAAAA)
+	assert.Check(t, is.Nil(c.get(q3)))
+
+	// Responses expire with the TTL of their records.
+	*now = now.Add(40 * time.Second)
+	assert.Check(t, is.Nil(c.get(q)))
+
+	c.set(q, newTestResp(q, 60))
+	c.flush()
+	assert.Check(t, is.Nil(c.get(q)))
+}
+
+func TestAnswerCacheNegative(t *testing.T) {
+	c, now := newTestCache(10)
+
+	q := new(dns.Msg)
+	q.SetQuestion("nonexistent.example.com.", dns.TypeA)
+
+	// Negative responses without SOA record are not cached.
+	resp := new(dns.Msg)
+	resp.SetRcode(q, dns.RcodeNameError)
+	c.set(q, resp)
+	assert.Check(t, is.Nil(c.get(q)))
+
+	// Negative responses are cached for the minimum of the TTL of the SOA
+	// record and of the minimum field of the SOA record.
+	resp.Ns = append(resp.Ns, &dns.SOA{
+		Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+		Ns:     "ns.example.com.",
+		Mbox:   "hostmaster.example.com.",
+		Minttl: 30,
+	})
+	c.set(q, resp)
+	cached := c.get(q)
+	assert.Assert(t, cached != nil)
+	assert.Check(t, is.Equal(cached.Rcode, dns.RcodeNameError))
+
+	*now = now.Add(30 * time.Second)
+	assert.Check(t, is.Nil(c.get(q)))
+}
+
+func TestAnswerCacheUncacheable(t *testing.T) {
+	c, _ := newTestCache(10)
+
+	q := new(dns.Msg)
+	q.SetQuestion("example.com.", dns.TypeA)
+
+	resp := new(dns.Msg)
+	resp.SetRcode(q, dns.RcodeServerFailure)
+	c.set(q, resp)
+	assert.Check(t, is.Nil(c.get(q)))
+
+	resp = newTestResp(q, 60)
+	resp.Truncated = true
+	c.set(q, resp)
+	assert.Check(t, is.Nil(c.get(q)))
+
+	c.set(q, newTestResp(q, 0))
+	assert.Check(t, is.Nil(c.get(q)))
+}
+
+func TestAnswerCacheEviction(t *testing.T) {
+	c, _ := newTestCache(2)
+
+	var queries []*dns.Msg
+	for _, name := range []string{"a.example.com.", "b.example.com.", "c.example.com."} {
+		q := new(dns.Msg)
+		q.SetQuestion(name, dns.TypeA)
+		queries = append(queries, q)
+	}
+
+	c.set(queries[0], newTestResp(queries[0], 60))
+	c.set(queries[1], newTestResp(queries[1], 60))
+	// Use the first response, so that the second is the least recently used.
+	assert.Check(t, c.get(queries[0]) != nil)
+	c.set(queries[2], newTestResp(queries[2], 60))
+
+	assert.Check(t, c.get(queries[0]) != nil)
+	assert.Check(t, is.Nil(c.get(queries[1])))
+	assert.Check(t, c.get(queries[2]) != nil)
+}
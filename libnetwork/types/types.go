@@ -68,6 +68,25 @@ func (t *TransportPort) String() string {
 	return fmt.Sprintf("%s/%d", t.Proto.String(), t.Port)
 }
 
+// Bandwidth represents the bandwidth limits of an endpoint, from the point of
+// view of the container. Rates are in bytes per second, bursts in bytes, and
+// zero values mean no limit.
+type Bandwidth struct {
+	IngressRate  uint64
+	IngressBurst uint64
+	EgressRate   uint64
+	EgressBurst  uint64
+}
+
+// GetCopy returns a copy of this Bandwidth structure instance
+func (b *Bandwidth) GetCopy() *Bandwidth {
+	if b == nil {
+		return nil
+	}
+	bCopy := *b
+	return &bCopy
+}
+
 // PortBinding represents a port binding between the container and the host
 type PortBinding struct {
 	Proto       Protocol
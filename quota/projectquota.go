@@ -2,12 +2,11 @@
 // +build linux,!exclude_disk_quota,cgo
 
 //
-// projectquota.go - implements XFS project quota controls
+// projectquota.go - implements XFS and ext4 project quota controls
 // for setting quota limits on a newly created directory.
-// It currently supports the legacy XFS specific ioctls.
-//
-// TODO: use generic quota control ioctl FS_IOC_FS{GET,SET}XATTR
-//       for both xfs/ext4 for kernel version >= v4.5
+// Project ids are assigned with the generic FS_IOC_FS{GET,SET}XATTR
+// ioctls. Quota limits are set with the XFS specific quotactl commands
+// on xfs, and with the generic VFS quotactl commands on ext4.
 //
 
 package quota // import "github.com/docker/docker/quota"
@@ -65,6 +64,14 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// The commands of the generic VFS quota interface do not fit in a C int once
+// combined with the quota type, so they are combined here instead of using
+// QCMD.
+const (
+	qGetPQuota = C.Q_GETQUOTA<<C.SUBCMDSHIFT | C.PRJQUOTA
+	qSetPQuota = C.Q_SETQUOTA<<C.SUBCMDSHIFT | C.PRJQUOTA
+)
+
 type pquotaState struct {
 	sync.Mutex
 	nextProjectID uint32
@@ -99,7 +106,9 @@ func (state *pquotaState) updateMinProjID(minProjectID uint32) {
 // Returns nil (and error) if project quota is not supported.
 //
 // First get the project id of the home directory.
-// This test will fail if the backing fs is not xfs.
+// This test will fail if the backing fs is not xfs or ext4. Project quotas
+// on ext4 require a filesystem with the "project" and "quota" features,
+// mounted with the "prjquota" option.
 //
 // xfs_quota tool can be used to assign a project id to the driver home directory, e.g.:
 //
@@ -132,7 +141,9 @@ func NewControl(basePath string) (*Control, error) {
 	}
 
 	// check if we can call quotactl with project quotas
-	// as a mechanism to determine (early) if we have support
+	// as a mechanism to determine (early) if we have support.
+	// The kernel translates this command to the VFS quota interface
+	// for filesystems other than xfs.
 	hasQuotaSupport, err := hasQuotaSupport(backingFsBlockDev)
 	if err != nil {
 		return nil, err
@@ -150,6 +161,21 @@ func NewControl(basePath string) (*Control, error) {
 	}
 	minProjectID := baseProjectID + 1
 
+	//
+	// ext4 implements project quotas through the generic VFS quota
+	// interface, xfs through its own interface
+	//
+	var stat unix.Statfs_t
+	if err := unix.Statfs(basePath, &stat); err != nil {
+		return nil, errors.Wrapf(err, "failed to get filesystem type of %s", basePath)
+	}
+
+	q := Control{
+		backingFsBlockDev: backingFsBlockDev,
+		vfsQuota:          stat.Type == unix.EXT4_SUPER_MAGIC,
+		quotas:            make(map[string]uint32),
+	}
+
 	//
 	// Test if filesystem supports project quotas by trying to set
 	// a quota on the first available project id
@@ -157,15 +183,10 @@ func NewControl(basePath string) (*Control, error) {
 	quota := Quota{
 		Size: 0,
 	}
-	if err := setProjectQuota(backingFsBlockDev, minProjectID, quota); err != nil {
+	if err := q.setProjectQuota(minProjectID, quota); err != nil {
 		return nil, err
 	}
 
-	q := Control{
-		backingFsBlockDev: backingFsBlockDev,
-		quotas:            make(map[string]uint32),
-	}
-
 	//
 	// update minimum project ID
 	//
@@ -216,11 +237,19 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 	// set the quota limit for the container's project id
 	//
 	logrus.Debugf("SetQuota(%s, %d): projectID=%d", targetPath, quota.Size, projectID)
-	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
+	return q.setProjectQuota(projectID, quota)
 }
 
-// setProjectQuota - set the quota for project id on xfs block device
-func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
+// setProjectQuota - set the quota for project id on the backing block device
+func (q *Control) setProjectQuota(projectID uint32, quota Quota) error {
+	if q.vfsQuota {
+		return setVfsProjectQuota(q.backingFsBlockDev, projectID, quota)
+	}
+	return setXfsProjectQuota(q.backingFsBlockDev, projectID, quota)
+}
+
+// setXfsProjectQuota - set the quota for project id on xfs block device
+func setXfsProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
 	var d C.fs_disk_quota_t
 	d.d_version = C.FS_DQUOT_VERSION
 	d.d_id = C.__u32(projectID)
@@ -244,36 +273,105 @@ func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) er
 	return nil
 }
 
+// setVfsProjectQuota - set the quota for project id on a block device of a
+// filesystem using the generic VFS quota interface (ext4)
+func setVfsProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
+	var d C.struct_if_dqblk
+	d.dqb_bhardlimit = C.__u64(quota.Size / C.QIF_DQBLKSIZE)
+	d.dqb_bsoftlimit = d.dqb_bhardlimit
+	d.dqb_valid = C.QIF_BLIMITS
+
+	var cs = C.CString(backingFsBlockDev)
+	defer C.free(unsafe.Pointer(cs))
+
+	_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, qSetPQuota,
+		uintptr(unsafe.Pointer(cs)), uintptr(projectID),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return errors.Wrapf(errno, "failed to set quota limit for projid %d on %s",
+			projectID, backingFsBlockDev)
+	}
+
+	return nil
+}
+
 // GetQuota - get the quota limits of a directory that was configured with SetQuota
 func (q *Control) GetQuota(targetPath string, quota *Quota) error {
-	q.RLock()
-	projectID, ok := q.quotas[targetPath]
-	q.RUnlock()
-	if !ok {
-		return errors.Errorf("quota not found for path: %s", targetPath)
+	projectID, err := q.getProjectIDOf(targetPath)
+	if err != nil {
+		return err
 	}
 
 	//
 	// get the quota limit for the container's project id
 	//
-	var d C.fs_disk_quota_t
+	limit, _, err := q.getProjectQuota(projectID)
+	if err != nil {
+		return err
+	}
+	quota.Size = limit
+
+	return nil
+}
 
+// GetUsage - get the disk space used by a directory that was configured with
+// SetQuota, as accounted by the filesystem
+func (q *Control) GetUsage(targetPath string, usage *Usage) error {
+	projectID, err := q.getProjectIDOf(targetPath)
+	if err != nil {
+		return err
+	}
+
+	_, used, err := q.getProjectQuota(projectID)
+	if err != nil {
+		return err
+	}
+	usage.Size = used
+
+	return nil
+}
+
+// getProjectIDOf - get the project id assigned to a directory by SetQuota
+func (q *Control) getProjectIDOf(targetPath string) (uint32, error) {
+	q.RLock()
+	projectID, ok := q.quotas[targetPath]
+	q.RUnlock()
+	if !ok {
+		return 0, errors.Errorf("quota not found for path: %s", targetPath)
+	}
+	return projectID, nil
+}
+
+// getProjectQuota - get the quota limit and the disk space used, in bytes,
+// of project id on the backing block device
+func (q *Control) getProjectQuota(projectID uint32) (limit, used uint64, err error) {
 	var cs = C.CString(q.backingFsBlockDev)
 	defer C.free(unsafe.Pointer(cs))
 
+	if q.vfsQuota {
+		var d C.struct_if_dqblk
+		_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, qGetPQuota,
+			uintptr(unsafe.Pointer(cs)), uintptr(C.__u32(projectID)),
+			uintptr(unsafe.Pointer(&d)), 0, 0)
+		if errno != 0 {
+			return 0, 0, errors.Wrapf(errno, "Failed to get quota limit for projid %d on %s",
+				projectID, q.backingFsBlockDev)
+		}
+		return uint64(d.dqb_bhardlimit) * C.QIF_DQBLKSIZE, uint64(d.dqb_curspace), nil
+	}
+
+	var d C.fs_disk_quota_t
 	_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, C.Q_XGETPQUOTA,
 		uintptr(unsafe.Pointer(cs)), uintptr(C.__u32(projectID)),
 		uintptr(unsafe.Pointer(&d)), 0, 0)
 	if errno != 0 {
-		return errors.Wrapf(errno, "Failed to get quota limit for projid %d on %s",
+		return 0, 0, errors.Wrapf(errno, "Failed to get quota limit for projid %d on %s",
 			projectID, q.backingFsBlockDev)
 	}
-	quota.Size = uint64(d.d_blk_hardlimit) * 512
-
-	return nil
+	return uint64(d.d_blk_hardlimit) * 512, uint64(d.d_bcount) * 512, nil
 }
 
-// getProjectID - get the project id of path on xfs
+// getProjectID - get the project id of path
 func getProjectID(targetPath string) (uint32, error) {
 	dir, err := openDir(targetPath)
 	if err != nil {
@@ -291,7 +389,7 @@ func getProjectID(targetPath string) (uint32, error) {
 	return uint32(fsx.fsx_projid), nil
 }
 
-// setProjectID - set the project id of path on xfs
+// setProjectID - set the project id of path
 func setProjectID(targetPath string, projectID uint32) error {
 	dir, err := openDir(targetPath)
 	if err != nil {
@@ -33,6 +33,7 @@ func TestBlockDev(t *testing.T) {
 	t.Run("testSmallerThanQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testSmallerThanQuota)))
 	t.Run("testBiggerThanQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testBiggerThanQuota)))
 	t.Run("testRetrieveQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testRetrieveQuota)))
+	t.Run("testRetrieveUsage", WrapMountTest(imageFileName, true, WrapQuotaTest(testRetrieveUsage)))
 }
 
 func testBlockDevQuotaDisabled(t *testing.T, mountPoint, backingFsDev, testDir string) {
@@ -76,3 +77,13 @@ func testRetrieveQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir
 	assert.NilError(t, ctrl.GetQuota(testSubDir, &q))
 	assert.Check(t, is.Equal(uint64(testQuotaSize), q.Size))
 }
+
+func testRetrieveUsage(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir string) {
+	// Validate that the usage of the directory is accounted for
+	assert.NilError(t, ctrl.SetQuota(testSubDir, Quota{}))
+	assert.NilError(t, os.WriteFile(filepath.Join(testSubDir, "file"), make([]byte, testQuotaSize/2), 0644))
+
+	var u Usage
+	assert.NilError(t, ctrl.GetUsage(testSubDir, &u))
+	assert.Check(t, u.Size >= testQuotaSize/2, "usage: %d", u.Size)
+}
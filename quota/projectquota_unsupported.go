@@ -17,3 +17,9 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 func (q *Control) GetQuota(targetPath string, quota *Quota) error {
 	return ErrQuotaNotSupported
 }
+
+// GetUsage - get the disk space used by a directory that was configured with
+// SetQuota, as accounted by the filesystem
+func (q *Control) GetUsage(targetPath string, usage *Usage) error {
+	return ErrQuotaNotSupported
+}
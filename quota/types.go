@@ -7,10 +7,16 @@ type Quota struct {
 	Size uint64
 }
 
+// Usage - disk space used by a directory, as accounted by the filesystem
+type Usage struct {
+	Size uint64
+}
+
 // Control - Context to be used by storage driver (e.g. overlay)
 // who wants to apply project quotas to container dirs
 type Control struct {
 	backingFsBlockDev string
-	sync.RWMutex      // protect nextProjectID and quotas map
+	vfsQuota          bool // use the generic VFS quota interface (ext4) instead of the XFS one
+	sync.RWMutex           // protect nextProjectID and quotas map
 	quotas            map[string]uint32
 }
@@ -3,6 +3,7 @@ package restartmanager // import "github.com/docker/docker/restartmanager"
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -12,9 +13,13 @@ import (
 const (
 	backoffMultiplier = 2
 	defaultTimeout    = 100 * time.Millisecond
-	maxRestartTimeout = 1 * time.Minute
+	resetTimeoutAfter = 10 * time.Second
 )
 
+// DefaultMaxDelay is the maximum delay between restarts, when the restart
+// policy doesn't set one.
+const DefaultMaxDelay = 1 * time.Minute
+
 // ErrRestartCanceled is returned when the restart manager has been
 // canceled and will no longer restart the container.
 var ErrRestartCanceled = errors.New("restart canceled")
@@ -23,6 +28,8 @@ var ErrRestartCanceled = errors.New("restart canceled")
 type RestartManager interface {
 	Cancel() error
 	ShouldRestart(exitCode uint32, hasBeenManuallyStopped bool, executionDuration time.Duration) (bool, chan error, error)
+	ForceRestart()
+	NextRestart() time.Time
 }
 
 type restartManager struct {
@@ -34,6 +41,8 @@ type restartManager struct {
 	active       bool
 	cancel       chan struct{}
 	canceled     bool
+	force        bool
+	nextRestart  time.Time
 }
 
 // New returns a new restartManager based on a policy.
@@ -47,10 +56,16 @@ func (rm *restartManager) SetPolicy(policy container.RestartPolicy) {
 	rm.Unlock()
 }
 
+// ForceRestart makes the next call to ShouldRestart restart the container,
+// regardless of the restart policy and of its exit code, unless it has been
+// manually stopped.
+func (rm *restartManager) ForceRestart() {
+	rm.Lock()
+	rm.force = true
+	rm.Unlock()
+}
+
 func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped bool, executionDuration time.Duration) (bool, chan error, error) {
-	if rm.policy.IsNone() {
-		return false, nil, nil
-	}
 	rm.Lock()
 	unlockOnExit := true
 	defer func() {
@@ -59,6 +74,12 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 		}
 	}()
 
+	force := rm.force
+	rm.force = false
+	if rm.policy.IsNone() && !force {
+		return false, nil, nil
+	}
+
 	if rm.canceled {
 		return false, nil, ErrRestartCanceled
 	}
@@ -66,23 +87,34 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	if rm.active {
 		return false, nil, fmt.Errorf("invalid call on an active restart manager")
 	}
-	// if the container ran for more than 10s, regardless of status and policy reset the
-	// the timeout back to the default.
-	if executionDuration.Seconds() >= 10 {
+	var (
+		initialTimeout = durationWithDefault(rm.policy.InitialDelay, defaultTimeout)
+		maxTimeout     = durationWithDefault(rm.policy.MaxDelay, DefaultMaxDelay)
+		resetAfter     = durationWithDefault(rm.policy.ResetAfter, resetTimeoutAfter)
+		multiplier     = rm.policy.Multiplier
+	)
+	if multiplier == 0 {
+		multiplier = backoffMultiplier
+	}
+	// if the container ran for more than ResetAfter (10s by default), regardless
+	// of status and policy reset the timeout back to the initial delay.
+	if executionDuration >= resetAfter {
 		rm.timeout = 0
 	}
 	switch {
 	case rm.timeout == 0:
-		rm.timeout = defaultTimeout
-	case rm.timeout < maxRestartTimeout:
-		rm.timeout *= backoffMultiplier
+		rm.timeout = initialTimeout
+	case rm.timeout < maxTimeout:
+		rm.timeout = time.Duration(float64(rm.timeout) * multiplier)
 	}
-	if rm.timeout > maxRestartTimeout {
-		rm.timeout = maxRestartTimeout
+	if rm.timeout > maxTimeout {
+		rm.timeout = maxTimeout
 	}
 
 	var restart bool
 	switch {
+	case force:
+		restart = !hasBeenManuallyStopped
 	case rm.policy.IsAlways():
 		restart = true
 	case rm.policy.IsUnlessStopped() && !hasBeenManuallyStopped:
@@ -101,13 +133,19 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 
 	rm.restartCount++
 
+	delay := rm.timeout
+	if rm.policy.Jitter > 0 {
+		delay -= time.Duration(rm.policy.Jitter * rand.Float64() * float64(delay))
+	}
+
 	unlockOnExit = false
 	rm.active = true
+	rm.nextRestart = time.Now().Add(delay)
 	rm.Unlock()
 
 	ch := make(chan error)
 	go func() {
-		timeout := time.NewTimer(rm.timeout)
+		timeout := time.NewTimer(delay)
 		defer timeout.Stop()
 
 		select {
@@ -118,6 +156,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 			rm.Lock()
 			close(ch)
 			rm.active = false
+			rm.nextRestart = time.Time{}
 			rm.Unlock()
 		}
 	}()
@@ -125,10 +164,27 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	return true, ch, nil
 }
 
+// NextRestart returns the time at which the container is scheduled to be
+// restarted, or the zero time if no restart is scheduled.
+func (rm *restartManager) NextRestart() time.Time {
+	rm.Lock()
+	defer rm.Unlock()
+	return rm.nextRestart
+}
+
+// If configuredValue is zero, use defaultValue instead.
+func durationWithDefault(configuredValue, defaultValue time.Duration) time.Duration {
+	if configuredValue == 0 {
+		return defaultValue
+	}
+	return configuredValue
+}
+
 func (rm *restartManager) Cancel() error {
 	rm.Do(func() {
 		rm.Lock()
 		rm.canceled = true
+		rm.nextRestart = time.Time{}
 		close(rm.cancel)
 		rm.Unlock()
 	})
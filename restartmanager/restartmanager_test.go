@@ -34,3 +34,83 @@ func TestRestartManagerTimeoutReset(t *testing.T) {
 		t.Fatalf("restart manager should have a timeout of 100 ms but has %s", rm.timeout)
 	}
 }
+
+func TestRestartManagerForceRestart(t *testing.T) {
+	rm := New(container.RestartPolicy{Name: "no"}, 0).(*restartManager)
+	rm.ForceRestart()
+	should, _, err := rm.ShouldRestart(0, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !should {
+		t.Fatal("container should be restarted")
+	}
+
+	rm = New(container.RestartPolicy{Name: "no"}, 0).(*restartManager)
+	rm.ForceRestart()
+	should, _, err = rm.ShouldRestart(0, true, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if should {
+		t.Fatal("container should not be restarted after it has been manually stopped")
+	}
+	should, _, err = rm.ShouldRestart(0, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if should {
+		t.Fatal("container should only be restarted once")
+	}
+}
+
+func TestRestartManagerBackoff(t *testing.T) {
+	policy := container.RestartPolicy{
+		Name:         "always",
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   3,
+		ResetAfter:   time.Minute,
+	}
+	rm := New(policy, 0).(*restartManager)
+	for _, expected := range []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second} {
+		// The restart manager does not schedule a restart while one is
+		// pending, so it's canceled for the next call.
+		rm.active = false
+		if _, _, err := rm.ShouldRestart(0, false, 30*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if rm.timeout != expected {
+			t.Fatalf("restart manager should have a timeout of %s but has %s", expected, rm.timeout)
+		}
+	}
+
+	rm.active = false
+	if _, _, err := rm.ShouldRestart(0, false, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if rm.timeout != policy.InitialDelay {
+		t.Fatalf("restart manager should have a timeout of %s but has %s", policy.InitialDelay, rm.timeout)
+	}
+}
+
+func TestRestartManagerNextRestart(t *testing.T) {
+	rm := New(container.RestartPolicy{Name: "always", InitialDelay: time.Minute, Jitter: 0.5}, 0).(*restartManager)
+	if next := rm.NextRestart(); !next.IsZero() {
+		t.Fatalf("restart manager should not have a scheduled restart but has %s", next)
+	}
+
+	now := time.Now()
+	if _, _, err := rm.ShouldRestart(0, false, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	next := rm.NextRestart()
+	if next.Before(now.Add(30*time.Second)) || next.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("restart manager should have a scheduled restart within 30s to 1m but has %s", next.Sub(now))
+	}
+
+	rm.Cancel()
+	if next := rm.NextRestart(); !next.IsZero() {
+		t.Fatalf("restart manager should not have a scheduled restart after being canceled but has %s", next)
+	}
+}
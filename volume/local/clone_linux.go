@@ -0,0 +1,15 @@
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"github.com/docker/docker/daemon/graphdriver/copy"
+	"github.com/docker/docker/errdefs"
+)
+
+// copyData copies the data of a volume, using reflinks or copy_file_range
+// where the filesystem supports them.
+func copyData(srcPath, dstPath string) error {
+	if err := copy.DirCopy(srcPath, dstPath, copy.Content, false); err != nil {
+		return errdefs.System(err)
+	}
+	return nil
+}
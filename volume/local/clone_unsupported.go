@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+func copyData(srcPath, dstPath string) error {
+	return errdefs.NotImplemented(errors.New("cloning volumes is not supported on this platform"))
+}
@@ -138,7 +138,27 @@ func (r *Root) Create(name string, opts map[string]string) (volume.Volume, error
 		return v, nil
 	}
 
-	v = &localVolume{
+	v, err := r.newVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(v.rootPath)
+		}
+	}()
+
+	if err = v.setOpts(opts); err != nil {
+		return nil, err
+	}
+
+	r.volumes[name] = v
+	return v, nil
+}
+
+// newVolume creates the directory tree of a new volume with the given name.
+func (r *Root) newVolume(name string) (*localVolume, error) {
+	v := &localVolume{
 		driverName: r.Name(),
 		name:       name,
 		rootPath:   filepath.Join(r.path, name),
@@ -156,18 +176,70 @@ func (r *Root) Create(name string, opts map[string]string) (volume.Volume, error
 		return nil, errors.Wrapf(errdefs.System(err), "error while creating volume data path '%s'", v.path)
 	}
 
-	var err error
+	// Assign a project id to the data path, without a limit, so that the disk
+	// space used by the volume is accounted for by the filesystem.
+	if r.quotaCtl != nil {
+		if err := r.quotaCtl.SetQuota(v.path, quota.Quota{}); err != nil {
+			logrus.WithError(err).WithField("volume", name).Warn("Error setting up disk usage accounting of volume")
+		}
+	}
+	return v, nil
+}
+
+// Clone creates a new volume with the given name, holding a copy of the data
+// of the given volume, including the ownership and permissions of its files.
+// The new volume has the same options as the given volume. Volumes with mount
+// options cannot be cloned, as their data is not stored in the volume root.
+//
+// The data is copied while the volume may be in use, so callers should make
+// sure that nothing writes to the volume if they need a consistent copy.
+func (r *Root) Clone(vol volume.Volume, name string) (volume.Volume, error) {
+	if err := r.validateName(name); err != nil {
+		return nil, err
+	}
+	src, ok := vol.(*localVolume)
+	if !ok {
+		return nil, errdefs.System(errors.Errorf("unknown volume type %T", vol))
+	}
+	src.m.Lock()
+	needsMount := src.needsMount()
+	src.m.Unlock()
+	if needsMount {
+		return nil, errdefs.InvalidParameter(errors.Errorf("volume %s has mount options and cannot be cloned", src.name))
+	}
+
+	r.m.Lock()
+	if _, exists := r.volumes[name]; exists {
+		r.m.Unlock()
+		return nil, errdefs.Conflict(errors.Errorf("volume %s already exists", name))
+	}
+	v, err := r.newVolume(name)
+	r.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
 		if err != nil {
 			os.RemoveAll(v.rootPath)
 		}
 	}()
 
-	if err = v.setOpts(opts); err != nil {
-		return nil, err
+	if src.opts != nil {
+		opts := *src.opts
+		v.opts = &opts
+		if err = v.saveOpts(); err != nil {
+			return nil, err
+		}
+	}
+	if err = copyData(src.path, v.path); err != nil {
+		return nil, errors.Wrapf(err, "error while copying data of volume %s", src.name)
 	}
 
+	// The data is copied without holding the lock, as copying may take a
+	// long time. The volume store serializes operations on a volume name.
+	r.m.Lock()
 	r.volumes[name] = v
+	r.m.Unlock()
 	return v, nil
 }
 
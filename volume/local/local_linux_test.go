@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"syscall"
 	"testing"
 
 	"github.com/docker/docker/errdefs"
@@ -219,3 +220,55 @@ func TestVolCreateValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestClone(t *testing.T) {
+	r, err := New(t.TempDir(), idtools.Identity{UID: os.Geteuid(), GID: os.Getegid()})
+	assert.NilError(t, err)
+
+	src, err := r.Create("src", nil)
+	assert.NilError(t, err)
+	srcDir, err := src.Mount("1234")
+	assert.NilError(t, err)
+	assert.NilError(t, os.Mkdir(filepath.Join(srcDir, "dir"), 0700))
+	assert.NilError(t, os.WriteFile(filepath.Join(srcDir, "dir", "file"), []byte("hello"), 0640))
+	assert.NilError(t, os.Symlink("dir/file", filepath.Join(srcDir, "link")))
+	if os.Geteuid() == 0 {
+		assert.NilError(t, os.Lchown(filepath.Join(srcDir, "dir", "file"), 1000, 1000))
+	}
+
+	clone, err := r.Clone(src, "clone")
+	assert.NilError(t, err)
+	assert.NilError(t, src.Unmount("1234"))
+	assert.Check(t, is.Equal(clone.Name(), "clone"))
+
+	v, err := r.Get("clone")
+	assert.NilError(t, err)
+	dir, err := v.Mount("5678")
+	assert.NilError(t, err)
+	defer v.Unmount("5678")
+
+	b, err := os.ReadFile(filepath.Join(dir, "link"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(b), "hello"))
+
+	srcInfo, err := os.Stat(filepath.Join(srcDir, "dir", "file"))
+	assert.NilError(t, err)
+	info, err := os.Stat(filepath.Join(dir, "dir", "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(info.Mode(), srcInfo.Mode()))
+	assert.Check(t, is.Equal(info.Sys().(*syscall.Stat_t).Uid, srcInfo.Sys().(*syscall.Stat_t).Uid))
+	assert.Check(t, is.Equal(info.Sys().(*syscall.Stat_t).Gid, srcInfo.Sys().(*syscall.Stat_t).Gid))
+
+	_, err = r.Clone(src, "clone")
+	assert.Check(t, errdefs.IsConflict(err))
+
+	_, err = r.Clone(src, "a")
+	assert.Check(t, errdefs.IsInvalidParameter(err))
+
+	mounted, err := r.Create("mounted", map[string]string{"type": "tmpfs", "device": "tmpfs"})
+	assert.NilError(t, err)
+	_, err = r.Clone(mounted, "mounted-clone")
+	assert.Check(t, errdefs.IsInvalidParameter(err))
+	_, err = r.Get("mounted-clone")
+	assert.Check(t, is.ErrorIs(err, ErrNotFound))
+}
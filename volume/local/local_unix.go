@@ -154,6 +154,24 @@ func (v *localVolume) postMount() error {
 	return nil
 }
 
+// Usage returns the disk space used by the volume, as accounted by the
+// project quota of the volume. It returns an error if the disk space used
+// by the volume is not accounted for, in which case it must be determined
+// by walking the volume.
+func (v *localVolume) Usage() (int64, error) {
+	v.m.Lock()
+	needsMount := v.needsMount()
+	v.m.Unlock()
+	if v.quotaCtl == nil || needsMount {
+		return 0, errdefs.NotImplemented(errors.New("disk usage of volume is not accounted for"))
+	}
+	var usage quota.Usage
+	if err := v.quotaCtl.GetUsage(v.path, &usage); err != nil {
+		return 0, err
+	}
+	return int64(usage.Size), nil
+}
+
 func (v *localVolume) unmount() error {
 	if v.needsMount() {
 		if err := mount.Unmount(v.path); err != nil {
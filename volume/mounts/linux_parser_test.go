@@ -178,6 +178,54 @@ func TestLinuxParseMountSpecBindWithFileinfoError(t *testing.T) {
 	assert.ErrorContains(t, err, testErr.Error())
 }
 
+func TestLinuxParseMountSpecImage(t *testing.T) {
+	parser := NewLinuxParser()
+
+	mp, err := parser.ParseMountSpec(mount.Mount{
+		Type:   mount.TypeImage,
+		Source: "busybox:latest",
+		Target: "/assets",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, mp.Type, mount.TypeImage)
+	assert.Equal(t, mp.Name, "busybox:latest")
+	assert.Equal(t, mp.Destination, "/assets")
+	assert.Equal(t, mp.RW, false)
+	assert.Equal(t, mp.Source, "")
+
+	for _, tc := range []struct {
+		doc         string
+		input       mount.Mount
+		expectedErr string
+	}{
+		{
+			doc:         "missing source",
+			input:       mount.Mount{Type: mount.TypeImage, Target: "/assets"},
+			expectedErr: "field Source must not be empty",
+		},
+		{
+			doc:         "bind options",
+			input:       mount.Mount{Type: mount.TypeImage, Source: "busybox", Target: "/assets", BindOptions: &mount.BindOptions{}},
+			expectedErr: "field BindOptions must not be specified",
+		},
+		{
+			doc:         "volume options",
+			input:       mount.Mount{Type: mount.TypeImage, Source: "busybox", Target: "/assets", VolumeOptions: &mount.VolumeOptions{}},
+			expectedErr: "field VolumeOptions must not be specified",
+		},
+		{
+			doc:         "tmpfs options",
+			input:       mount.Mount{Type: mount.TypeImage, Source: "busybox", Target: "/assets", TmpfsOptions: &mount.TmpfsOptions{}},
+			expectedErr: "field TmpfsOptions must not be specified",
+		},
+	} {
+		t.Run(tc.doc, func(t *testing.T) {
+			_, err := parser.ParseMountSpec(tc.input)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
 func TestConvertTmpfsOptions(t *testing.T) {
 	type testCase struct {
 		opt                  mount.TmpfsOptions
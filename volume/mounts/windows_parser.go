@@ -288,6 +288,8 @@ func (p *windowsParser) validateMountConfigReg(mnt *mount.Mount, additionalValid
 		if windowsDetectMountType(mnt.Target) != mount.TypeNamedPipe {
 			return &errMountConfig{mnt, fmt.Errorf("'%s' is not a valid pipe path", mnt.Target)}
 		}
+	case mount.TypeImage:
+		return &errMountConfig{mnt, errors.New("image mounts are not supported on Windows")}
 	default:
 		return &errMountConfig{mnt, errors.New("mount type unknown")}
 	}
@@ -0,0 +1,90 @@
+package service // import "github.com/docker/docker/volume/service"
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/volume"
+	"github.com/docker/docker/volume/service/opts"
+	"github.com/sirupsen/logrus"
+)
+
+// Export returns a tar archive of the contents of the named volume.
+// The volume is kept mounted, and cannot be removed, until the returned archive
+// is closed. When user namespaces are used, the ownership of the files in the
+// archive is mapped to the user namespace, as it is seen by containers.
+func (s *VolumesService) Export(ctx context.Context, name string) (io.ReadCloser, error) {
+	v, path, ref, err := s.mountForArchive(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rdr, err := chrootarchive.Tar(path, &archive.TarOptions{
+		Compression: archive.Uncompressed,
+		IDMap:       s.idMapping,
+	}, path)
+	if err != nil {
+		s.unmountForArchive(v, ref)
+		return nil, errdefs.System(err)
+	}
+	return ioutils.NewReadCloserWrapper(rdr, func() error {
+		err := rdr.Close()
+		s.unmountForArchive(v, ref)
+		return err
+	}), nil
+}
+
+// Import extracts a tar archive into the named volume. Files of the volume
+// which are also in the archive are overwritten, other files are kept. The
+// archive may be compressed. When user namespaces are used, the ownership of
+// the files in the archive is mapped from the user namespace, as it is seen
+// by containers.
+func (s *VolumesService) Import(ctx context.Context, name string, content io.Reader) error {
+	v, path, ref, err := s.mountForArchive(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer s.unmountForArchive(v, ref)
+	defer s.vs.sizes.invalidate(v.Name())
+
+	return chrootarchive.UntarWithRoot(content, path, &archive.TarOptions{
+		IDMap: s.idMapping,
+	}, path)
+}
+
+// mountForArchive mounts the named volume, with a reference preventing its
+// removal. It returns the volume, the path it is mounted at, and the reference
+// to pass to unmountForArchive.
+func (s *VolumesService) mountForArchive(ctx context.Context, name string) (volume.Volume, string, string, error) {
+	ref := stringid.GenerateRandomID()
+	v, err := s.vs.Get(ctx, name, opts.WithGetReference(ref))
+	if err != nil {
+		if IsNotExist(err) {
+			err = errdefs.NotFound(err)
+		}
+		return nil, "", "", err
+	}
+
+	path, err := v.Mount(ref)
+	if err != nil {
+		if err := s.vs.Release(context.Background(), v.Name(), ref); err != nil {
+			logrus.WithError(err).WithField("volume", v.Name()).Warn("Error releasing reference to volume")
+		}
+		return nil, "", "", err
+	}
+	return v, path, ref, nil
+}
+
+func (s *VolumesService) unmountForArchive(v volume.Volume, ref string) {
+	if err := v.Unmount(ref); err != nil {
+		logrus.WithError(err).WithField("volume", v.Name()).Warn("Error unmounting volume")
+	}
+	if err := s.vs.Release(context.Background(), v.Name(), ref); err != nil {
+		logrus.WithError(err).WithField("volume", v.Name()).Warn("Error releasing reference to volume")
+	}
+}
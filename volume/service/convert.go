@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/docker/docker/api/types/filters"
@@ -64,7 +65,7 @@ func (s *VolumesService) volumesToAPI(ctx context.Context, volumes []volume.Volu
 			if apiV.Mountpoint == "" {
 				apiV.Mountpoint = p
 			}
-			sz, err := directory.Size(ctx, p)
+			sz, err := s.volumeSize(ctx, v, p)
 			if err != nil {
 				logrus.WithError(err).WithField("volume", v.Name()).Warnf("Failed to determine size of volume")
 				sz = -1
@@ -77,6 +78,34 @@ func (s *VolumesService) volumesToAPI(ctx context.Context, volumes []volume.Volu
 	return out
 }
 
+// volumeSize returns the disk space used by the volume. The disk space is
+// determined by walking the path of the volume if the volume does not report
+// it, unless the size determined by a previous walk is still valid.
+func (s *VolumesService) volumeSize(ctx context.Context, v volume.Volume, path string) (int64, error) {
+	if ur, ok := unwrapVolume(v).(volume.UsageReporter); ok {
+		sz, err := ur.Usage()
+		if err == nil {
+			return sz, nil
+		}
+		logrus.WithError(err).WithField("volume", v.Name()).Debug("Falling back to walking volume to determine its size")
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return directory.Size(ctx, path)
+	}
+	sz, gen, ok := s.vs.sizes.get(v.Name(), path, fi.ModTime())
+	if ok {
+		return sz, nil
+	}
+	sz, err = directory.Size(ctx, path)
+	if err != nil {
+		return sz, err
+	}
+	s.vs.sizes.set(v.Name(), gen, path, fi.ModTime(), sz)
+	return sz, nil
+}
+
 func volumeToAPIType(v volume.Volume) volumetypes.Volume {
 	createdAt, _ := v.CreatedAt()
 	tv := volumetypes.Volume{
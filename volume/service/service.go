@@ -9,7 +9,6 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/errdefs"
-	"github.com/docker/docker/pkg/directory"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/plugingetter"
 	"github.com/docker/docker/pkg/stringid"
@@ -39,12 +38,13 @@ type VolumesService struct {
 	pruneRunning int32
 	eventLogger  VolumeEventLogger
 	usage        singleflight.Group
+	idMapping    idtools.IdentityMapping
 }
 
 // NewVolumeService creates a new volume service
-func NewVolumeService(root string, pg plugingetter.PluginGetter, rootIDs idtools.Identity, logger VolumeEventLogger) (*VolumesService, error) {
+func NewVolumeService(root string, pg plugingetter.PluginGetter, idMapping idtools.IdentityMapping, logger VolumeEventLogger) (*VolumesService, error) {
 	ds := drivers.NewStore(pg)
-	if err := setupDefaultDriver(ds, root, rootIDs); err != nil {
+	if err := setupDefaultDriver(ds, root, idMapping.RootPair()); err != nil {
 		return nil, err
 	}
 
@@ -52,7 +52,7 @@ func NewVolumeService(root string, pg plugingetter.PluginGetter, rootIDs idtools
 	if err != nil {
 		return nil, err
 	}
-	return &VolumesService{vs: vs, ds: ds, eventLogger: logger}, nil
+	return &VolumesService{vs: vs, ds: ds, eventLogger: logger, idMapping: idMapping}, nil
 }
 
 // GetDriverList gets the list of registered volume drivers
@@ -80,6 +80,37 @@ func (s *VolumesService) Create(ctx context.Context, name, driverName string, op
 	return &apiV, nil
 }
 
+// Clone creates a volume with the given target name, holding a copy of the
+// data of the named volume. The new volume is created with the driver and the
+// options of the named volume, and has its labels merged with the labels
+// passed in the options. Only drivers which support cloning volumes, such as
+// the local driver, can be used.
+func (s *VolumesService) Clone(ctx context.Context, name, target string, createOpts ...opts.CreateOption) (*volumetypes.Volume, error) {
+	if target == "" {
+		target = stringid.GenerateRandomID()
+	}
+
+	// Hold a reference to the source volume while its data is copied, so
+	// that it cannot be removed.
+	ref := stringid.GenerateRandomID()
+	v, err := s.vs.Get(ctx, name, opts.WithGetReference(ref))
+	if err != nil {
+		if IsNotExist(err) {
+			err = errdefs.NotFound(err)
+		}
+		return nil, err
+	}
+	defer s.vs.Release(context.Background(), v.Name(), ref)
+
+	cv, err := s.vs.Clone(ctx, v, target, createOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	apiV := volumeToAPIType(cv)
+	return &apiV, nil
+}
+
 // Get returns details about a volume
 func (s *VolumesService) Get(ctx context.Context, name string, getOpts ...opts.GetOption) (*volumetypes.Volume, error) {
 	v, err := s.vs.Get(ctx, name, getOpts...)
@@ -136,6 +167,14 @@ func (s *VolumesService) Unmount(ctx context.Context, vol *volumetypes.Volume, r
 	return v.Unmount(ref)
 }
 
+// RestoreMount records a mount of a volume by a container which kept running
+// while the daemon was down. The volume was mounted by the previous daemon
+// process, so it is not mounted again, but its size isn't cached until the
+// mount is released with Unmount.
+func (s *VolumesService) RestoreMount(name string) {
+	s.vs.sizes.mounted(name)
+}
+
 // Release releases a volume reference
 func (s *VolumesService) Release(ctx context.Context, name string, ref string) error {
 	return s.vs.Release(ctx, name, ref)
@@ -158,6 +197,9 @@ func (s *VolumesService) Remove(ctx context.Context, name string, rmOpts ...opts
 	}
 
 	err = s.vs.Remove(ctx, v, rmOpts...)
+	if err == nil {
+		s.vs.sizes.forget(v.Name())
+	}
 	if IsNotExist(err) {
 		err = nil
 	} else if IsInUse(err) {
@@ -183,12 +225,13 @@ var acceptedListFilters = map[string]bool{
 // LocalVolumesSize gets all local volumes and fetches their size on disk
 // Note that this intentionally skips volumes which have mount options. Typically
 // volumes with mount options are not really local even if they are using the
-// local driver.
+// local driver. The size of volumes is taken from the project quota accounting
+// of the filesystem if available, instead of walking the volumes.
 func (s *VolumesService) LocalVolumesSize(ctx context.Context) ([]*volumetypes.Volume, error) {
 	ch := s.usage.DoChan("LocalVolumesSize", func() (interface{}, error) {
 		ls, _, err := s.vs.Find(ctx, And(ByDriver(volume.DefaultDriverName), CustomFilter(func(v volume.Volume) bool {
 			dv, ok := v.(volume.DetailedVolume)
-			return ok && len(dv.Options()) == 0
+			return ok && !hasMountOptions(dv)
 		})))
 		if err != nil {
 			return nil, err
@@ -206,6 +249,17 @@ func (s *VolumesService) LocalVolumesSize(ctx context.Context) ([]*volumetypes.V
 	}
 }
 
+// hasMountOptions returns whether the local volume has options other than a
+// quota size limit, which are used to mount it.
+func hasMountOptions(v volume.DetailedVolume) bool {
+	for opt := range v.Options() {
+		if opt != "size" {
+			return true
+		}
+	}
+	return false
+}
+
 // Prune removes (local) volumes which match the past in filter arguments.
 // Note that this intentionally skips volumes with mount options as there would
 // be no space reclaimed in this case.
@@ -239,7 +293,7 @@ func (s *VolumesService) Prune(ctx context.Context, filter filters.Args) (*types
 		default:
 		}
 
-		vSize, err := directory.Size(ctx, v.Path())
+		vSize, err := s.volumeSize(ctx, v, v.Path())
 		if err != nil {
 			logrus.WithField("volume", v.Name()).WithError(err).Warn("could not determine size of volume")
 		}
@@ -247,6 +301,7 @@ func (s *VolumesService) Prune(ctx context.Context, filter filters.Args) (*types
 			logrus.WithError(err).WithField("volume", v.Name()).Warnf("Could not determine size of volume")
 			continue
 		}
+		s.vs.sizes.forget(v.Name())
 		rep.SpaceReclaimed += uint64(vSize)
 		rep.VolumesDeleted = append(rep.VolumesDeleted, v.Name())
 	}
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
 	"github.com/docker/docker/volume"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/local"
@@ -14,8 +18,13 @@ import (
 	"github.com/docker/docker/volume/testutils"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
 )
 
+func init() {
+	reexec.Init()
+}
+
 func TestLocalVolumeSize(t *testing.T) {
 	t.Parallel()
 
@@ -63,3 +72,96 @@ func TestLocalVolumeSize(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceClone(t *testing.T) {
+	t.Parallel()
+
+	ds := volumedrivers.NewStore(nil)
+	l, err := local.New(t.TempDir(), idtools.Identity{UID: os.Getuid(), GID: os.Getegid()})
+	assert.NilError(t, err)
+	assert.Assert(t, ds.Register(l, volume.DefaultDriverName))
+	assert.Assert(t, ds.Register(testutils.NewFakeDriver("fake"), "fake"))
+
+	service, cleanup := newTestService(t, ds)
+	defer cleanup()
+
+	ctx := context.Background()
+	src, err := service.Create(ctx, "src", volume.DefaultDriverName, opts.WithCreateLabels(map[string]string{"a": "1", "b": "2"}))
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(src.Mountpoint, "data"), []byte("hello"), 0644))
+
+	clone, err := service.Clone(ctx, "src", "clone", opts.WithCreateLabels(map[string]string{"b": "3"}))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(clone.Name, "clone"))
+	assert.Check(t, is.Equal(clone.Driver, volume.DefaultDriverName))
+	assert.Check(t, is.DeepEqual(clone.Labels, map[string]string{"a": "1", "b": "3"}))
+
+	b, err := os.ReadFile(filepath.Join(clone.Mountpoint, "data"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(b), "hello"))
+
+	v, err := service.Get(ctx, "clone")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(v.Labels, clone.Labels))
+
+	// The reference held on the source volume while cloning is released.
+	assert.NilError(t, service.Remove(ctx, "src"))
+
+	_, err = service.Clone(ctx, "clone", "clone")
+	assert.Check(t, errdefs.IsConflict(err))
+
+	_, err = service.Clone(ctx, "notfound", "clone2")
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	_, err = service.Create(ctx, "fake", "fake")
+	assert.NilError(t, err)
+	_, err = service.Clone(ctx, "fake", "clone2")
+	assert.Check(t, errdefs.IsNotImplemented(err))
+}
+
+func TestServiceExportImport(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+	t.Parallel()
+
+	ds := volumedrivers.NewStore(nil)
+	l, err := local.New(t.TempDir(), idtools.Identity{UID: os.Getuid(), GID: os.Getegid()})
+	assert.NilError(t, err)
+	assert.Assert(t, ds.Register(l, volume.DefaultDriverName))
+
+	service, cleanup := newTestService(t, ds)
+	defer cleanup()
+
+	ctx := context.Background()
+	src, err := service.Create(ctx, "src", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	assert.NilError(t, os.Mkdir(filepath.Join(src.Mountpoint, "dir"), 0700))
+	assert.NilError(t, os.WriteFile(filepath.Join(src.Mountpoint, "dir", "data"), []byte("hello"), 0640))
+	assert.NilError(t, os.Lchown(filepath.Join(src.Mountpoint, "dir", "data"), 1000, 1000))
+
+	rdr, err := service.Export(ctx, "src")
+	assert.NilError(t, err)
+
+	// The volume cannot be removed while it is exported.
+	err = service.Remove(ctx, "src")
+	assert.Check(t, errdefs.IsConflict(err))
+
+	dst, err := service.Create(ctx, "dst", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	assert.NilError(t, service.Import(ctx, "dst", rdr))
+	assert.NilError(t, rdr.Close())
+
+	b, err := os.ReadFile(filepath.Join(dst.Mountpoint, "dir", "data"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(b), "hello"))
+	fi, err := os.Stat(filepath.Join(dst.Mountpoint, "dir", "data"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(fi.Mode(), os.FileMode(0640)))
+	assert.Check(t, is.Equal(fi.Sys().(*syscall.Stat_t).Uid, uint32(1000)))
+
+	assert.NilError(t, service.Remove(ctx, "src"))
+
+	_, err = service.Export(ctx, "notfound")
+	assert.Check(t, errdefs.IsNotFound(err))
+	err = service.Import(ctx, "notfound", bytes.NewReader(nil))
+	assert.Check(t, errdefs.IsNotFound(err))
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/errdefs"
@@ -11,6 +13,7 @@ import (
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/service/opts"
 	"github.com/docker/docker/volume/testutils"
+	"github.com/pkg/errors"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 )
@@ -250,3 +253,82 @@ func newTestService(t *testing.T, ds *volumedrivers.Store) (*VolumesService, fun
 type dummyEventLogger struct{}
 
 func (dummyEventLogger) LogVolumeEvent(_, _ string, _ map[string]string) {}
+
+type usageVolume struct {
+	testutils.FakeVolume
+	usage int64
+	err   error
+}
+
+func (v usageVolume) Usage() (int64, error) {
+	return v.usage, v.err
+}
+
+func TestVolumeSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "file"), make([]byte, 100), 0644))
+
+	ctx := context.Background()
+	s := &VolumesService{vs: &VolumeStore{sizes: &sizeCache{}}}
+	sz, err := s.volumeSize(ctx, usageVolume{usage: 1234}, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(1234)))
+
+	sz, err = s.volumeSize(ctx, volumeWrapper{Volume: usageVolume{usage: 1234}}, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(1234)))
+
+	// Volumes which do not know their usage are walked.
+	sz, err = s.volumeSize(ctx, usageVolume{err: errdefs.NotImplemented(errors.New("not accounted"))}, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(100)))
+
+	sz, err = s.volumeSize(ctx, testutils.NoopVolume{}, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(100)))
+}
+
+func TestVolumeSizeCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NilError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "sub", "file"), make([]byte, 100), 0644))
+
+	ctx := context.Background()
+	s := &VolumesService{vs: &VolumeStore{sizes: &sizeCache{}}}
+	v := volumeWrapper{Volume: testutils.NewFakeVolume("v1", "fake"), sizes: s.vs.sizes}
+	sz, err := s.volumeSize(ctx, v, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(100)))
+
+	// Changes which do not modify the data directory are not detected while
+	// the volume is not mounted.
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "sub", "file"), make([]byte, 200), 0644))
+	sz, err = s.volumeSize(ctx, v, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(100)))
+
+	// Mounted volumes are always walked.
+	_, err = v.Mount("ref")
+	assert.NilError(t, err)
+	sz, err = s.volumeSize(ctx, v, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(200)))
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "sub", "file"), make([]byte, 300), 0644))
+	assert.NilError(t, v.Unmount("ref"))
+	sz, err = s.volumeSize(ctx, v, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(300)))
+
+	// Modifying the data directory invalidates the cached size.
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "file"), make([]byte, 10), 0644))
+	future := time.Now().Add(time.Hour)
+	assert.NilError(t, os.Chtimes(dir, future, future))
+	sz, err = s.volumeSize(ctx, v, dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(sz, int64(310)))
+}
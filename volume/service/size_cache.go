@@ -0,0 +1,119 @@
+package service // import "github.com/docker/docker/volume/service"
+
+import (
+	"sync"
+	"time"
+)
+
+// sizeCache caches the sizes of volumes determined by walking them, so that
+// volumes are only walked again when they may have changed. A volume may have
+// changed when it was mounted since its size was determined, or when the
+// modification time of its data directory changed. Changes made to the data
+// of a volume from outside of containers, other than in the top-level
+// directory of the volume, are not detected until the volume is mounted.
+type sizeCache struct {
+	mu      sync.Mutex
+	volumes map[string]*cachedSize
+}
+
+type cachedSize struct {
+	// mounts is the number of active mounts of the volume. The size of a
+	// mounted volume is never taken from the cache.
+	mounts int
+	// gen is incremented on every change of the volume, so that a size
+	// determined while the volume changed is not cached.
+	gen uint64
+
+	valid bool
+	path  string
+	mtime time.Time
+	size  int64
+}
+
+func (c *sizeCache) entry(name string) *cachedSize {
+	if c.volumes == nil {
+		c.volumes = make(map[string]*cachedSize)
+	}
+	e, ok := c.volumes[name]
+	if !ok {
+		e = &cachedSize{}
+		c.volumes[name] = e
+	}
+	return e
+}
+
+// get returns the cached size of the named volume, if it is still valid for
+// the given data path and modification time. It also returns the generation
+// of the volume to pass to set once the size is determined.
+func (c *sizeCache) get(name, path string, mtime time.Time) (size int64, gen uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entry(name)
+	if e.valid && e.mounts == 0 && e.path == path && e.mtime.Equal(mtime) {
+		return e.size, e.gen, true
+	}
+	return 0, e.gen, false
+}
+
+// set caches the size of the named volume, determined for the given data
+// path and modification time. The size is not cached if the volume changed
+// since the generation was returned by get.
+func (c *sizeCache) set(name string, gen uint64, path string, mtime time.Time, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entry(name)
+	if e.gen != gen || e.mounts > 0 {
+		return
+	}
+	e.valid = true
+	e.path = path
+	e.mtime = mtime
+	e.size = size
+}
+
+// mounted records a mount of the named volume.
+func (c *sizeCache) mounted(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entry(name)
+	e.mounts++
+	e.gen++
+	e.valid = false
+}
+
+// unmounted records an unmount of the named volume. The data of the volume
+// may have changed while it was mounted, so its cached size is invalidated.
+func (c *sizeCache) unmounted(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entry(name)
+	if e.mounts > 0 {
+		e.mounts--
+	}
+	e.gen++
+	e.valid = false
+}
+
+// invalidate invalidates the cached size of the named volume.
+func (c *sizeCache) invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entry(name)
+	e.gen++
+	e.valid = false
+}
+
+// forget removes the named volume from the cache, once it is removed.
+func (c *sizeCache) forget(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.volumes[name]; ok && e.mounts == 0 {
+		delete(c.volumes, name)
+	}
+}
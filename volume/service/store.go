@@ -29,6 +29,7 @@ type volumeWrapper struct {
 	labels  map[string]string
 	scope   string
 	options map[string]string
+	sizes   *sizeCache
 }
 
 func (v volumeWrapper) Options() map[string]string {
@@ -58,6 +59,23 @@ func (v volumeWrapper) Scope() string {
 	return v.scope
 }
 
+// Mount mounts the volume. The data of the volume may change while it is
+// mounted, so its size isn't cached until it is unmounted.
+func (v volumeWrapper) Mount(id string) (string, error) {
+	v.sizes.mounted(v.Name())
+	path, err := v.Volume.Mount(id)
+	if err != nil {
+		v.sizes.unmounted(v.Name())
+	}
+	return path, err
+}
+
+// Unmount unmounts the volume, and invalidates its cached size.
+func (v volumeWrapper) Unmount(id string) error {
+	defer v.sizes.unmounted(v.Name())
+	return v.Volume.Unmount(id)
+}
+
 func (v volumeWrapper) CachedPath() string {
 	if vv, ok := v.Volume.(interface {
 		CachedPath() string
@@ -79,6 +97,7 @@ func NewStore(rootPath string, drivers *drivers.Store, opts ...StoreOpt) (*Volum
 		labels:  make(map[string]map[string]string),
 		options: make(map[string]map[string]string),
 		drivers: drivers,
+		sizes:   &sizeCache{},
 	}
 
 	for _, o := range opts {
@@ -215,6 +234,8 @@ type VolumeStore struct {
 	labels map[string]map[string]string
 	// options stores volume options for each volume
 	options map[string]map[string]string
+	// sizes caches the sizes of the volumes determined by walking them
+	sizes *sizeCache
 
 	db          *bolt.DB
 	eventLogger VolumeEventLogger
@@ -433,7 +454,7 @@ func (s *VolumeStore) list(ctx context.Context, driverNames ...string) ([]volume
 			}
 			for i, v := range vs {
 				s.globalLock.RLock()
-				vs[i] = volumeWrapper{v, s.labels[v.Name()], d.Scope(), s.options[v.Name()]}
+				vs[i] = volumeWrapper{v, s.labels[v.Name()], d.Scope(), s.options[v.Name()], s.sizes}
 				s.globalLock.RUnlock()
 			}
 
@@ -624,6 +645,15 @@ func (s *VolumeStore) create(ctx context.Context, name, driverName string, opts,
 		}
 	}
 
+	v, err = s.register(vd, v, opts, labels)
+	return v, true, err
+}
+
+// register stores the labels and options of a volume created by the given
+// driver, and persists its metadata.
+// It is expected that callers of this function hold any necessary locks.
+func (s *VolumeStore) register(vd volume.Driver, v volume.Volume, opts, labels map[string]string) (volume.Volume, error) {
+	name := v.Name()
 	s.globalLock.Lock()
 	s.labels[name] = labels
 	s.options[name] = opts
@@ -638,9 +668,95 @@ func (s *VolumeStore) create(ctx context.Context, name, driverName string, opts,
 	}
 
 	if err := s.setMeta(name, metadata); err != nil {
-		return nil, true, err
+		return nil, err
+	}
+	return volumeWrapper{v, labels, vd.Scope(), opts, s.sizes}, nil
+}
+
+// Clone creates a volume with the given name, holding a copy of the data of
+// the given volume. The volume is created by the driver of the given volume,
+// which must support cloning volumes, with the same options as the given
+// volume. The labels of the given volume are preserved, and merged with the
+// labels passed in the options.
+func (s *VolumeStore) Clone(ctx context.Context, v volume.Volume, name string, createOpts ...opts.CreateOption) (volume.Volume, error) {
+	var cfg opts.CreateConfig
+	for _, o := range createOpts {
+		o(&cfg)
+	}
+
+	name = normalizeVolumeName(name)
+	s.locks.Lock(name)
+	defer s.locks.Unlock(name)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	cv, err := s.clone(ctx, v, name, cfg.Labels)
+	if err != nil {
+		if _, ok := err.(*OpErr); ok {
+			return nil, err
+		}
+		return nil, &OpErr{Err: err, Name: name, Op: "clone"}
+	}
+
+	if s.eventLogger != nil {
+		s.eventLogger.LogVolumeEvent(cv.Name(), "create", map[string]string{"driver": cv.DriverName()})
+	}
+	s.setNamed(cv, cfg.Reference)
+	return cv, nil
+}
+
+// clone asks the driver of the given volume to create a copy of it with the
+// given name.
+// It is expected that callers of this function hold any necessary locks.
+func (s *VolumeStore) clone(ctx context.Context, v volume.Volume, name string, labels map[string]string) (volume.Volume, error) {
+	parser := volumemounts.NewParser()
+	if err := parser.ValidateVolumeName(name); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.checkConflict(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.Wrapf(errNameConflict, "volume '%s' already exists", name)
+	}
+
+	driverName := v.DriverName()
+	vd, err := s.drivers.CreateDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var cv volume.Volume
+	if cloner, ok := vd.(volume.Cloner); ok {
+		cv, err = cloner.Clone(unwrapVolume(v), name)
+	} else {
+		err = errdefs.NotImplemented(errors.Errorf("volume driver '%s' does not support cloning volumes", vd.Name()))
+	}
+	if err != nil {
+		if _, err := s.drivers.ReleaseDriver(driverName); err != nil {
+			logrus.WithError(err).WithField("driver", driverName).Error("Error releasing reference to volume driver")
+		}
+		return nil, err
+	}
+
+	var opts map[string]string
+	cloneLabels := make(map[string]string)
+	if dv, ok := v.(volume.DetailedVolume); ok {
+		opts = dv.Options()
+		for k, v := range dv.Labels() {
+			cloneLabels[k] = v
+		}
+	}
+	for k, v := range labels {
+		cloneLabels[k] = v
 	}
-	return volumeWrapper{v, labels, vd.Scope(), opts}, true, nil
+	return s.register(vd, cv, opts, cloneLabels)
 }
 
 // Get looks if a volume with the given name exists and returns it if so
@@ -719,7 +835,7 @@ func (s *VolumeStore) getVolume(ctx context.Context, name, driverName string) (v
 		if err == nil {
 			scope = vd.Scope()
 		}
-		return volumeWrapper{vol, meta.Labels, scope, meta.Options}, nil
+		return volumeWrapper{vol, meta.Labels, scope, meta.Options, s.sizes}, nil
 	}
 
 	logrus.Debugf("Probing all drivers for volume with name: %s", name)
@@ -742,7 +858,7 @@ func (s *VolumeStore) getVolume(ctx context.Context, name, driverName string) (v
 		if err := s.setMeta(name, meta); err != nil {
 			return nil, err
 		}
-		return volumeWrapper{v, meta.Labels, d.Scope(), meta.Options}, nil
+		return volumeWrapper{v, meta.Labels, d.Scope(), meta.Options, s.sizes}, nil
 	}
 	return nil, errNoSuchVolume
 }
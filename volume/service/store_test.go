@@ -363,7 +363,10 @@ func TestGetWithReference(t *testing.T) {
 	assert.NilError(t, err)
 }
 
-var cmpVolume = cmp.AllowUnexported(volumetestutils.FakeVolume{}, volumeWrapper{})
+var cmpVolume = cmp.Options{
+	cmp.AllowUnexported(volumetestutils.FakeVolume{}, volumeWrapper{}),
+	cmp.Comparer(func(a, b *sizeCache) bool { return a == b }),
+}
 
 func setupTest(t *testing.T) (*VolumeStore, func()) {
 	t.Helper()
@@ -32,6 +32,22 @@ type Driver interface {
 	Scope() string
 }
 
+// Cloner is implemented by drivers which can copy their volumes.
+type Cloner interface {
+	// Clone creates a new volume with the given name, holding a copy of the
+	// data of the given volume.
+	Clone(vol Volume, name string) (Volume, error)
+}
+
+// UsageReporter is an optional interface for volumes which can report the
+// disk space they use without walking their data, for example because it is
+// accounted for by the filesystem.
+type UsageReporter interface {
+	// Usage returns the disk space used by the volume, in bytes. It returns
+	// an error if the disk space used by the volume is not known.
+	Usage() (int64, error)
+}
+
 // Capability defines a set of capabilities that a driver is able to handle.
 type Capability struct {
 	// Scope is the scope of the driver, `global` or `local`